@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Outcomes recorded in the audit log
+const (
+	auditProcessed     = "processed"
+	auditFailed        = "failed"
+	auditSkippedBinary = "skipped_binary"
+)
+
+// AuditEntry records how an index run handled one file. Hash is the
+// content hash the index manifest recorded, when one was computed.
+type AuditEntry struct {
+	Time          time.Time `json:"time"`
+	Path          string    `json:"path"`
+	Size          int64     `json:"size"`
+	Hash          string    `json:"hash,omitempty"`
+	HashAlgorithm string    `json:"hash_algorithm,omitempty"`
+	Outcome       string    `json:"outcome"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// AuditLog appends a JSON line per handled file to a log kept across
+// runs. Unlike the run manifest it is written in place, a line at a
+// time, so the record survives an interrupted run. Writes from the
+// workers are serialized; the first failure is kept and returned by
+// Close.
+type AuditLog struct {
+	mu   sync.Mutex
+	file *os.File
+	err  error
+}
+
+// OpenAuditLog opens the audit log at path for appending, creating it
+// and its directory if needed
+func OpenAuditLog(path string) (*AuditLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &AuditLog{file: file}, nil
+}
+
+// Record appends entry as a single line
+func (a *AuditLog) Record(entry AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, err := a.file.Write(append(data, '\n')); err != nil {
+		err = fmt.Errorf("failed to write audit log: %w", err)
+		if a.err == nil {
+			a.err = err
+		}
+		return err
+	}
+	return nil
+}
+
+// Close closes the log, returning the first write error if any
+func (a *AuditLog) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.file.Close(); err != nil && a.err == nil {
+		a.err = fmt.Errorf("failed to close audit log: %w", err)
+	}
+	return a.err
+}
+
+// audit records the outcome of filePath in the --audit-log, if any
+func (ic *IndexCommand) audit(filePath, outcome string, procErr error) {
+	if ic.auditLog == nil {
+		return
+	}
+
+	entry := AuditEntry{
+		Time:    time.Now(),
+		Path:    manifestKey(filePath),
+		Outcome: outcome,
+	}
+	if info, err := os.Stat(filePath); err == nil {
+		entry.Size = info.Size()
+	}
+	if outcome == auditProcessed && ic.index != nil {
+		if recorded, ok := ic.index.Entry(filePath); ok {
+			entry.Hash = recorded.Hash
+			entry.HashAlgorithm = recorded.hashAlgorithm()
+		}
+	}
+	if procErr != nil {
+		entry.Error = procErr.Error()
+	}
+
+	// Write errors are reported when the log is closed
+	_ = ic.auditLog.Record(entry)
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+)
+
+const (
+	// monitorBaselineFile records the files seen by the last monitor
+	// --once run inside the state directory
+	monitorBaselineFile = "monitor-baseline.json"
+
+	// monitorBaselineVersion is the current baseline format version
+	monitorBaselineVersion = 1
+)
+
+// MonitorBaseline is the snapshot monitor --once compares against, so
+// successive runs report the changes made in between. Files are keyed
+// by absolute path.
+type MonitorBaseline struct {
+	path string
+
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Paths     []string  `json:"paths"`
+	Files     snapshot  `json:"files"`
+}
+
+// defaultMonitorBaselinePath returns the baseline path inside the state
+// directory
+func defaultMonitorBaselinePath() string {
+	return filepath.Join(stateDirName, monitorBaselineFile)
+}
+
+// NewMonitorBaseline creates an empty baseline for monitoring paths that
+// saves to path
+func NewMonitorBaseline(path string, paths []string) *MonitorBaseline {
+	return &MonitorBaseline{
+		path:    path,
+		Version: monitorBaselineVersion,
+		Paths:   checkpointPaths(paths),
+	}
+}
+
+// LoadMonitorBaseline reads the baseline at path. It returns nil, and no
+// error, when there is none yet or it was recorded for other paths, in
+// which case the caller starts a new one.
+func LoadMonitorBaseline(path string, paths []string) (*MonitorBaseline, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read monitor baseline: %w", err)
+	}
+
+	b := &MonitorBaseline{}
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("failed to parse monitor baseline %s: %w", path, err)
+	}
+	if b.Version != monitorBaselineVersion {
+		return nil, fmt.Errorf("unsupported monitor baseline version %d in %s", b.Version, path)
+	}
+	if !reflect.DeepEqual(b.Paths, checkpointPaths(paths)) {
+		logger.Warn("monitor baseline was recorded for other paths; starting a new one", "baseline", path, "paths", b.Paths)
+		return nil, nil
+	}
+
+	b.path = path
+	if b.Files == nil {
+		b.Files = make(snapshot)
+	}
+	return b, nil
+}
+
+// Save records snap as the baseline and writes it atomically
+func (b *MonitorBaseline) Save(snap snapshot) error {
+	b.Files = snap
+	b.UpdatedAt = time.Now()
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		return fmt.Errorf("failed to marshal monitor baseline: %w", err)
+	}
+
+	if err := writeFileAtomic(b.path, data); err != nil {
+		return fmt.Errorf("failed to save monitor baseline: %w", err)
+	}
+
+	return nil
+}
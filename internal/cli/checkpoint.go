@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// checkpointFile records the progress of an interrupted index run
+	// inside the state directory
+	checkpointFile = "checkpoint.json"
+
+	// checkpointVersion is the current checkpoint format version
+	checkpointVersion = 1
+)
+
+// Checkpoint records the files an index run has processed so that an
+// interrupted run can be resumed with --resume. It only lives as long as
+// the run is unfinished; a run that completes removes it.
+type Checkpoint struct {
+	mu      sync.Mutex
+	path    string
+	done    map[string]bool
+	pending int
+
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Paths     []string  `json:"paths"`
+	Processed []string  `json:"processed"`
+}
+
+// defaultCheckpointPath returns the checkpoint path inside the state
+// directory
+func defaultCheckpointPath() string {
+	return filepath.Join(stateDirName, checkpointFile)
+}
+
+// NewCheckpoint creates an empty checkpoint for a run over paths that
+// saves to path
+func NewCheckpoint(path string, paths []string) *Checkpoint {
+	return &Checkpoint{
+		path:    path,
+		done:    make(map[string]bool),
+		Version: checkpointVersion,
+		Paths:   checkpointPaths(paths),
+	}
+}
+
+// LoadCheckpoint reads the checkpoint at path and checks that it was
+// written for the same paths. A missing file yields an empty checkpoint.
+func LoadCheckpoint(path string, paths []string) (*Checkpoint, error) {
+	cp := NewCheckpoint(path, paths)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cp, nil
+		}
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	saved := &Checkpoint{}
+	if err := json.Unmarshal(data, saved); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	if saved.Version != checkpointVersion {
+		return nil, fmt.Errorf("unsupported checkpoint version %d in %s", saved.Version, path)
+	}
+	if !reflect.DeepEqual(saved.Paths, cp.Paths) {
+		return nil, fmt.Errorf("checkpoint %s was written for paths %v, not %v", path, saved.Paths, cp.Paths)
+	}
+
+	for _, file := range saved.Processed {
+		cp.done[file] = true
+	}
+	return cp, nil
+}
+
+// checkpointPaths returns the absolute, sorted form of the indexed paths
+func checkpointPaths(paths []string) []string {
+	abs := make([]string, len(paths))
+	for i, path := range paths {
+		abs[i] = manifestKey(path)
+	}
+	sort.Strings(abs)
+	return abs
+}
+
+// Done reports whether the file was processed by an earlier run
+func (c *Checkpoint) Done(filePath string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.done[manifestKey(filePath)]
+}
+
+// Add records a processed file and saves the checkpoint once interval
+// files have been added since the last save
+func (c *Checkpoint) Add(filePath string, interval int) error {
+	c.mu.Lock()
+	c.done[manifestKey(filePath)] = true
+	c.pending++
+	due := interval > 0 && c.pending >= interval
+	c.mu.Unlock()
+
+	if !due {
+		return nil
+	}
+	return c.Save()
+}
+
+// Len returns the number of processed files in the checkpoint
+func (c *Checkpoint) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.done)
+}
+
+// Save writes the checkpoint atomically
+func (c *Checkpoint) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Processed = make([]string, 0, len(c.done))
+	for file := range c.done {
+		c.Processed = append(c.Processed, file)
+	}
+	sort.Strings(c.Processed)
+	c.UpdatedAt = time.Now()
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	if err := writeFileAtomic(c.path, data); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+
+	c.pending = 0
+	return nil
+}
+
+// Remove deletes the saved checkpoint once a run has completed
+func (c *Checkpoint) Remove() error {
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+	return nil
+}
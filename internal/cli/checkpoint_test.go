@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheckpointSaveAndLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-checkpoint")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	oldDir := tempFiles.Dir()
+	tempFiles.SetDir(filepath.Join(dir, "tmp"))
+	defer tempFiles.SetDir(oldDir)
+
+	path := filepath.Join(dir, "checkpoint.json")
+	roots := []string{filepath.Join(dir, "docs")}
+
+	cp := NewCheckpoint(path, roots)
+	for i, name := range []string{"a.md", "b.md"} {
+		if err := cp.Add(filepath.Join(dir, "docs", name), 2); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+		if _, err := os.Stat(path); (err == nil) != (i == 1) {
+			t.Errorf("after %d file(s), checkpoint saved = %v, expected a save every 2 files", i+1, err == nil)
+		}
+	}
+
+	loaded, err := LoadCheckpoint(path, roots)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint() error = %v", err)
+	}
+	if loaded.Len() != 2 || !loaded.Done(filepath.Join(dir, "docs", "a.md")) || loaded.Done(filepath.Join(dir, "docs", "c.md")) {
+		t.Errorf("LoadCheckpoint() = %v, expected a.md and b.md", loaded.Processed)
+	}
+
+	if _, err := LoadCheckpoint(path, []string{dir}); err == nil {
+		t.Error("LoadCheckpoint() with different paths should fail")
+	}
+
+	missing, err := LoadCheckpoint(filepath.Join(dir, "missing.json"), roots)
+	if err != nil || missing.Len() != 0 {
+		t.Errorf("LoadCheckpoint() of a missing file = %d files, %v, expected an empty checkpoint", missing.Len(), err)
+	}
+
+	if err := loaded.Remove(); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected checkpoint to be removed, got %v", err)
+	}
+}
+
+func TestIndexResumeAfterInterruption(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-resume")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	oldDir := tempFiles.Dir()
+	tempFiles.SetDir(filepath.Join(dir, "tmp"))
+	defer tempFiles.SetDir(oldDir)
+
+	docs := filepath.Join(dir, "docs")
+	if err := os.Mkdir(docs, 0755); err != nil {
+		t.Fatalf("Failed to create docs dir: %v", err)
+	}
+	for i := 0; i < 30; i++ {
+		if err := ioutil.WriteFile(filepath.Join(docs, fmt.Sprintf("file%02d.txt", i)), []byte("content"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+	checkpointPath := filepath.Join(dir, "checkpoint.json")
+
+	var (
+		mu        sync.Mutex
+		processed = make(map[string]int)
+	)
+	newCommand := func(resume bool, process func(ctx context.Context, filePath string, stats *IndexStats) error) *IndexCommand {
+		return &IndexCommand{
+			config:             &CommandConfig{},
+			paths:              []string{docs},
+			recursive:          true,
+			maxDepth:           -1,
+			patterns:           []string{"*"},
+			maxWorkers:         2,
+			batchSize:          5,
+			checkpointInterval: 4,
+			resume:             resume,
+			checkpointPath:     checkpointPath,
+			processFn: func(ctx context.Context, filePath string, stats *IndexStats) error {
+				if err := process(ctx, filePath, stats); err != nil {
+					return err
+				}
+				mu.Lock()
+				processed[filepath.Base(filePath)]++
+				mu.Unlock()
+				return nil
+			},
+		}
+	}
+
+	run := func(ic *IndexCommand, ctx context.Context) (*IndexStats, error) {
+		if err := ic.loadCheckpoint(); err != nil {
+			t.Fatalf("loadCheckpoint() error = %v", err)
+		}
+		stats := &IndexStats{StartTime: time.Now(), FileTypes: make(map[string]int)}
+		var runErr error
+		captureStdout(t, func() {
+			runErr = ic.runFullIndex(ctx, stats)
+			if err := ic.finishCheckpoint(runErr); err != nil {
+				t.Errorf("finishCheckpoint() error = %v", err)
+			}
+		})
+		return stats, runErr
+	}
+
+	// Interrupt the first run partway through
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int32
+	first, err := run(newCommand(false, func(ctx context.Context, filePath string, stats *IndexStats) error {
+		if atomic.AddInt32(&calls, 1) == 12 {
+			cancel()
+		}
+		return nil
+	}), ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("first run error = %v, expected %v", err, context.Canceled)
+	}
+	if first.ProcessedFiles == 0 || first.ProcessedFiles == 30 {
+		t.Fatalf("first run processed %d files, expected a partial run", first.ProcessedFiles)
+	}
+	if _, err := os.Stat(checkpointPath); err != nil {
+		t.Fatalf("Expected a checkpoint after the interruption: %v", err)
+	}
+
+	// The resumed run processes only the remainder
+	second, err := run(newCommand(true, func(ctx context.Context, filePath string, stats *IndexStats) error {
+		return nil
+	}), context.Background())
+	if err != nil {
+		t.Fatalf("resumed run error = %v", err)
+	}
+
+	if second.Resumed != first.ProcessedFiles {
+		t.Errorf("Resumed = %d, expected the %d files of the first run", second.Resumed, first.ProcessedFiles)
+	}
+	if second.ProcessedFiles != 30-first.ProcessedFiles {
+		t.Errorf("resumed run processed %d files, expected %d", second.ProcessedFiles, 30-first.ProcessedFiles)
+	}
+
+	var twice []string
+	for name, count := range processed {
+		if count > 1 {
+			twice = append(twice, name)
+		}
+	}
+	sort.Strings(twice)
+	if len(processed) != 30 || len(twice) != 0 {
+		t.Errorf("processed %d distinct files, %s more than once, expected all 30 exactly once",
+			len(processed), strings.Join(twice, ", "))
+	}
+
+	if _, err := os.Stat(checkpointPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the checkpoint to be removed after a completed run, got %v", err)
+	}
+}
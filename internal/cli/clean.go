@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+// CleanCommand represents the clean command configuration
+type CleanCommand struct {
+	config *CommandConfig
+	dryRun bool
+
+	// indexManifestPath overrides the default .stroidex/manifest.json
+	indexManifestPath string
+}
+
+// NewCleanCommand creates the clean command
+func NewCleanCommand(config *CommandConfig) *cobra.Command {
+	cc := &CleanCommand{
+		config: config,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Prune index entries for deleted files",
+		Long: `Remove index entries for files that no longer exist on disk.
+
+Files that still exist but cannot be read stay in the index. Use 'stroidex
+remove' to drop files that still exist.
+
+Examples:
+  stroidex clean                           # Prune missing files
+  stroidex clean --dry-run                 # Show what would be pruned`,
+		Args: cobra.NoArgs,
+		RunE: cc.runClean,
+	}
+
+	cmd.Flags().BoolVar(&cc.dryRun, "dry-run", false, "Show what would be pruned without changing the index")
+
+	return cmd
+}
+
+// runClean executes the clean command
+func (cc *CleanCommand) runClean(cmd *cobra.Command, args []string) error {
+	// Reject the format before the index changes, not when rendering
+	if cc.config.OutputFormat == "prometheus" {
+		return fmt.Errorf("prometheus output is not supported for clean")
+	}
+
+	path := cc.indexManifestPath
+	if path == "" {
+		path = cc.config.resolvePath(defaultIndexManifestPath())
+	}
+	index, err := LoadIndexManifest(path)
+	if err != nil {
+		return err
+	}
+
+	keys := missingFiles(index)
+
+	base, _ := filepath.Abs(cc.config.resolvePath("."))
+	files := make([]string, len(keys))
+	for i, key := range keys {
+		files[i] = displayPath(base, key)
+	}
+
+	if !cc.dryRun && len(keys) > 0 {
+		index.Remove(keys...)
+		if err := index.Save(); err != nil {
+			return err
+		}
+	}
+
+	result := RemoveResult{Removed: len(keys), DryRun: cc.dryRun, Files: files}
+	return displayRemoveResult(cc.config, result, "No missing files in the index")
+}
+
+// missingFiles returns the sorted manifest keys of files that no longer
+// exist
+func missingFiles(index *IndexManifest) []string {
+	keys := make([]string, 0)
+	for key := range index.Files {
+		if _, err := os.Lstat(key); os.IsNotExist(err) {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClean(t *testing.T) {
+	preserveTheme(t)
+	t.Setenv("NO_COLOR", "1")
+
+	corpus := []string{"a.md", "docs/b.md", "docs/c.md", "old/d.md"}
+
+	tests := []struct {
+		name      string
+		args      []string
+		remaining []string
+		message   string
+	}{
+		{"Prunes missing files", nil, []string{"a.md", "docs/c.md"}, "Removed 2 entries"},
+		{"Dry run", []string{"--dry-run"}, corpus, "Would remove 2 entries"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := indexWorkspace(t, corpus)
+			for _, name := range []string{"docs/b.md", "old"} {
+				if err := os.RemoveAll(filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+					t.Fatalf("Failed to delete %s: %v", name, err)
+				}
+			}
+
+			output, err := runWorkspaceCommand(t, dir, append([]string{"clean"}, tt.args...)...)
+			if err != nil {
+				t.Fatalf("clean error = %v", err)
+			}
+			if !strings.Contains(output, tt.message) {
+				t.Errorf("output = %q, expected %q", output, tt.message)
+			}
+
+			if got := indexedFiles(t, dir); !reflect.DeepEqual(got, tt.remaining) {
+				t.Errorf("indexed files after clean = %v, expected %v", got, tt.remaining)
+			}
+		})
+	}
+}
+
+func TestCleanNothingMissing(t *testing.T) {
+	preserveTheme(t)
+	t.Setenv("NO_COLOR", "1")
+
+	dir := indexWorkspace(t, []string{"a.md"})
+	output, err := runWorkspaceCommand(t, dir, "clean")
+	if err != nil {
+		t.Fatalf("clean error = %v", err)
+	}
+	if !strings.Contains(output, "No missing files in the index") {
+		t.Errorf("output = %q, expected no missing files", output)
+	}
+}
+
+func TestCleanRejectsPrometheusOutput(t *testing.T) {
+	preserveTheme(t)
+
+	dir := indexWorkspace(t, []string{"a.md", "b.md"})
+	if err := os.Remove(filepath.Join(dir, "b.md")); err != nil {
+		t.Fatalf("Failed to delete b.md: %v", err)
+	}
+
+	if _, err := runWorkspaceCommand(t, dir, "clean", "-o", "prometheus"); err == nil {
+		t.Error("clean -o prometheus succeeded, expected an error")
+	}
+	if got := indexedFiles(t, dir); !reflect.DeepEqual(got, []string{"a.md", "b.md"}) {
+		t.Errorf("indexed files after a rejected clean = %v, expected the index unchanged", got)
+	}
+}
@@ -1,32 +1,64 @@
 package cli
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 // CLI represents the main CLI structure
 type CLI struct {
- RootCmd *cobra.Command
- Config  *CommandConfig
+	RootCmd *cobra.Command
+	Config  *CommandConfig
 }
 
 // CommandConfig holds configuration for CLI commands
 type CommandConfig struct {
-	ConfigFile string
-	Verbose    bool
-	Quiet      bool
+	ConfigFile   string
+	Verbose      bool
+	Quiet        bool
 	OutputFormat string
-	Theme      string
+	Theme        string
+	NoColor      bool
+	Color        string
+	TmpDir       string
+	EngineType   string
+	LogLevel     string
+
+	// Workspace is the base directory of relative paths and of the
+	// .stroidex state directory
+	Workspace string
+
+	// Timeout bounds the run of a command; zero means no limit
+	Timeout time.Duration
+
+	// OutputFile receives structured results instead of stdout when set
+	OutputFile string
+	outputFile *os.File
+
+	// Profile is the kind of profile, cpu or mem, written to ProfileOut
+	// for the run of a command
+	Profile     string
+	ProfileOut  string
+	profileFile *os.File
+
+	// ctx is the context commands run under, cancelled after Timeout
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 // NewCLI creates a new CLI instance
 func NewCLI() *CLI {
 	config := &CommandConfig{
-		OutputFormat: "table", // default output format
+		OutputFormat: "table",   // default output format
 		Theme:        "default", // default theme
+		Color:        colorAuto, // color on a terminal only
+		EngineType:   "default", // default engine
+		LogLevel:     "info",    // default log level
+		Workspace:    ".",       // default workspace
 	}
 
 	cli := &CLI{
@@ -45,16 +77,34 @@ func (cli *CLI) createRootCommand() *cobra.Command {
 		Use:   "stroidex",
 		Short: "Stroidex - Document indexing and monitoring CLI",
 		Long: `Stroidex CLI is a powerful command-line interface for document indexing,
-monitoring file system changes, and managing the Stroidex engine.`,
-		Version: "1.0.0",
+monitoring file system changes, and managing the Stroidex engine.
+
+Global flags can also be set with STROIDEX_<FLAG> environment variables,
+e.g. STROIDEX_OUTPUT=json or STROIDEX_NO_COLOR=true. Flags override the
+environment, which overrides the config file.`,
+		Version: versionString(),
+		// main prints the error returned by Execute
+		SilenceErrors: true,
 	}
 
 	// Global flags
-	cmd.PersistentFlags().StringVar(&cli.Config.ConfigFile, "config", "", "config file path")
+	cmd.PersistentFlags().StringVarP(&cli.Config.Workspace, "workspace", "w", ".", "workspace directory; relative paths and the .stroidex state directory are resolved against it")
+	cmd.PersistentFlags().StringVar(&cli.Config.ConfigFile, "config", "", "config file path (default $HOME/.stroidex.yaml)")
 	cmd.PersistentFlags().BoolVarP(&cli.Config.Verbose, "verbose", "v", false, "verbose output")
-	cmd.PersistentFlags().BoolVarP(&cli.Config.Quiet, "quiet", "q", false, "quiet mode")
-	cmd.PersistentFlags().StringVarP(&cli.Config.OutputFormat, "output", "o", "table", "output format (table, json, yaml)")
+	cmd.PersistentFlags().BoolVarP(&cli.Config.Quiet, "quiet", "q", false, "quiet mode (no output except errors)")
+	cmd.PersistentFlags().StringVarP(&cli.Config.OutputFormat, "output", "o", "table", "output format (table, json, ndjson, yaml, csv, prometheus)")
 	cmd.PersistentFlags().StringVar(&cli.Config.Theme, "theme", "default", "color theme (default, dark, light, none)")
+	cmd.PersistentFlags().StringVar(&cli.Config.Color, "color", colorAuto, "when to color output: auto (on a terminal, unless NO_COLOR is set), always (also when piped, e.g. to less -R) or never")
+	cmd.PersistentFlags().BoolVar(&cli.Config.NoColor, "no-color", false, "disable colored output; same as --color=never")
+	cmd.PersistentFlags().StringVar(&cli.Config.EngineType, "engine-type", "default", "engine type (default, experimental, legacy)")
+	cmd.PersistentFlags().StringVar(&cli.Config.LogLevel, "log-level", "info", "log level of diagnostics on stderr (debug, info, warn, error; --verbose implies debug)")
+	cmd.PersistentFlags().StringVar(&cli.Config.TmpDir, "tmp-dir", "", "directory for temporary files (default .stroidex/tmp)")
+	cmd.PersistentFlags().DurationVar(&cli.Config.Timeout, "timeout", 0, "cancel the command after this long, e.g. 30s or 5m (0 = no limit)")
+	cmd.PersistentFlags().StringVar(&cli.Config.Profile, "profile", "", "write a profile of the command for diagnosing slow runs: cpu, or mem for the heap in use at the end")
+	cmd.PersistentFlags().StringVar(&cli.Config.ProfileOut, "profile-out", "", "file to write the --profile to (default stroidex-cpu.pprof or stroidex-mem.pprof)")
+	cmd.PersistentFlags().StringVar(&cli.Config.OutputFile, "output-file", "", "write structured results (--output other than table) to this file instead of stdout")
+
+	addPersistentPreRun(cmd, cli.Config)
 
 	// Add custom help and version commands
 	// cmd.SetHelpCommand(cmd.HelpCommand())
@@ -69,31 +119,76 @@ func (cli *CLI) addCommands() {
 	cli.RootCmd.AddCommand(NewMonitorCommand(cli.Config))
 	cli.RootCmd.AddCommand(NewIndexCommand(cli.Config))
 	cli.RootCmd.AddCommand(NewStatusCommand(cli.Config))
-	// cli.RootCmd.AddCommand(cli.NewConfigCommand())
+	cli.RootCmd.AddCommand(NewSearchCommand(cli.Config))
+	cli.RootCmd.AddCommand(NewRemoveCommand(cli.Config))
+	cli.RootCmd.AddCommand(NewCleanCommand(cli.Config))
+	cli.RootCmd.AddCommand(NewStatsCommand(cli.Config))
+	cli.RootCmd.AddCommand(NewDoctorCommand(cli.Config))
+	cli.RootCmd.AddCommand(NewCompletionCommand())
+	cli.RootCmd.AddCommand(cli.NewConfigCommand())
 }
 
 // Execute executes the CLI
 func (cli *CLI) Execute() error {
-	return cli.RootCmd.Execute()
+	defer tempFiles.Cleanup()
+	defer restoreProgressOnPanic()
+	defer cli.Config.stopTimeout()
+
+	err := cli.Config.timeoutError(cli.RootCmd.Execute())
+
+	// The post-run hook stops the profile after a successful run; this
+	// covers commands that failed
+	if profileErr := cli.Config.stopProfile(); profileErr != nil && err == nil {
+		err = profileErr
+	}
+	if closeErr := cli.Config.closeOutputFile(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// ErrChangesFound is returned by monitor --once when it detected
+// changes. It is not a failure; main exits with ExitChangesFound and
+// prints nothing for it.
+var ErrChangesFound = errors.New("changes found")
+
+// ExitChangesFound is the exit status for ErrChangesFound
+const ExitChangesFound = 2
+
+// ExitCode returns the exit status for an error returned by Execute
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, ErrChangesFound):
+		return ExitChangesFound
+	default:
+		return 1
+	}
 }
 
-// PrintError prints formatted error message
+// PrintError prints formatted error message to stderr. It does not exit;
+// commands return their errors and main decides the exit status.
 func PrintError(err error) {
-	fmt.Printf("Error: %v\n", err)
-	os.Exit(1)
+	output.Error(err)
 }
 
 // PrintSuccess prints formatted success message
 func PrintSuccess(message string) {
-	fmt.Printf("✓ %s\n", message)
+	output.Success(message)
 }
 
 // PrintInfo prints formatted info message
 func PrintInfo(message string) {
-	fmt.Printf("ℹ %s\n", message)
+	output.Info(message)
 }
 
 // PrintWarning prints formatted warning message
 func PrintWarning(message string) {
-	fmt.Printf("⚠ %s\n", message)
-}
\ No newline at end of file
+	output.Warning(message)
+}
+
+// PrintTable prints rows under header as an aligned table
+func PrintTable(header []string, rows [][]string) {
+	output.Table(header, rows)
+}
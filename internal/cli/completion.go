@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// completionShells lists the shells completion scripts are generated for
+var completionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// NewCompletionCommand creates the completion command
+func NewCompletionCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "completion [bash|zsh|fish|powershell]",
+		Short: "Generate a shell completion script",
+		Long: `Generate a completion script for the given shell and write it to stdout.
+
+Load completions in the current shell, or install them permanently:
+
+  bash:       source <(stroidex completion bash)
+              stroidex completion bash > /etc/bash_completion.d/stroidex
+  zsh:        stroidex completion zsh > "${fpath[1]}/_stroidex"
+  fish:       stroidex completion fish > ~/.config/fish/completions/stroidex.fish
+  powershell: stroidex completion powershell | Out-String | Invoke-Expression`,
+		Example: `  stroidex completion bash
+  stroidex completion zsh`,
+		Args:                  cobra.ExactValidArgs(1),
+		ValidArgs:             completionShells,
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return writeCompletion(cmd.Root(), cmd, args[0])
+		},
+	}
+
+	return cmd
+}
+
+// writeCompletion writes the completion script of root for shell to the
+// output of cmd
+func writeCompletion(root, cmd *cobra.Command, shell string) error {
+	out := cmd.OutOrStdout()
+
+	switch shell {
+	case "bash":
+		return root.GenBashCompletionV2(out, true)
+	case "zsh":
+		return root.GenZshCompletion(out)
+	case "fish":
+		return root.GenFishCompletion(out, true)
+	case "powershell":
+		return root.GenPowerShellCompletionWithDesc(out)
+	default:
+		return fmt.Errorf("unsupported shell: %s (valid: bash, zsh, fish, powershell)", shell)
+	}
+}
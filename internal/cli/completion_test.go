@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCompletionCommand(t *testing.T) {
+	preserveTheme(t)
+
+	tests := []struct {
+		shell  string
+		marker string
+	}{
+		{"bash", "# bash completion V2 for stroidex"},
+		{"zsh", "#compdef _stroidex stroidex"},
+		{"fish", "complete -c stroidex"},
+		{"powershell", "Register-ArgumentCompleter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			cli := NewCLI()
+			var buf bytes.Buffer
+			cli.RootCmd.SetOut(&buf)
+			cli.RootCmd.SetArgs([]string{"completion", tt.shell})
+
+			if err := cli.RootCmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if !strings.Contains(buf.String(), tt.marker) {
+				t.Errorf("%s completion does not contain %q", tt.shell, tt.marker)
+			}
+		})
+	}
+}
+
+func TestCompletionCommandInvalidShell(t *testing.T) {
+	cli := NewCLI()
+	cli.RootCmd.SetOut(&bytes.Buffer{})
+	cli.RootCmd.SetErr(&bytes.Buffer{})
+	cli.RootCmd.SetArgs([]string{"completion", "tcsh"})
+
+	if err := cli.RootCmd.Execute(); err == nil {
+		t.Error("Execute() with an unsupported shell should fail")
+	}
+}
@@ -0,0 +1,291 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// configSetting is a config file setting managed by the config command
+type configSetting struct {
+	key string
+	get func(fc *FileConfig) string
+	set func(fc *FileConfig, value string) error
+}
+
+// ConfigEntry is a config setting and its value, for structured output
+type ConfigEntry struct {
+	Key   string `json:"key" yaml:"key"`
+	Value string `json:"value" yaml:"value"`
+}
+
+// configSettings lists the settings of the config file, validated with
+// the same rules as the flags they provide defaults for
+var configSettings = []configSetting{
+	{
+		key: "output",
+		get: func(fc *FileConfig) string { return fc.Output },
+		set: func(fc *FileConfig, value string) error {
+			if err := validateOutputFormat(value); err != nil {
+				return err
+			}
+			fc.Output = value
+			return nil
+		},
+	},
+	{
+		key: "theme",
+		get: func(fc *FileConfig) string { return fc.Theme },
+		set: func(fc *FileConfig, value string) error {
+			if err := validateTheme(value); err != nil {
+				return err
+			}
+			fc.Theme = value
+			return nil
+		},
+	},
+	{
+		key: "index.workers",
+		get: func(fc *FileConfig) string { return formatSettingInt(fc.Index.Workers) },
+		set: func(fc *FileConfig, value string) error {
+			return parsePositiveInt("index.workers", value, &fc.Index.Workers)
+		},
+	},
+	{
+		key: "index.batch_size",
+		get: func(fc *FileConfig) string { return formatSettingInt(fc.Index.BatchSize) },
+		set: func(fc *FileConfig, value string) error {
+			return parsePositiveInt("index.batch_size", value, &fc.Index.BatchSize)
+		},
+	},
+	{
+		key: "index.patterns",
+		get: func(fc *FileConfig) string { return strings.Join(fc.Index.Patterns, ",") },
+		set: func(fc *FileConfig, value string) error {
+			var patterns []string
+			for _, pattern := range strings.Split(value, ",") {
+				if pattern = strings.TrimSpace(pattern); pattern != "" {
+					patterns = append(patterns, pattern)
+				}
+			}
+			if len(patterns) == 0 {
+				return fmt.Errorf("index.patterns must list at least one pattern")
+			}
+			fc.Index.Patterns = patterns
+			return nil
+		},
+	},
+	{
+		key: "monitor.interval",
+		get: func(fc *FileConfig) string { return fc.Monitor.Interval },
+		set: func(fc *FileConfig, value string) error {
+			interval, err := time.ParseDuration(value)
+			if err != nil || interval <= 0 {
+				return fmt.Errorf("invalid monitor.interval: %s (expected a positive duration, e.g. 10s)", value)
+			}
+			fc.Monitor.Interval = value
+			return nil
+		},
+	},
+}
+
+// lookupConfigSetting returns the setting named key
+func lookupConfigSetting(key string) (configSetting, error) {
+	keys := make([]string, len(configSettings))
+	for i, setting := range configSettings {
+		if setting.key == key {
+			return setting, nil
+		}
+		keys[i] = setting.key
+	}
+	return configSetting{}, fmt.Errorf("unknown config key: %s (valid: %s)", key, strings.Join(keys, ", "))
+}
+
+// formatSettingInt formats an integer setting, leaving zero unset
+func formatSettingInt(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// parsePositiveInt parses value into n, requiring it to be positive
+func parsePositiveInt(key, value string, n *int) error {
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		return fmt.Errorf("invalid %s: %s (expected a positive integer)", key, value)
+	}
+	*n = parsed
+	return nil
+}
+
+// saveConfigFile writes fc as YAML to path
+func saveConfigFile(path string, fc *FileConfig) error {
+	data, err := yaml.Marshal(fc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// NewConfigCommand creates the config command
+func (cli *CLI) NewConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Read and write the config file",
+		Long: `Read and write settings in the config file ($HOME/.stroidex.yaml, or the
+file named with --config). Settings provide defaults for the matching
+flags; flags and STROIDEX_ environment variables override them.
+
+Keys: output, theme, index.workers, index.batch_size, index.patterns,
+monitor.interval
+
+Examples:
+  stroidex config list                     # Show all settings
+  stroidex config get output               # Show one setting
+  stroidex config set output json          # Default to JSON output
+  stroidex config set index.patterns "*.md,*.txt"`,
+	}
+
+	cmd.AddCommand(cli.newConfigGetCommand())
+	cmd.AddCommand(cli.newConfigSetCommand())
+	cmd.AddCommand(cli.newConfigListCommand())
+
+	return cmd
+}
+
+// configFileCommand creates a config subcommand, which reads the config
+// file itself rather than having it applied beforehand
+func configFileCommand(cmd *cobra.Command) *cobra.Command {
+	cmd.Annotations = map[string]string{skipConfigFileAnnotation: "true"}
+	return cmd
+}
+
+// loadConfigForEdit returns the config file path and its settings. A
+// missing file has no settings yet.
+func (cli *CLI) loadConfigForEdit() (string, *FileConfig, error) {
+	path, _ := configFilePath(cli.Config)
+	if path == "" {
+		return "", nil, fmt.Errorf("no config file: use --config, as the home directory is unknown")
+	}
+
+	fc, err := loadConfigFile(path, false)
+	if err != nil {
+		return "", nil, err
+	}
+	return path, fc, nil
+}
+
+// newConfigGetCommand creates the config get command
+func (cli *CLI) newConfigGetCommand() *cobra.Command {
+	return configFileCommand(&cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the value of a setting",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			setting, err := lookupConfigSetting(args[0])
+			if err != nil {
+				return err
+			}
+
+			_, fc, err := cli.loadConfigForEdit()
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), setting.get(fc))
+			return nil
+		},
+	})
+}
+
+// newConfigSetCommand creates the config set command
+func (cli *CLI) newConfigSetCommand() *cobra.Command {
+	return configFileCommand(&cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Change a setting",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			setting, err := lookupConfigSetting(args[0])
+			if err != nil {
+				return err
+			}
+
+			path, fc, err := cli.loadConfigForEdit()
+			if err != nil {
+				return err
+			}
+
+			if err := setting.set(fc, args[1]); err != nil {
+				return err
+			}
+			if err := saveConfigFile(path, fc); err != nil {
+				return err
+			}
+
+			PrintSuccess(fmt.Sprintf("Set %s = %s in %s", setting.key, setting.get(fc), path))
+			return nil
+		},
+	})
+}
+
+// newConfigListCommand creates the config list command
+func (cli *CLI) newConfigListCommand() *cobra.Command {
+	return configFileCommand(&cobra.Command{
+		Use:   "list",
+		Short: "Print all settings",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, fc, err := cli.loadConfigForEdit()
+			if err != nil {
+				return err
+			}
+
+			entries := make([]ConfigEntry, len(configSettings))
+			for i, setting := range configSettings {
+				entries[i] = ConfigEntry{Key: setting.key, Value: setting.get(fc)}
+			}
+
+			out := cmd.OutOrStdout()
+			renderer := NewRenderer(cli.Config.OutputFormat, out)
+			if cli.Config.OutputFormat == "csv" {
+				for _, entry := range entries {
+					if err := renderer.Record("setting", entry); err != nil {
+						return err
+					}
+				}
+				return renderer.Flush()
+			}
+			if renderer != nil {
+				if err := renderer.Render("config", entries); err != nil {
+					return err
+				}
+				return renderer.Flush()
+			}
+
+			table := tablewriter.NewWriter(out)
+			table.SetHeader([]string{"Key", "Value"})
+			table.SetAlignment(tablewriter.ALIGN_LEFT)
+			for _, entry := range entries {
+				table.Append([]string{entry.Key, entry.Value})
+			}
+			table.Render()
+			return nil
+		},
+	})
+}
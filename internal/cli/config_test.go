@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runConfigCommand executes a config subcommand against the config file
+// at path, returning its stdout
+func runConfigCommand(t *testing.T, path string, args ...string) (string, error) {
+	t.Helper()
+
+	cli := NewCLI()
+	var stdout bytes.Buffer
+	cli.RootCmd.SetOut(&stdout)
+	cli.RootCmd.SetArgs(append([]string{"--config", path, "--no-color", "config"}, args...))
+
+	var err error
+	captureStdout(t, func() {
+		err = cli.RootCmd.Execute()
+	})
+	return stdout.String(), err
+}
+
+func TestConfigSetGet(t *testing.T) {
+	preserveTheme(t)
+	path := filepath.Join(filepath.Dir(writeConfigFile(t, "unused.yaml", "")), "nested", "stroidex.yaml")
+
+	tests := []struct {
+		key      string
+		value    string
+		expected string
+	}{
+		{"output", "json", "json"},
+		{"theme", "dark", "dark"},
+		{"index.workers", "8", "8"},
+		{"index.batch_size", "25", "25"},
+		{"index.patterns", "*.md, *.txt", "*.md,*.txt"},
+		{"monitor.interval", "30s", "30s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if _, err := runConfigCommand(t, path, "set", tt.key, tt.value); err != nil {
+				t.Fatalf("config set error = %v", err)
+			}
+
+			got, err := runConfigCommand(t, path, "get", tt.key)
+			if err != nil {
+				t.Fatalf("config get error = %v", err)
+			}
+			if strings.TrimSpace(got) != tt.expected {
+				t.Errorf("config get %s = %q, expected %q", tt.key, got, tt.expected)
+			}
+		})
+	}
+
+	// The saved file is a valid config applying to every command
+	fc, err := loadConfigFile(path, true)
+	if err != nil {
+		t.Fatalf("loadConfigFile() error = %v", err)
+	}
+	if fc.Output != "json" || fc.Index.Workers != 8 || len(fc.Index.Patterns) != 2 {
+		t.Errorf("saved config = %+v, expected the values set", fc)
+	}
+
+	list, err := runConfigCommand(t, path, "--output", "table", "list")
+	if err != nil {
+		t.Fatalf("config list error = %v", err)
+	}
+	if !strings.Contains(list, "monitor.interval") || !strings.Contains(list, "30s") {
+		t.Errorf("config list = %q, expected monitor.interval = 30s", list)
+	}
+}
+
+func TestConfigRejectsInvalid(t *testing.T) {
+	preserveTheme(t)
+	path := writeConfigFile(t, "stroidex.yaml", "output: yaml\n")
+
+	tests := []struct {
+		name  string
+		args  []string
+		error string
+	}{
+		{"Unknown get key", []string{"get", "colour"}, "unknown config key: colour"},
+		{"Unknown set key", []string{"set", "colour", "red"}, "unknown config key: colour"},
+		{"Invalid output", []string{"set", "output", "xml"}, "output format must be one of"},
+		{"Invalid theme", []string{"set", "theme", "neon"}, "theme must be one of"},
+		{"Invalid workers", []string{"set", "index.workers", "0"}, "invalid index.workers"},
+		{"Invalid interval", []string{"set", "monitor.interval", "soon"}, "invalid monitor.interval"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runConfigCommand(t, path, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.error) {
+				t.Errorf("error = %v, expected %q", err, tt.error)
+			}
+		})
+	}
+
+	fc, err := loadConfigFile(path, true)
+	if err != nil {
+		t.Fatalf("loadConfigFile() error = %v", err)
+	}
+	if fc.Output != "yaml" || fc.Theme != "" {
+		t.Errorf("config after rejected sets = %+v, expected it unchanged", fc)
+	}
+}
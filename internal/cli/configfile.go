@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigName is the config file read from the home directory when
+// --config is not given
+const defaultConfigName = ".stroidex.yaml"
+
+// FileConfig holds the settings of a config file. Unset settings keep
+// their flag defaults, and flags given on the command line override it.
+type FileConfig struct {
+	Output  string        `yaml:"output,omitempty"`
+	Theme   string        `yaml:"theme,omitempty"`
+	Index   IndexConfig   `yaml:"index,omitempty"`
+	Monitor MonitorConfig `yaml:"monitor,omitempty"`
+}
+
+// IndexConfig holds the index settings of a config file
+type IndexConfig struct {
+	Workers   int      `yaml:"workers,omitempty"`
+	BatchSize int      `yaml:"batch_size,omitempty"`
+	Patterns  []string `yaml:"patterns,omitempty"`
+}
+
+// MonitorConfig holds the monitor settings of a config file
+type MonitorConfig struct {
+	Interval string `yaml:"interval,omitempty"`
+}
+
+// defaultConfigPath returns $HOME/.stroidex.yaml, or "" when the home
+// directory is unknown
+func defaultConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, defaultConfigName)
+}
+
+// loadConfigFile reads the config file at path. A missing file is only an
+// error when required, i.e. when it was named with --config.
+func loadConfigFile(path string, required bool) (*FileConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !required {
+			return &FileConfig{}, nil
+		}
+		return nil, err
+	}
+
+	var fc FileConfig
+	if err := yaml.Unmarshal(data, &fc); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return &fc, nil
+}
+
+// flagValues returns the config file settings as flag values, keyed by
+// the name of the command they apply to ("" for global flags) and then
+// by flag name
+func (fc *FileConfig) flagValues() map[string]map[string][]string {
+	values := map[string]map[string][]string{
+		"":        {},
+		"index":   {},
+		"monitor": {},
+	}
+
+	if fc.Output != "" {
+		values[""]["output"] = []string{fc.Output}
+	}
+	if fc.Theme != "" {
+		values[""]["theme"] = []string{fc.Theme}
+	}
+	if fc.Index.Workers != 0 {
+		values["index"]["workers"] = []string{strconv.Itoa(fc.Index.Workers)}
+	}
+	if fc.Index.BatchSize != 0 {
+		values["index"]["batch-size"] = []string{strconv.Itoa(fc.Index.BatchSize)}
+	}
+	if len(fc.Index.Patterns) > 0 {
+		values["index"]["pattern"] = fc.Index.Patterns
+	}
+	if fc.Monitor.Interval != "" {
+		values["monitor"]["interval"] = []string{fc.Monitor.Interval}
+	}
+
+	return values
+}
+
+// sliceValue is implemented by slice flags, which replace their default
+// rather than appending to it
+type sliceValue interface {
+	Replace([]string) error
+}
+
+// skipConfigFileAnnotation marks commands that manage the config file
+// themselves, so it is not applied before they run
+const skipConfigFileAnnotation = "stroidex.skip-config-file"
+
+// configFilePath returns the config file selected by config, and whether
+// it was named explicitly. It is "" when there is no home directory.
+func configFilePath(config *CommandConfig) (string, bool) {
+	if config.ConfigFile != "" {
+		return config.ConfigFile, true
+	}
+	return defaultConfigPath(), false
+}
+
+// applyConfigFile loads the config file and applies its settings to the
+// flags of cmd that were not given on the command line
+func applyConfigFile(cmd *cobra.Command, config *CommandConfig) error {
+	if cmd.Annotations[skipConfigFileAnnotation] != "" {
+		return nil
+	}
+
+	path, required := configFilePath(config)
+	if path == "" {
+		return nil
+	}
+
+	fc, err := loadConfigFile(path, required)
+	if err != nil {
+		return err
+	}
+
+	values := fc.flagValues()
+	for _, scope := range []string{"", cmd.Name()} {
+		for name, value := range values[scope] {
+			if err := setFlagDefault(cmd, name, value); err != nil {
+				return fmt.Errorf("invalid %s in config file %s: %w", name, path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// setFlagDefault sets the flag name of cmd to value unless it was given
+// on the command line
+func setFlagDefault(cmd *cobra.Command, name string, value []string) error {
+	flag := cmd.Flags().Lookup(name)
+	if flag == nil || flag.Changed {
+		return nil
+	}
+
+	if slice, ok := flag.Value.(sliceValue); ok {
+		return slice.Replace(value)
+	}
+	return flag.Value.Set(strings.Join(value, ","))
+}
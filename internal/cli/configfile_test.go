@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testConfigFile = `output: json
+theme: dark
+index:
+  workers: 8
+  batch_size: 25
+  patterns: ["*.md", "*.txt"]
+monitor:
+  interval: 30s
+`
+
+// writeConfigFile writes content to a config file in a temp dir
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "stroidex-config-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestConfigFilePrecedence(t *testing.T) {
+	path := writeConfigFile(t, "stroidex.yaml", testConfigFile)
+
+	preserveTheme(t)
+
+	tests := []struct {
+		name      string
+		args      []string
+		workers   int
+		batchSize int
+		patterns  []string
+		theme     string
+	}{
+		{"File overrides defaults", nil, 8, 25, []string{"*.md", "*.txt"}, "dark"},
+		{"Flags override file", []string{"--workers", "2", "--pattern", "*.go", "--theme", "light"}, 2, 25, []string{"*.go"}, "light"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := NewCLI()
+			args := append([]string{"--config", path, "index", "--print-config"}, tt.args...)
+			cli.RootCmd.SetArgs(append(args, "."))
+
+			output := captureStdout(t, func() {
+				if err := cli.RootCmd.Execute(); err != nil {
+					t.Fatalf("Execute() error = %v", err)
+				}
+			})
+
+			var resolved IndexRunConfig
+			if err := json.Unmarshal([]byte(output), &resolved); err != nil {
+				t.Fatalf("Invalid --print-config output: %v\n%s", err, output)
+			}
+
+			if resolved.Workers != tt.workers {
+				t.Errorf("workers = %d, expected %d", resolved.Workers, tt.workers)
+			}
+			if resolved.BatchSize != tt.batchSize {
+				t.Errorf("batch_size = %d, expected %d", resolved.BatchSize, tt.batchSize)
+			}
+			if !reflect.DeepEqual(resolved.Patterns, tt.patterns) {
+				t.Errorf("patterns = %v, expected %v", resolved.Patterns, tt.patterns)
+			}
+			if resolved.OutputFormat != "json" {
+				t.Errorf("output_format = %s, expected json", resolved.OutputFormat)
+			}
+			if cli.Config.Theme != tt.theme {
+				t.Errorf("theme = %s, expected %s", cli.Config.Theme, tt.theme)
+			}
+		})
+	}
+}
+
+func TestConfigFileDefaultPath(t *testing.T) {
+	home := filepath.Dir(writeConfigFile(t, defaultConfigName, "monitor:\n  interval: 30s\n"))
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	cli := NewCLI()
+	monitorCmd, _, err := cli.RootCmd.Find([]string{"monitor"})
+	if err != nil {
+		t.Fatalf("Find(monitor) error = %v", err)
+	}
+	if err := monitorCmd.ParseFlags(nil); err != nil {
+		t.Fatalf("ParseFlags() error = %v", err)
+	}
+	if err := applyConfigFile(monitorCmd, cli.Config); err != nil {
+		t.Fatalf("applyConfigFile() error = %v", err)
+	}
+
+	interval, _ := monitorCmd.Flags().GetDuration("interval")
+	if interval != 30*time.Second {
+		t.Errorf("interval = %v, expected 30s", interval)
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	missing := filepath.Join(os.TempDir(), "stroidex-missing-config.yaml")
+
+	if _, err := loadConfigFile(missing, false); err != nil {
+		t.Errorf("loadConfigFile() of a missing optional file error = %v", err)
+	}
+	if _, err := loadConfigFile(missing, true); err == nil {
+		t.Error("loadConfigFile() of a missing --config file should fail")
+	}
+
+	invalid := writeConfigFile(t, "invalid.yaml", "index: [not, a, map]\n")
+	if _, err := loadConfigFile(invalid, true); err == nil {
+		t.Error("loadConfigFile() of an invalid file should fail")
+	}
+}
+
+func TestApplyConfigFileInvalidValue(t *testing.T) {
+	path := writeConfigFile(t, "stroidex.yaml", "monitor:\n  interval: soon\n")
+
+	cli := NewCLI()
+	cli.Config.ConfigFile = path
+	monitorCmd, _, err := cli.RootCmd.Find([]string{"monitor"})
+	if err != nil {
+		t.Fatalf("Find(monitor) error = %v", err)
+	}
+	if err := applyConfigFile(monitorCmd, cli.Config); err == nil {
+		t.Error("applyConfigFile() with an invalid interval should fail")
+	}
+}
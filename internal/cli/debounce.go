@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// debouncer coalesces file events per path, delivering one event for a
+// path once no new event has arrived for it within the window
+type debouncer struct {
+	mu      sync.Mutex
+	ctx     context.Context
+	window  time.Duration
+	timers  map[string]*time.Timer
+	pending map[string]FileEvent
+	fires   sync.WaitGroup
+	out     chan FileEvent
+}
+
+// newDebouncer creates a debouncer that delivers events until ctx is done
+func newDebouncer(ctx context.Context, window time.Duration) *debouncer {
+	return &debouncer{
+		ctx:     ctx,
+		window:  window,
+		timers:  make(map[string]*time.Timer),
+		pending: make(map[string]FileEvent),
+		out:     make(chan FileEvent, eventBuffer),
+	}
+}
+
+// mergeEvent combines two events for the same path. A file created and
+// then written is still reported as created; otherwise the latest
+// change wins. The merged event takes the time of the latest change.
+func mergeEvent(prev, next FileEvent) FileEvent {
+	if prev.Op == "create" && next.Op == "write" {
+		prev.Time = next.Time
+		return prev
+	}
+	return next
+}
+
+// Add queues an event, restarting the quiet period of its path. A rename
+// takes over the pending event of the path it moved from, so a file
+// created and then moved is reported as created at its new path.
+func (d *debouncer) Add(event FileEvent) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if event.OldPath != "" {
+		if prev, ok := d.pending[event.OldPath]; ok {
+			if timer := d.timers[event.OldPath]; timer.Stop() {
+				d.fires.Done()
+			}
+			delete(d.pending, event.OldPath)
+			delete(d.timers, event.OldPath)
+			if prev.Op == "create" {
+				event = FileEvent{Path: event.Path, Op: "create", Time: event.Time}
+			}
+		}
+	}
+
+	if prev, ok := d.pending[event.Path]; ok {
+		event = mergeEvent(prev, event)
+	}
+	d.pending[event.Path] = event
+
+	if timer, ok := d.timers[event.Path]; ok && timer.Stop() {
+		d.fires.Done()
+	}
+
+	var timer *time.Timer
+	d.fires.Add(1)
+	timer = time.AfterFunc(d.window, func() {
+		defer d.fires.Done()
+		d.fire(event.Path, &timer)
+	})
+	d.timers[event.Path] = timer
+}
+
+// fire delivers the pending event of a path whose quiet period ended,
+// unless timer was superseded by a later event. timer is read under the
+// lock, as Add assigns it after scheduling.
+func (d *debouncer) fire(path string, timer **time.Timer) {
+	d.mu.Lock()
+	if d.timers[path] != *timer {
+		d.mu.Unlock()
+		return
+	}
+	event := d.pending[path]
+	delete(d.pending, path)
+	delete(d.timers, path)
+	d.mu.Unlock()
+
+	d.send(event)
+}
+
+// send delivers an event unless the debouncer's context is done
+func (d *debouncer) send(event FileEvent) {
+	select {
+	case d.out <- event:
+	case <-d.ctx.Done():
+	}
+}
+
+// Close delivers the events still pending without waiting for their
+// quiet period, then closes the output channel
+func (d *debouncer) Close() {
+	d.mu.Lock()
+	var flush []FileEvent
+	for path, timer := range d.timers {
+		// A timer that already fired delivers its own event
+		if timer.Stop() {
+			d.fires.Done()
+			flush = append(flush, d.pending[path])
+			delete(d.pending, path)
+			delete(d.timers, path)
+		}
+	}
+	d.mu.Unlock()
+
+	for _, event := range flush {
+		d.send(event)
+	}
+
+	d.fires.Wait()
+	close(d.out)
+}
+
+// debounce coalesces the events from in with the --debounce window
+func (mc *MonitorCommand) debounce(ctx context.Context, in <-chan FileEvent) <-chan FileEvent {
+	d := newDebouncer(ctx, mc.debounceWindow)
+
+	go func() {
+		for event := range in {
+			d.Add(event)
+		}
+		d.Close()
+	}()
+
+	return d.out
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// collectEvents reads events until the channel has been quiet for idle
+func collectEvents(events <-chan FileEvent, idle time.Duration) []FileEvent {
+	var got []FileEvent
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return got
+			}
+			got = append(got, event)
+		case <-time.After(idle):
+			return got
+		}
+	}
+}
+
+func TestDebouncerCoalescesEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d := newDebouncer(ctx, 50*time.Millisecond)
+	d.Add(FileEvent{Path: "a.md", Op: "create"})
+	d.Add(FileEvent{Path: "a.md", Op: "write"})
+	d.Add(FileEvent{Path: "b.md", Op: "write"})
+	d.Add(FileEvent{Path: "a.md", Op: "write"})
+
+	got := collectEvents(d.out, 300*time.Millisecond)
+
+	expected := map[string]string{"a.md": "create", "b.md": "write"}
+	if len(got) != len(expected) {
+		t.Fatalf("debouncer delivered %v, expected one event per path", got)
+	}
+	for _, event := range got {
+		if expected[event.Path] != event.Op {
+			t.Errorf("event for %s = %q, expected %q", event.Path, event.Op, expected[event.Path])
+		}
+	}
+}
+
+func TestDebouncerCloseFlushesPending(t *testing.T) {
+	d := newDebouncer(context.Background(), time.Hour)
+	d.Add(FileEvent{Path: "a.md", Op: "write"})
+	d.Close()
+
+	got := collectEvents(d.out, time.Second)
+	expected := []FileEvent{{Path: "a.md", Op: "write"}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Close() delivered %v, expected %v", got, expected)
+	}
+}
+
+func TestDebouncerRenameTakesOverPending(t *testing.T) {
+	d := newDebouncer(context.Background(), time.Hour)
+	d.Add(FileEvent{Path: "a.md", Op: "create"})
+	d.Add(FileEvent{Path: "b.md", OldPath: "a.md", Op: "rename"})
+	d.Close()
+
+	got := collectEvents(d.out, time.Second)
+	expected := []FileEvent{{Path: "b.md", Op: "create"}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("debouncer delivered %v, expected %v", got, expected)
+	}
+}
+
+func TestMergeEvent(t *testing.T) {
+	tests := []struct {
+		prev, next string
+		expected   string
+	}{
+		{"create", "write", "create"},
+		{"write", "write", "write"},
+		{"create", "remove", "remove"},
+		{"write", "rename", "rename"},
+		{"remove", "create", "create"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prev+"+"+tt.next, func(t *testing.T) {
+			got := mergeEvent(FileEvent{Path: "a", Op: tt.prev}, FileEvent{Path: "a", Op: tt.next})
+			if got.Op != tt.expected {
+				t.Errorf("mergeEvent() = %q, expected %q", got.Op, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMonitorDebouncesRapidWrites(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-debounce")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	mc := &MonitorCommand{
+		config:         &CommandConfig{},
+		paths:          []string{dir},
+		patterns:       []string{"*"},
+		debounceWindow: 200 * time.Millisecond,
+	}
+	if err := mc.startWatching(); err != nil {
+		t.Fatalf("startWatching() error = %v", err)
+	}
+	defer mc.watcher.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mc.events = mc.debounce(ctx, mc.watch(ctx))
+
+	path := filepath.Join(dir, "notes.md")
+	for i := 0; i < 3; i++ {
+		if err := ioutil.WriteFile(path, []byte{byte('a' + i)}, 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	got := collectEvents(mc.events, time.Second)
+	if len(got) != 1 || got[0].Path != path {
+		t.Errorf("monitor delivered %v, expected a single event for %s", got, path)
+	}
+}
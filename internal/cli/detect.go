@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// sniffLen is how much of a file is read to classify its content
+	sniffLen = 8000
+
+	// maxInvalidUTF8Ratio is the share of invalid UTF-8 bytes above which
+	// content without NUL bytes is still considered binary
+	maxInvalidUTF8Ratio = 0.3
+)
+
+// errBinaryFile is returned by processFile for files skipped as binary
+var errBinaryFile = errors.New("binary file")
+
+// readHead returns up to sniffLen bytes from the start of a file
+func readHead(filePath string) ([]byte, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// isBinary classifies content as binary if it contains a NUL byte or too
+// much of it is not valid UTF-8
+func isBinary(head []byte) bool {
+	if len(head) == 0 {
+		return false
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return true
+	}
+
+	invalid := 0
+	for i := 0; i < len(head); {
+		r, size := utf8.DecodeRune(head[i:])
+		if r == utf8.RuneError && size == 1 {
+			// A multibyte rune cut off by the end of the buffer is fine
+			if len(head)-i < utf8.UTFMax && !utf8.FullRune(head[i:]) {
+				break
+			}
+			invalid++
+		}
+		i += size
+	}
+
+	return float64(invalid)/float64(len(head)) > maxInvalidUTF8Ratio
+}
+
+// detectMimeType sniffs the media type of content, without parameters
+// such as charset
+func detectMimeType(head []byte) string {
+	mimeType := http.DetectContentType(head)
+	if i := strings.IndexByte(mimeType, ';'); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	return strings.TrimSpace(mimeType)
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  []byte
+		expected bool
+	}{
+		{"Empty", nil, false},
+		{"ASCII text", []byte("# README\n\nplain text\n"), false},
+		{"UTF-8 text", []byte("Привет, мир! こんにちは"), false},
+		{"NUL byte", []byte("text\x00more"), true},
+		{"PNG header", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), true},
+		{"Mostly invalid UTF-8", bytes.Repeat([]byte{0xff, 0xfe, 'a'}, 100), true},
+		{"Rune cut at buffer end", append(bytes.Repeat([]byte("a"), 10), "я"[0]), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isBinary(tt.content); got != tt.expected {
+				t.Errorf("isBinary(%q) = %v, expected %v", tt.content, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIndexSkipsBinaryFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-binary")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	text := filepath.Join(dir, "notes.txt")
+	binary := filepath.Join(dir, "image.png")
+	if err := ioutil.WriteFile(text, []byte(strings.Repeat("plain text\n", 100)), 0644); err != nil {
+		t.Fatalf("Failed to write text file: %v", err)
+	}
+	if err := ioutil.WriteFile(binary, []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), 0644); err != nil {
+		t.Fatalf("Failed to write binary file: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		includeBinary bool
+		wantProcessed int
+		wantSkipped   int
+	}{
+		{"Skip binary", false, 1, 1},
+		{"Include binary", true, 2, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ic := &IndexCommand{
+				config:        &CommandConfig{},
+				maxWorkers:    2,
+				batchSize:     10,
+				includeBinary: tt.includeBinary,
+			}
+
+			stats := &IndexStats{FileTypes: make(map[string]int)}
+			processed, errs := ic.processBatch(context.Background(), []string{text, binary}, 1, 1, stats)
+
+			if len(errs) != 0 {
+				t.Fatalf("processBatch() returned errors: %v", errs)
+			}
+			if processed != tt.wantProcessed || stats.SkippedBinary != tt.wantSkipped {
+				t.Errorf("processBatch() = %d processed, %d binary skipped, expected %d and %d",
+					processed, stats.SkippedBinary, tt.wantProcessed, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestDetectMimeType(t *testing.T) {
+	tests := []struct {
+		name     string
+		head     []byte
+		expected string
+	}{
+		{"Plain text", []byte("hello world\n"), "text/plain"},
+		{"HTML", []byte("<!DOCTYPE html><html></html>"), "text/html"},
+		{"PNG", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), "image/png"},
+		{"Empty", nil, "text/plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectMimeType(tt.head); got != tt.expected {
+				t.Errorf("detectMimeType() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIndexDetectsMimeTypes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-mime")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"notes.txt":  "plain text\n",
+		"README":     "no extension, still text\n",
+		"image.png":  "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR",
+		"index.html": "<html><body></body></html>",
+	}
+	var paths []string
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		paths = append(paths, path)
+	}
+
+	ic := &IndexCommand{
+		config:        &CommandConfig{},
+		maxWorkers:    2,
+		batchSize:     10,
+		includeBinary: true,
+		detectMime:    true,
+	}
+
+	stats := &IndexStats{FileTypes: make(map[string]int)}
+	if _, errs := ic.processBatch(context.Background(), paths, 1, 1, stats); len(errs) != 0 {
+		t.Fatalf("processBatch() returned errors: %v", errs)
+	}
+
+	expected := map[string]int{"text/plain": 2, "image/png": 1, "text/html": 1}
+	if len(stats.MimeTypes) != len(expected) {
+		t.Fatalf("MimeTypes = %v, expected %v", stats.MimeTypes, expected)
+	}
+	for mimeType, count := range expected {
+		if stats.MimeTypes[mimeType] != count {
+			t.Errorf("MimeTypes[%q] = %d, expected %d", mimeType, stats.MimeTypes[mimeType], count)
+		}
+	}
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if !bytes.Contains(data, []byte(`"mime_types":{`)) {
+		t.Errorf("report JSON = %s, expected mime_types", data)
+	}
+}
@@ -0,0 +1,32 @@
+package cli
+
+// defaultDiskWarnPercent is the disk usage above which health checks warn
+const defaultDiskWarnPercent = 80
+
+// DiskUsage is the space on the file system holding a path. Free is the
+// space available to this user, which may be less than Total - Used.
+type DiskUsage struct {
+	Path    string  `json:"path" yaml:"path"`
+	Total   uint64  `json:"total" yaml:"total"`
+	Used    uint64  `json:"used" yaml:"used"`
+	Free    uint64  `json:"free" yaml:"free"`
+	Percent float64 `json:"percent" yaml:"percent"`
+}
+
+// newDiskUsage computes the usage of a file system from its total, free
+// and user-available bytes. The percentage is of the space usable by
+// this user, as df reports it.
+func newDiskUsage(path string, total, free, available uint64) *DiskUsage {
+	usage := &DiskUsage{
+		Path:  path,
+		Total: total,
+		Free:  available,
+	}
+	if free < total {
+		usage.Used = total - free
+	}
+	if usable := usage.Used + available; usable > 0 {
+		usage.Percent = float64(usage.Used) / float64(usable) * 100
+	}
+	return usage
+}
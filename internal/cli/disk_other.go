@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !freebsd && !windows
+// +build !linux,!darwin,!freebsd,!windows
+
+package cli
+
+// diskUsage is not implemented on this platform
+func diskUsage(path string) (*DiskUsage, error) {
+	return nil, errUnsupportedPlatform
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDiskUsage(t *testing.T) {
+	disk, err := diskUsage(os.TempDir())
+	if err == errUnsupportedPlatform {
+		t.Skip("diskUsage() is not supported on this platform")
+	}
+	if err != nil {
+		t.Fatalf("diskUsage(%s) error = %v", os.TempDir(), err)
+	}
+
+	if disk.Total == 0 {
+		t.Errorf("diskUsage() total = 0, expected the size of the file system")
+	}
+	if disk.Used > disk.Total || disk.Free > disk.Total {
+		t.Errorf("diskUsage() = %d used, %d free of %d, expected both within the total", disk.Used, disk.Free, disk.Total)
+	}
+	if disk.Percent < 0 || disk.Percent > 100 {
+		t.Errorf("diskUsage() percent = %v, expected 0-100", disk.Percent)
+	}
+}
+
+func TestNewDiskUsage(t *testing.T) {
+	tests := []struct {
+		name                   string
+		total, free, available uint64
+		expectedUsed           uint64
+		expectedPercent        float64
+	}{
+		{"Half used", 1000, 500, 500, 500, 50},
+		{"Reserved blocks", 1000, 200, 100, 800, 800.0 / 900 * 100},
+		{"Empty", 1000, 1000, 1000, 0, 0},
+		{"Full", 1000, 0, 0, 1000, 100},
+		{"No blocks", 0, 0, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			disk := newDiskUsage("/", tt.total, tt.free, tt.available)
+			if disk.Used != tt.expectedUsed || disk.Percent != tt.expectedPercent {
+				t.Errorf("newDiskUsage() = %d used, %v%%, expected %d used, %v%%",
+					disk.Used, disk.Percent, tt.expectedUsed, tt.expectedPercent)
+			}
+		})
+	}
+}
+
+func TestCheckDiskSpaceThreshold(t *testing.T) {
+	if _, err := diskUsage(os.TempDir()); err != nil {
+		t.Skipf("diskUsage() is not available: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		warnPercent float64
+		expected    string
+	}{
+		{"Below threshold", 100, "ok"},
+		{"Above threshold", -1, "warning"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := &StatusCommand{workspace: os.TempDir(), diskWarnPercent: tt.warnPercent}
+			health := HealthStatus{Components: make(map[string]string)}
+
+			sc.checkDiskSpace(&health)
+			if got := health.Components["disk_space"]; got != tt.expected {
+				t.Errorf("disk_space = %q, expected %q", got, tt.expected)
+			}
+			if (len(health.Warnings) > 0) != (tt.expected == "warning") {
+				t.Errorf("warnings = %v, expected a warning only above the threshold", health.Warnings)
+			}
+			if health.Disk == nil {
+				t.Error("checkDiskSpace() did not record the disk usage")
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package cli
+
+import "syscall"
+
+// diskUsage reports the space on the file system holding path
+func diskUsage(path string) (*DiskUsage, error) {
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(path, &fs); err != nil {
+		return nil, err
+	}
+
+	blockSize := uint64(fs.Bsize)
+	return newDiskUsage(path, uint64(fs.Blocks)*blockSize, uint64(fs.Bfree)*blockSize, uint64(fs.Bavail)*blockSize), nil
+}
@@ -0,0 +1,20 @@
+//go:build windows
+// +build windows
+
+package cli
+
+import "golang.org/x/sys/windows"
+
+// diskUsage reports the space on the volume holding path
+func diskUsage(path string) (*DiskUsage, error) {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var available, total, free uint64
+	if err := windows.GetDiskFreeSpaceEx(name, &available, &total, &free); err != nil {
+		return nil, err
+	}
+	return newDiskUsage(path, total, free, available), nil
+}
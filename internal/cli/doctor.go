@@ -0,0 +1,325 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// Doctor check results, from best to worst
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+// DoctorCommand represents the doctor command configuration
+type DoctorCommand struct {
+	config          *CommandConfig
+	diskWarnPercent float64
+
+	// indexManifestPath overrides the default .stroidex/manifest.json
+	indexManifestPath string
+}
+
+// DoctorCheck is the result of one doctor check
+type DoctorCheck struct {
+	Name    string `json:"name" yaml:"name"`
+	Status  string `json:"status" yaml:"status"`
+	Message string `json:"message" yaml:"message"`
+	Hint    string `json:"hint,omitempty" yaml:"hint,omitempty"`
+}
+
+// DoctorReport is the structured output of the doctor command
+type DoctorReport struct {
+	Status string        `json:"status" yaml:"status"`
+	Checks []DoctorCheck `json:"checks" yaml:"checks"`
+}
+
+// NewDoctorCommand creates the doctor command
+func NewDoctorCommand(config *CommandConfig) *cobra.Command {
+	dc := &DoctorCommand{
+		config: config,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the workspace, config and index for problems",
+		Long: `Doctor checks that the workspace is writable, the config file and index
+manifest can be read, the index matches the files on disk and there is
+enough disk space, and reports the space reclaimed from temp files that
+earlier runs left behind. Each problem comes with a hint on how to fix it.
+
+Doctor exits with an error when any check fails; warnings do not fail.
+
+Examples:
+  stroidex doctor                          # Run all checks
+  stroidex doctor --disk-warn 90           # Warn above 90% disk usage
+  stroidex doctor -o json                  # Output as JSON`,
+		Args: cobra.NoArgs,
+		// The config file is checked rather than applied, so a broken
+		// one can be diagnosed
+		Annotations: map[string]string{skipConfigFileAnnotation: "true"},
+		RunE:        dc.runDoctor,
+	}
+
+	cmd.Flags().Float64Var(&dc.diskWarnPercent, "disk-warn", defaultDiskWarnPercent, "Disk usage percentage above which the disk check warns")
+
+	return cmd
+}
+
+// runDoctor executes the doctor command
+func (dc *DoctorCommand) runDoctor(cmd *cobra.Command, args []string) error {
+	report := dc.runChecks()
+	if err := dc.displayReport(report); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, check := range report.Checks {
+		if check.Status == checkFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d doctor check(s) failed", failed)
+	}
+	return nil
+}
+
+// runChecks runs every check and derives the overall status
+func (dc *DoctorCommand) runChecks() DoctorReport {
+	workspace := dc.config.resolvePath(".")
+
+	checks := []DoctorCheck{
+		dc.checkWorkspace(workspace),
+		dc.checkConfigFile(),
+	}
+
+	index, manifestCheck := dc.checkManifest()
+	checks = append(checks, manifestCheck)
+	if index != nil {
+		checks = append(checks, checkIndexConsistency(index))
+	}
+	checks = append(checks, dc.checkDisk(workspace), checkTempFiles())
+
+	report := DoctorReport{Status: checkPass, Checks: checks}
+	for _, check := range checks {
+		if check.Status == checkFail || (check.Status == checkWarn && report.Status == checkPass) {
+			report.Status = check.Status
+		}
+	}
+	return report
+}
+
+// checkWorkspace checks that stroidex can create its files in the
+// workspace. The probe is a managed temp file, so one left behind by a
+// killed run is swept like any other.
+func (dc *DoctorCommand) checkWorkspace(workspace string) DoctorCheck {
+	check := DoctorCheck{Name: "workspace"}
+
+	file, err := tempFiles.CreateTemp("doctor-*")
+	if err != nil {
+		check.Status = checkFail
+		check.Message = fmt.Sprintf("%s is not writable: %v", workspace, err)
+		check.Hint = "fix the directory permissions, or choose another workspace with --workspace or temp directory with --tmp-dir"
+		return check
+	}
+	file.Close()
+	os.Remove(file.Name())
+	tempFiles.Release(file.Name())
+
+	check.Status = checkPass
+	check.Message = fmt.Sprintf("%s is writable", workspace)
+	return check
+}
+
+// checkConfigFile checks that the config file, when there is one, parses
+// and holds valid settings
+func (dc *DoctorCommand) checkConfigFile() DoctorCheck {
+	check := DoctorCheck{Name: "config file"}
+
+	path, required := configFilePath(dc.config)
+	if path == "" {
+		check.Status = checkWarn
+		check.Message = "no config file: the home directory is unknown"
+		check.Hint = "set HOME or pass --config"
+		return check
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) && !required {
+		check.Status = checkWarn
+		check.Message = fmt.Sprintf("%s not found; using defaults", path)
+		check.Hint = "create it with 'stroidex config set <key> <value>'"
+		return check
+	}
+
+	fc, err := loadConfigFile(path, true)
+	if err != nil {
+		check.Status = checkFail
+		check.Message = err.Error()
+		check.Hint = fmt.Sprintf("fix or remove %s", path)
+		return check
+	}
+
+	for _, setting := range configSettings {
+		value := setting.get(fc)
+		if value == "" {
+			continue
+		}
+		if err := setting.set(&FileConfig{}, value); err != nil {
+			check.Status = checkFail
+			check.Message = fmt.Sprintf("%s: %v", path, err)
+			check.Hint = fmt.Sprintf("fix it with 'stroidex config set %s <value>'", setting.key)
+			return check
+		}
+	}
+
+	check.Status = checkPass
+	check.Message = fmt.Sprintf("%s is valid", path)
+	return check
+}
+
+// checkManifest checks that the index manifest can be read, returning it
+// when it can
+func (dc *DoctorCommand) checkManifest() (*IndexManifest, DoctorCheck) {
+	check := DoctorCheck{Name: "index manifest"}
+
+	path := dc.indexManifestPath
+	if path == "" {
+		path = dc.config.resolvePath(defaultIndexManifestPath())
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		check.Status = checkWarn
+		check.Message = fmt.Sprintf("%s not found; nothing is indexed", path)
+		check.Hint = "run 'stroidex index'"
+		return nil, check
+	}
+
+	index, err := LoadIndexManifest(path)
+	if err != nil {
+		check.Status = checkFail
+		check.Message = err.Error()
+		check.Hint = "rebuild it with 'stroidex index --force'"
+		return nil, check
+	}
+
+	check.Status = checkPass
+	check.Message = fmt.Sprintf("%s records %d file(s)", path, index.Len())
+	return index, check
+}
+
+// checkIndexConsistency compares the manifest entries with the files on
+// disk
+func checkIndexConsistency(index *IndexManifest) DoctorCheck {
+	check := DoctorCheck{Name: "index consistency"}
+
+	missing := len(missingFiles(index))
+	changed := 0
+	for key := range index.Files {
+		if info, err := os.Stat(key); err == nil && !index.Unchanged(key, info) {
+			changed++
+		}
+	}
+
+	switch {
+	case missing > 0:
+		check.Status = checkWarn
+		check.Message = fmt.Sprintf("%d indexed file(s) no longer exist", missing)
+		check.Hint = "prune them with 'stroidex clean'"
+	case changed > 0:
+		check.Status = checkWarn
+		check.Message = fmt.Sprintf("%d indexed file(s) changed since the last run", changed)
+		check.Hint = "update the index with 'stroidex index'"
+	default:
+		check.Status = checkPass
+		check.Message = "the index matches the files on disk"
+	}
+	return check
+}
+
+// checkDisk checks the free space on the disk holding the workspace
+func (dc *DoctorCommand) checkDisk(workspace string) DoctorCheck {
+	check := DoctorCheck{Name: "disk space"}
+
+	disk, err := diskUsage(workspace)
+	switch {
+	case err != nil:
+		check.Status = checkWarn
+		check.Message = fmt.Sprintf("failed to check disk space: %v", err)
+	case disk.Free == 0:
+		check.Status = checkFail
+		check.Message = fmt.Sprintf("no space left: %s", formatDiskUsage(disk))
+		check.Hint = fmt.Sprintf("free up space on the disk holding %s", filepath.Clean(workspace))
+	case disk.Percent > dc.diskWarnPercent:
+		check.Status = checkWarn
+		check.Message = fmt.Sprintf("%s, above %.0f%%", formatDiskUsage(disk), dc.diskWarnPercent)
+		check.Hint = fmt.Sprintf("free up space on the disk holding %s", filepath.Clean(workspace))
+	default:
+		check.Status = checkPass
+		check.Message = formatDiskUsage(disk)
+	}
+	return check
+}
+
+// checkTempFiles reports the orphaned temp files the startup sweep
+// removed
+func checkTempFiles() DoctorCheck {
+	check := DoctorCheck{Name: "temp files", Status: checkPass}
+
+	removed, reclaimed := tempFiles.Reclaimed()
+	if removed > 0 {
+		check.Message = fmt.Sprintf("reclaimed %s from %d orphaned temp file(s) in %s", formatBytes(reclaimed), removed, tempFiles.Dir())
+	} else {
+		check.Message = fmt.Sprintf("no orphaned temp files in %s", tempFiles.Dir())
+	}
+	return check
+}
+
+// displayReport shows the checks as a table or in the output format
+func (dc *DoctorCommand) displayReport(report DoctorReport) error {
+	renderer := NewRenderer(dc.config.OutputFormat, dc.config.Stdout())
+
+	// A CSV holds a single table: the checks
+	if dc.config.OutputFormat == "csv" {
+		for _, check := range report.Checks {
+			if err := renderer.Record("check", check); err != nil {
+				return err
+			}
+		}
+		return renderer.Flush()
+	}
+
+	if renderer != nil {
+		if err := renderer.Render("doctor", report); err != nil {
+			return err
+		}
+		return renderer.Flush()
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Check", "Status", "Details"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoWrapText(false)
+	for _, check := range report.Checks {
+		table.Append([]string{check.Name, activeTheme.PaintStatus(check.Status), check.Message})
+	}
+	table.Render()
+
+	printedHeading := false
+	for _, check := range report.Checks {
+		if check.Hint == "" {
+			continue
+		}
+		if !printedHeading {
+			fmt.Printf("\n%s\n", activeTheme.Paint(activeTheme.Info, "Hints:"))
+			printedHeading = true
+		}
+		fmt.Printf("  - %s: %s\n", check.Name, check.Hint)
+	}
+	return nil
+}
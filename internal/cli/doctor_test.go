@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// doctorChecks runs doctor in a workspace as JSON and returns the checks
+// by name
+func doctorChecks(t *testing.T, dir string, args ...string) (map[string]DoctorCheck, error) {
+	t.Helper()
+
+	output, err := runWorkspaceCommand(t, dir, append([]string{"--output", "json", "doctor"}, args...)...)
+
+	var report DoctorReport
+	if jsonErr := json.Unmarshal([]byte(output), &report); jsonErr != nil {
+		t.Fatalf("doctor output is not valid JSON: %v\n%s", jsonErr, output)
+	}
+
+	checks := make(map[string]DoctorCheck)
+	for _, check := range report.Checks {
+		checks[check.Name] = check
+	}
+	return checks, err
+}
+
+func TestDoctor(t *testing.T) {
+	preserveTheme(t)
+	t.Setenv("HOME", filepath.Dir(writeConfigFile(t, defaultConfigName, "output: table\n")))
+
+	dir := indexWorkspace(t, []string{"a.md", "b.md"})
+	checks, err := doctorChecks(t, dir, "--disk-warn", "100")
+	if err != nil {
+		t.Fatalf("doctor error = %v", err)
+	}
+
+	for _, name := range []string{"workspace", "config file", "index manifest", "index consistency", "disk space"} {
+		if checks[name].Status != checkPass {
+			t.Errorf("%s check = %+v, expected pass", name, checks[name])
+		}
+	}
+}
+
+func TestDoctorWorkspaceProbeUsesTempDir(t *testing.T) {
+	preserveTheme(t)
+	t.Setenv("HOME", filepath.Dir(writeConfigFile(t, defaultConfigName, "")))
+
+	dir := indexWorkspace(t, []string{"a.md"})
+	tmpDir := filepath.Join(dir, "tmp")
+	if _, err := doctorChecks(t, dir, "--tmp-dir", tmpDir, "--disk-warn", "100"); err != nil {
+		t.Fatalf("doctor error = %v", err)
+	}
+
+	// The probe went through the temp manager, which created the
+	// directory, and was removed
+	entries, err := ioutil.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatalf("Expected the probe to create %s: %v", tmpDir, err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected the probe to be removed, found %s", entries[0].Name())
+	}
+}
+
+func TestDoctorFailures(t *testing.T) {
+	preserveTheme(t)
+
+	tests := []struct {
+		name   string
+		setup  func(t *testing.T, dir string)
+		check  string
+		status string
+		hint   string
+	}{
+		{
+			"Corrupt manifest",
+			func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, defaultIndexManifestPath()), "{not json")
+			},
+			"index manifest", checkFail, "index --force",
+		},
+		{
+			"Unsupported manifest version",
+			func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, defaultIndexManifestPath()), `{"version": 99, "files": {}}`)
+			},
+			"index manifest", checkFail, "index --force",
+		},
+		{
+			"Invalid config file",
+			func(t *testing.T, dir string) {
+				t.Setenv("HOME", filepath.Dir(writeConfigFile(t, defaultConfigName, "theme: neon\n")))
+			},
+			"config file", checkFail, "config set theme",
+		},
+		{
+			"Deleted files",
+			func(t *testing.T, dir string) {
+				os.Remove(filepath.Join(dir, "a.md"))
+			},
+			"index consistency", checkWarn, "stroidex clean",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", filepath.Dir(writeConfigFile(t, defaultConfigName, "")))
+			dir := indexWorkspace(t, []string{"a.md", "b.md"})
+			tt.setup(t, dir)
+
+			checks, err := doctorChecks(t, dir, "--disk-warn", "100")
+			if (err != nil) != (tt.status == checkFail) {
+				t.Errorf("doctor error = %v, expected an error only for failures", err)
+			}
+
+			check := checks[tt.check]
+			if check.Status != tt.status || !strings.Contains(check.Hint, tt.hint) {
+				t.Errorf("%s check = %+v, expected %s with a hint about %q", tt.check, check, tt.status, tt.hint)
+			}
+		})
+	}
+}
+
+func TestDoctorReportsReclaimedTempFiles(t *testing.T) {
+	preserveTheme(t)
+	t.Setenv("HOME", filepath.Dir(writeConfigFile(t, defaultConfigName, "")))
+
+	// A fresh manager, so totals of other tests do not count
+	defer func(old *TempManager) { tempFiles = old }(tempFiles)
+	tempFiles = NewTempManager("")
+
+	dir := indexWorkspace(t, []string{"a.md"})
+	tmpDir := filepath.Join(dir, stateDirName, tempDirName)
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+
+	orphan := filepath.Join(tmpDir, "manifest-orphan.jsonl")
+	writeFile(t, orphan, strings.Repeat("x", 2048))
+	old := time.Now().Add(-2 * orphanTempAge)
+	if err := os.Chtimes(orphan, old, old); err != nil {
+		t.Fatalf("Failed to age %s: %v", orphan, err)
+	}
+	recent := filepath.Join(tmpDir, "manifest-recent.jsonl")
+	writeFile(t, recent, "in use")
+
+	checks, err := doctorChecks(t, dir, "--disk-warn", "100")
+	if err != nil {
+		t.Fatalf("doctor error = %v", err)
+	}
+
+	check := checks["temp files"]
+	expected := "reclaimed " + formatBytes(2048) + " from 1 orphaned temp file(s)"
+	if check.Status != checkPass || !strings.Contains(check.Message, expected) {
+		t.Errorf("temp files check = %+v, expected a pass reporting %q", check, expected)
+	}
+	if _, err := os.Stat(orphan); !os.IsNotExist(err) {
+		t.Errorf("Expected the orphaned temp file to be removed, stat error = %v", err)
+	}
+	if _, err := os.Stat(recent); err != nil {
+		t.Errorf("Expected the recent temp file to be kept: %v", err)
+	}
+}
+
+// writeFile overwrites a file with content
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// envPrefix prefixes the environment variables bound to global flags
+const envPrefix = "STROIDEX_"
+
+// envName returns the environment variable bound to a flag, e.g.
+// STROIDEX_NO_COLOR for --no-color
+func envName(flag string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// applyEnv sets the global flags not given on the command line from
+// their environment variables. Flags set this way count as given, so the
+// config file does not override them.
+func applyEnv(cmd *cobra.Command) error {
+	flags := cmd.Root().PersistentFlags()
+
+	var err error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed {
+			return
+		}
+		value, ok := os.LookupEnv(envName(flag.Name))
+		if !ok {
+			return
+		}
+		if setErr := flags.Set(flag.Name, value); setErr != nil {
+			err = fmt.Errorf("invalid %s: %w", envName(flag.Name), setErr)
+		}
+	})
+	return err
+}
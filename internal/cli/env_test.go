@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	tests := []struct {
+		flag     string
+		expected string
+	}{
+		{"output", "STROIDEX_OUTPUT"},
+		{"no-color", "STROIDEX_NO_COLOR"},
+		{"tmp-dir", "STROIDEX_TMP_DIR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.flag, func(t *testing.T) {
+			if got := envName(tt.flag); got != tt.expected {
+				t.Errorf("envName(%s) = %s, expected %s", tt.flag, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEnvBinding(t *testing.T) {
+	path := writeConfigFile(t, "stroidex.yaml", "output: yaml\ntheme: light\n")
+
+	preserveTheme(t)
+
+	tests := []struct {
+		name   string
+		args   []string
+		output string
+		theme  string
+	}{
+		{"Env overrides defaults", nil, "json", "dark"},
+		{"Env overrides config file", []string{"--config", path}, "json", "dark"},
+		{"Flags override env", []string{"--output", "csv", "--theme", "none"}, "csv", "none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("STROIDEX_OUTPUT", "json")
+			t.Setenv("STROIDEX_THEME", "dark")
+			t.Setenv("STROIDEX_NO_COLOR", "true")
+
+			cli := NewCLI()
+			cli.RootCmd.SetArgs(append(tt.args, "index", "--print-config", "."))
+			captureStdout(t, func() {
+				if err := cli.RootCmd.Execute(); err != nil {
+					t.Fatalf("Execute() error = %v", err)
+				}
+			})
+
+			if cli.Config.OutputFormat != tt.output {
+				t.Errorf("OutputFormat = %s, expected %s", cli.Config.OutputFormat, tt.output)
+			}
+			if cli.Config.Theme != tt.theme {
+				t.Errorf("Theme = %s, expected %s", cli.Config.Theme, tt.theme)
+			}
+			if !cli.Config.NoColor {
+				t.Error("NoColor = false, expected true from STROIDEX_NO_COLOR")
+			}
+		})
+	}
+}
+
+func TestEnvBindingConfigPath(t *testing.T) {
+	path := writeConfigFile(t, "stroidex.yaml", "output: yaml\n")
+	t.Setenv("STROIDEX_CONFIG", path)
+
+	preserveTheme(t)
+
+	cli := NewCLI()
+	cli.RootCmd.SetArgs([]string{"index", "--print-config", "."})
+	captureStdout(t, func() {
+		if err := cli.RootCmd.Execute(); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	})
+
+	if cli.Config.OutputFormat != "yaml" {
+		t.Errorf("OutputFormat = %s, expected yaml from STROIDEX_CONFIG", cli.Config.OutputFormat)
+	}
+}
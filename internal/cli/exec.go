@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// execPlaceholder is replaced with the changed path in --exec commands
+const execPlaceholder = "{}"
+
+// changeRunner runs the --exec command for changes, one at a time. With
+// a debounce window a burst of changes runs the command once, for the
+// last changed path, after the burst has been quiet for the window.
+type changeRunner struct {
+	mu      sync.Mutex
+	command string
+	dir     string
+	window  time.Duration
+	timer   *time.Timer
+	last    string
+	stopped bool
+
+	// out receives the command output
+	out io.Writer
+
+	// scheduled counts debounced runs that are scheduled or in flight,
+	// so Stop can wait for them
+	scheduled sync.WaitGroup
+
+	// running serializes command runs so their output does not interleave
+	running sync.Mutex
+}
+
+// newChangeRunner creates a runner for command, executed in dir, that
+// writes the command output to out
+func newChangeRunner(command, dir string, window time.Duration, out io.Writer) *changeRunner {
+	return &changeRunner{
+		command: command,
+		dir:     dir,
+		window:  window,
+		out:     out,
+	}
+}
+
+// Changed runs the command for path, or schedules it when debouncing.
+// Changes after Stop are ignored.
+func (r *changeRunner) Changed(ctx context.Context, path string) {
+	if r.window <= 0 {
+		r.run(ctx, path)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopped {
+		return
+	}
+
+	r.last = path
+	if r.timer != nil && r.timer.Stop() {
+		r.scheduled.Done()
+	}
+	r.scheduled.Add(1)
+	r.timer = time.AfterFunc(r.window, func() {
+		defer r.scheduled.Done()
+
+		r.mu.Lock()
+		path := r.last
+		r.mu.Unlock()
+
+		r.run(ctx, path)
+	})
+}
+
+// Flush runs a scheduled command now rather than after the window, for
+// callers that will not see more changes
+func (r *changeRunner) Flush(ctx context.Context) {
+	if path, ok := r.takePending(false); ok {
+		defer r.scheduled.Done()
+		r.run(ctx, path)
+	}
+}
+
+// Stop runs a scheduled command now and waits for any command in
+// flight to exit. The pending change has already happened, so it is
+// run even when the monitor is being cancelled.
+func (r *changeRunner) Stop() {
+	if path, ok := r.takePending(true); ok {
+		r.run(context.Background(), path)
+		r.scheduled.Done()
+	}
+	r.scheduled.Wait()
+}
+
+// takePending cancels the scheduled run, if any, and returns its path.
+// With stop set no further runs are scheduled.
+func (r *changeRunner) takePending(stop bool) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stop {
+		r.stopped = true
+	}
+	if r.timer == nil || !r.timer.Stop() {
+		return "", false
+	}
+	return r.last, true
+}
+
+// run executes the command for path and prints its output. A failing
+// command is reported but never stops the monitor.
+func (r *changeRunner) run(ctx context.Context, path string) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	r.running.Lock()
+	defer r.running.Unlock()
+
+	cmd := shellCommand(ctx, expandExec(r.command, path))
+	cmd.Dir = r.dir
+
+	output, err := cmd.CombinedOutput()
+	if len(output) > 0 {
+		fmt.Fprint(r.out, string(output))
+		if output[len(output)-1] != '\n' {
+			fmt.Fprintln(r.out)
+		}
+	}
+	if err != nil {
+		PrintWarning(fmt.Sprintf("Command for %s failed: %v", path, err))
+	}
+}
+
+// expandExec substitutes the shell-quoted path for each placeholder in
+// command
+func expandExec(command, path string) string {
+	return strings.ReplaceAll(command, execPlaceholder, shellQuote(path))
+}
+
+// shellQuote quotes s as a single argument for the platform shell
+func shellQuote(s string) string {
+	if runtime.GOOS == "windows" {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellCommand runs command through the platform shell
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandExec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("POSIX shell quoting")
+	}
+
+	tests := []struct {
+		name     string
+		command  string
+		path     string
+		expected string
+	}{
+		{"Single placeholder", "cat {}", "docs/a.md", "cat 'docs/a.md'"},
+		{"Spaces", "wc -l {}", "my notes.md", "wc -l 'my notes.md'"},
+		{"Quote in path", "cat {}", "it's.md", `cat 'it'\''s.md'`},
+		{"Repeated placeholder", "cp {} {}.bak", "a", "cp 'a' 'a'.bak"},
+		{"No placeholder", "make build", "a.md", "make build"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandExec(tt.command, tt.path); got != tt.expected {
+				t.Errorf("expandExec() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMonitorExecRunsOnChange(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell command")
+	}
+
+	dir, err := ioutil.TempDir("", "stroidex-exec")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	marker := filepath.Join(dir, "marker")
+	mc := &MonitorCommand{
+		config: &CommandConfig{},
+		runner: newChangeRunner("echo {} >> "+shellQuote(marker), "", 0, ioutil.Discard),
+	}
+
+	events := []FileEvent{{Path: "a.md", Op: "write"}, {Path: "b md", Op: "create"}}
+	captureStdout(t, func() {
+		if err := mc.processEvents(context.Background(), events); err != nil {
+			t.Errorf("processEvents() returned error: %v", err)
+		}
+	})
+
+	data, err := ioutil.ReadFile(marker)
+	if err != nil {
+		t.Fatalf("Expected the command to write %s: %v", marker, err)
+	}
+	if got := string(data); got != "a.md\nb md\n" {
+		t.Errorf("marker = %q, expected one line per changed path", got)
+	}
+}
+
+func TestMonitorExecFailureKeepsRunning(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell command")
+	}
+
+	var out bytes.Buffer
+	mc := &MonitorCommand{
+		config: &CommandConfig{},
+		runner: newChangeRunner("echo failing {}; exit 3", "", 0, &out),
+	}
+
+	var stderr bytes.Buffer
+	SetOutputWriters(nil, &stderr)
+	defer SetOutputWriters(nil, nil)
+
+	captureStdout(t, func() {
+		if err := mc.processEvents(context.Background(), []FileEvent{{Path: "a.md", Op: "write"}}); err != nil {
+			t.Errorf("processEvents() returned error: %v", err)
+		}
+	})
+
+	if !strings.Contains(out.String(), "failing a.md") {
+		t.Errorf("output = %q, expected the command output", out.String())
+	}
+	if !strings.Contains(stderr.String(), "Command for a.md failed") {
+		t.Errorf("stderr = %q, expected a failure warning", stderr.String())
+	}
+}
+
+func TestMonitorExecDebounce(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell command")
+	}
+
+	dir, err := ioutil.TempDir("", "stroidex-exec-debounce")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	marker := filepath.Join(dir, "marker")
+	var out bytes.Buffer
+	mc := &MonitorCommand{
+		config: &CommandConfig{},
+		runner: newChangeRunner("echo {} >> "+shellQuote(marker)+"; echo ran {}", "", 100*time.Millisecond, &out),
+	}
+
+	captureStdout(t, func() {
+		for _, path := range []string{"a.md", "b.md", "c.md"} {
+			if err := mc.processEvents(context.Background(), []FileEvent{{Path: path, Op: "write"}}); err != nil {
+				t.Errorf("processEvents() returned error: %v", err)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(marker); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	mc.runner.Stop()
+
+	if got := out.String(); got != "ran c.md\n" {
+		t.Errorf("output = %q, expected the output of a single run", got)
+	}
+
+	data, err := ioutil.ReadFile(marker)
+	if err != nil {
+		t.Fatalf("Expected the command to run: %v", err)
+	}
+	if got := string(data); got != "c.md\n" {
+		t.Errorf("marker = %q, expected a single run for the last path", got)
+	}
+}
+
+func TestChangeRunnerStop(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell command")
+	}
+
+	dir, err := ioutil.TempDir("", "stroidex-exec-stop")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	t.Run("flushes a pending run", func(t *testing.T) {
+		marker := filepath.Join(dir, "pending")
+		runner := newChangeRunner("echo {} >> "+shellQuote(marker), "", time.Hour, ioutil.Discard)
+
+		runner.Changed(context.Background(), "a.md")
+		runner.Stop()
+
+		data, err := ioutil.ReadFile(marker)
+		if err != nil {
+			t.Fatalf("Expected Stop to run the pending command: %v", err)
+		}
+		if got := string(data); got != "a.md\n" {
+			t.Errorf("marker = %q, expected the pending path", got)
+		}
+
+		runner.Changed(context.Background(), "b.md")
+		runner.Stop()
+		if data, _ := ioutil.ReadFile(marker); string(data) != "a.md\n" {
+			t.Errorf("marker = %q, expected changes after Stop to be ignored", data)
+		}
+	})
+
+	t.Run("waits for a running command", func(t *testing.T) {
+		marker := filepath.Join(dir, "running")
+		runner := newChangeRunner("sleep 0.3; echo {} >> "+shellQuote(marker), "", 10*time.Millisecond, ioutil.Discard)
+
+		runner.Changed(context.Background(), "a.md")
+		time.Sleep(100 * time.Millisecond)
+		runner.Stop()
+
+		if _, err := os.Stat(marker); err != nil {
+			t.Errorf("Expected Stop to wait for the command to exit: %v", err)
+		}
+	})
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+// +build !windows
+
+package cli
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileID identifies a file independently of the path used to reach it
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+// identify returns the device and inode of a file
+func identify(path string, info os.FileInfo) (fileID, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}, false
+	}
+	return fileID{dev: uint64(st.Dev), ino: uint64(st.Ino)}, true
+}
@@ -0,0 +1,28 @@
+//go:build windows
+// +build windows
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// fileID identifies a file independently of the path used to reach it
+type fileID struct {
+	path string
+}
+
+// identify returns the fully resolved path of a file, as inodes are not
+// exposed through os.FileInfo on Windows
+func identify(path string, info os.FileInfo) (fileID, bool) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return fileID{}, false
+	}
+	abs, err := filepath.Abs(resolved)
+	if err != nil {
+		return fileID{}, false
+	}
+	return fileID{path: abs}, true
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		relPath  string
+		expected bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/tool/main.go", true},
+		{"*.go", "main.md", false},
+		{"src/**/*.go", "src/main.go", true},
+		{"src/**/*.go", "src/a/b/c/main.go", true},
+		{"src/**/*.go", "lib/src/main.go", false},
+		{"docs/**", "docs/guide.md", true},
+		{"docs/**", "docs/a/b/guide.md", true},
+		{"docs/**", "docsx/guide.md", false},
+		{"**/testdata/**", "testdata/input.txt", true},
+		{"**/testdata/**", "pkg/parser/testdata/input.txt", true},
+		{"**/testdata/**", "pkg/testdata.go", false},
+		{"cmd/**/*.go", "cmd/stroidex/main.go", true},
+		{"cmd/**/*.go", "internal/cmd/main.go", false},
+		{"cmd/*.go", "cmd/main.go", true},
+		{"cmd/*.go", "cmd/sub/main.go", false},
+		{"/cmd/*.go", "cmd/main.go", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.relPath, func(t *testing.T) {
+			if got := matchGlob(tt.pattern, filepath.FromSlash(tt.relPath)); got != tt.expected {
+				t.Errorf("matchGlob(%q, %q) = %v, expected %v", tt.pattern, tt.relPath, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIndexCollectFilesDoublestar(t *testing.T) {
+	root, err := ioutil.TempDir("", "stroidex-glob")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	for _, name := range []string{
+		"README.md",
+		"src/main.go",
+		"src/util/strings.go",
+		"src/util/testdata/golden.go",
+		"src/util/deep/er/path.go",
+		"docs/guide.md",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	ic := &IndexCommand{
+		config:       &CommandConfig{},
+		paths:        []string{root},
+		recursive:    true,
+		maxDepth:     -1,
+		patterns:     []string{"src/**/*.go", "README.md"},
+		excludePaths: []string{"**/testdata/**"},
+	}
+
+	files, err := ic.collectFiles(context.Background(), &IndexStats{})
+	if err != nil {
+		t.Fatalf("collectFiles() returned error: %v", err)
+	}
+
+	var rel []string
+	for _, f := range files {
+		r, _ := filepath.Rel(root, f)
+		rel = append(rel, filepath.ToSlash(r))
+	}
+	sort.Strings(rel)
+
+	expected := []string{"README.md", "src/main.go", "src/util/deep/er/path.go", "src/util/strings.go"}
+	if !reflect.DeepEqual(rel, expected) {
+		t.Errorf("collectFiles() = %v, expected %v", rel, expected)
+	}
+}
@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math/bits"
+	"os"
+	"sort"
+	"strings"
+)
+
+// defaultHashAlgorithm hashes file contents when no --hash is chosen. It
+// is fast and not cryptographic; the hashes only detect changes.
+const defaultHashAlgorithm = "xxhash"
+
+// legacyHashAlgorithm is the algorithm of manifest entries written before
+// the algorithm was recorded
+const legacyHashAlgorithm = "sha256"
+
+// hashAlgorithms are the content hashes --hash selects from
+var hashAlgorithms = map[string]func() hash.Hash{
+	"xxhash": func() hash.Hash { return newXXHash64() },
+	"sha256": sha256.New,
+	"crc32":  func() hash.Hash { return crc32.NewIEEE() },
+}
+
+// hashAlgorithmNames returns the supported algorithms, sorted
+func hashAlgorithmNames() []string {
+	names := make([]string, 0, len(hashAlgorithms))
+	for name := range hashAlgorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateHashAlgorithm checks that algorithm is supported
+func validateHashAlgorithm(algorithm string) error {
+	if _, ok := hashAlgorithms[algorithm]; !ok {
+		return fmt.Errorf("invalid hash algorithm: %s (valid: %s)", algorithm, strings.Join(hashAlgorithmNames(), ", "))
+	}
+	return nil
+}
+
+// hashFile returns the hex hash of a file's contents under algorithm
+func hashFile(filePath, algorithm string) (string, error) {
+	newHash, ok := hashAlgorithms[algorithm]
+	if !ok {
+		return "", validateHashAlgorithm(algorithm)
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := newHash()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// XXH64 primes; variables so sums of them may wrap around
+var (
+	xxPrime1 uint64 = 11400714785074694791
+	xxPrime2 uint64 = 14029467366897019727
+	xxPrime3 uint64 = 1609587929392839161
+	xxPrime4 uint64 = 9650029242287828579
+	xxPrime5 uint64 = 2870177450012600261
+)
+
+// xxHash64 is a streaming XXH64 with seed 0
+type xxHash64 struct {
+	v1, v2, v3, v4 uint64
+	total          uint64
+	mem            [32]byte
+	n              int // bytes buffered in mem
+}
+
+// newXXHash64 returns a new XXH64 hash
+func newXXHash64() *xxHash64 {
+	h := &xxHash64{}
+	h.Reset()
+	return h
+}
+
+func (h *xxHash64) Reset() {
+	h.v1 = xxPrime1 + xxPrime2
+	h.v2 = xxPrime2
+	h.v3 = 0
+	h.v4 = -xxPrime1
+	h.total = 0
+	h.n = 0
+}
+
+func (h *xxHash64) Size() int      { return 8 }
+func (h *xxHash64) BlockSize() int { return 32 }
+
+func (h *xxHash64) Write(b []byte) (int, error) {
+	n := len(b)
+	h.total += uint64(n)
+
+	// Fill the buffer of a previous short write first
+	if h.n+len(b) < 32 {
+		h.n += copy(h.mem[h.n:], b)
+		return n, nil
+	}
+	if h.n > 0 {
+		c := copy(h.mem[h.n:], b)
+		h.v1 = xxRound(h.v1, binary.LittleEndian.Uint64(h.mem[0:8]))
+		h.v2 = xxRound(h.v2, binary.LittleEndian.Uint64(h.mem[8:16]))
+		h.v3 = xxRound(h.v3, binary.LittleEndian.Uint64(h.mem[16:24]))
+		h.v4 = xxRound(h.v4, binary.LittleEndian.Uint64(h.mem[24:32]))
+		b = b[c:]
+		h.n = 0
+	}
+
+	for ; len(b) >= 32; b = b[32:] {
+		h.v1 = xxRound(h.v1, binary.LittleEndian.Uint64(b[0:8]))
+		h.v2 = xxRound(h.v2, binary.LittleEndian.Uint64(b[8:16]))
+		h.v3 = xxRound(h.v3, binary.LittleEndian.Uint64(b[16:24]))
+		h.v4 = xxRound(h.v4, binary.LittleEndian.Uint64(b[24:32]))
+	}
+	h.n = copy(h.mem[:], b)
+	return n, nil
+}
+
+func (h *xxHash64) Sum(b []byte) []byte {
+	var sum [8]byte
+	binary.BigEndian.PutUint64(sum[:], h.Sum64())
+	return append(b, sum[:]...)
+}
+
+func (h *xxHash64) Sum64() uint64 {
+	var acc uint64
+	if h.total >= 32 {
+		acc = bits.RotateLeft64(h.v1, 1) + bits.RotateLeft64(h.v2, 7) +
+			bits.RotateLeft64(h.v3, 12) + bits.RotateLeft64(h.v4, 18)
+		acc = xxMergeRound(acc, h.v1)
+		acc = xxMergeRound(acc, h.v2)
+		acc = xxMergeRound(acc, h.v3)
+		acc = xxMergeRound(acc, h.v4)
+	} else {
+		acc = xxPrime5
+	}
+	acc += h.total
+
+	b := h.mem[:h.n]
+	for ; len(b) >= 8; b = b[8:] {
+		acc ^= xxRound(0, binary.LittleEndian.Uint64(b))
+		acc = bits.RotateLeft64(acc, 27)*xxPrime1 + xxPrime4
+	}
+	if len(b) >= 4 {
+		acc ^= uint64(binary.LittleEndian.Uint32(b)) * xxPrime1
+		acc = bits.RotateLeft64(acc, 23)*xxPrime2 + xxPrime3
+		b = b[4:]
+	}
+	for _, c := range b {
+		acc ^= uint64(c) * xxPrime5
+		acc = bits.RotateLeft64(acc, 11) * xxPrime1
+	}
+
+	acc ^= acc >> 33
+	acc *= xxPrime2
+	acc ^= acc >> 29
+	acc *= xxPrime3
+	acc ^= acc >> 32
+	return acc
+}
+
+func xxRound(acc, input uint64) uint64 {
+	acc += input * xxPrime2
+	acc = bits.RotateLeft64(acc, 31)
+	return acc * xxPrime1
+}
+
+func xxMergeRound(acc, val uint64) uint64 {
+	val = xxRound(0, val)
+	acc ^= val
+	return acc*xxPrime1 + xxPrime4
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestXXHash64(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected uint64
+	}{
+		{"", 0xef46db3751d8e999},
+		{"a", 0xd24ec4f1a98c6e5b},
+		{"abc", 0x44bc2cf5ad770999},
+		{"Nobody inspects the spammish repetition", 0xfbcea83c8a378bf1},
+	}
+
+	for _, tt := range tests {
+		h := newXXHash64()
+		h.Write([]byte(tt.input))
+		if got := h.Sum64(); got != tt.expected {
+			t.Errorf("xxhash(%q) = %016x, expected %016x", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestXXHash64Streaming(t *testing.T) {
+	data := []byte(strings.Repeat("stroidex indexes documents. ", 20))
+
+	whole := newXXHash64()
+	whole.Write(data)
+
+	for _, chunk := range []int{1, 3, 7, 31, 32, 33, 100} {
+		h := newXXHash64()
+		for i := 0; i < len(data); i += chunk {
+			end := i + chunk
+			if end > len(data) {
+				end = len(data)
+			}
+			h.Write(data[i:end])
+		}
+		if h.Sum64() != whole.Sum64() {
+			t.Errorf("writing in chunks of %d gives %016x, expected %016x", chunk, h.Sum64(), whole.Sum64())
+		}
+	}
+}
+
+func TestHashFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-hash")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "file.txt")
+	if err := ioutil.WriteFile(path, []byte("abc"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	expected := map[string]string{
+		"xxhash": "44bc2cf5ad770999",
+		"sha256": "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		"crc32":  "352441c2",
+	}
+	for algorithm, want := range expected {
+		got, err := hashFile(path, algorithm)
+		if err != nil {
+			t.Fatalf("hashFile(%s) error = %v", algorithm, err)
+		}
+		if got != want {
+			t.Errorf("hashFile(%s) = %s, expected %s", algorithm, got, want)
+		}
+	}
+
+	if _, err := hashFile(path, "md5"); err == nil || !strings.Contains(err.Error(), "invalid hash algorithm") {
+		t.Errorf("hashFile(md5) error = %v, expected invalid hash algorithm", err)
+	}
+}
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// gitIgnoreFile is read from every directory visited during collection
+	gitIgnoreFile = ".gitignore"
+
+	// stroidexIgnoreFile is read from each collection root and takes
+	// precedence over .gitignore
+	stroidexIgnoreFile = ".stroidexignore"
+)
+
+// ignoreRule is a single pattern from an ignore file
+type ignoreRule struct {
+	base     string   // slash-separated directory the rule applies below, "" for the root
+	segments []string // pattern split on "/"
+	negate   bool     // pattern started with "!"
+	dirOnly  bool     // pattern ended with "/"
+	anchored bool     // pattern contained a "/" before its end
+}
+
+// IgnoreMatcher applies gitignore rules collected while walking a tree.
+// Paths are relative to the walk root; the last matching rule wins.
+type IgnoreMatcher struct {
+	rules []ignoreRule
+}
+
+// NewIgnoreMatcher creates a matcher that ignores .git directories
+func NewIgnoreMatcher() *IgnoreMatcher {
+	m := &IgnoreMatcher{}
+	m.AddPatterns("", []string{".git/"})
+	return m
+}
+
+// AddFile reads the ignore file at filePath, if it exists, and adds its
+// rules relative to base
+func (m *IgnoreMatcher) AddFile(filePath, base string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	m.AddPatterns(base, lines)
+	return nil
+}
+
+// AddPatterns adds gitignore pattern lines relative to base
+func (m *IgnoreMatcher) AddPatterns(base string, lines []string) {
+	base = strings.Trim(filepath.ToSlash(base), "/")
+	if base == "." {
+		base = ""
+	}
+
+	for _, line := range lines {
+		if rule, ok := parseIgnoreRule(base, line); ok {
+			m.rules = append(m.rules, rule)
+		}
+	}
+}
+
+// parseIgnoreRule parses one line of an ignore file, reporting false for
+// blank lines and comments
+func parseIgnoreRule(base, line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	rule := ignoreRule{base: base}
+
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+
+	if strings.Contains(line, "/") {
+		rule.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	rule.segments = strings.Split(line, "/")
+	return rule, true
+}
+
+// Match reports whether relPath, relative to the walk root, is ignored.
+// A path inside an ignored directory is ignored too, and cannot be
+// re-included by a negated pattern.
+func (m *IgnoreMatcher) Match(relPath string, isDir bool) bool {
+	relPath = strings.Trim(filepath.ToSlash(relPath), "/")
+	if relPath == "" || relPath == "." {
+		return false
+	}
+
+	for i, c := range relPath {
+		if c == '/' && m.matchRules(relPath[:i], true) {
+			return true
+		}
+	}
+
+	return m.matchRules(relPath, isDir)
+}
+
+// matchRules applies the rules to a single path, last match winning
+func (m *IgnoreMatcher) matchRules(relPath string, isDir bool) bool {
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.matches(relPath, isDir) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// matches reports whether the rule applies to relPath
+func (r ignoreRule) matches(relPath string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+
+	if r.base != "" {
+		if !strings.HasPrefix(relPath, r.base+"/") {
+			return false
+		}
+		relPath = relPath[len(r.base)+1:]
+	}
+
+	segments := strings.Split(relPath, "/")
+
+	if !r.anchored {
+		// A pattern without a slash matches the name at any depth
+		ok, _ := path.Match(r.segments[0], segments[len(segments)-1])
+		return ok
+	}
+
+	return matchSegments(r.segments, segments)
+}
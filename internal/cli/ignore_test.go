@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	m := NewIgnoreMatcher()
+	m.AddPatterns("", []string{
+		"# comment",
+		"",
+		"*.log",
+		"!keep.log",
+		"build/",
+		"/root-only.txt",
+		"docs/**/draft.md",
+		"cache/**",
+	})
+	m.AddPatterns("sub", []string{"local.txt", "!*.log"})
+
+	tests := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"app.log", false, true},
+		{"nested/deep/app.log", false, true},
+		{"keep.log", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"build/out.bin", false, true},
+		{"root-only.txt", false, true},
+		{"nested/root-only.txt", false, false},
+		{"docs/draft.md", false, true},
+		{"docs/a/b/draft.md", false, true},
+		{"docs/final.md", false, false},
+		{"cache", true, false},
+		{"cache/x/y", false, true},
+		{".git", true, true},
+		{"sub/local.txt", false, true},
+		{"local.txt", false, false},
+		{"sub/app.log", false, false},
+		{"README.md", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := m.Match(tt.path, tt.isDir); got != tt.expected {
+				t.Errorf("Match(%q, %v) = %v, expected %v", tt.path, tt.isDir, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIndexCollectFilesHonorsIgnoreFiles(t *testing.T) {
+	root, err := ioutil.TempDir("", "stroidex-ignore")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	files := map[string]string{
+		".gitignore":                 "*.log\nnode_modules/\n",
+		".stroidexignore":            "secret.txt\n",
+		"main.go":                    "",
+		"debug.log":                  "",
+		"secret.txt":                 "",
+		"node_modules/pkg/index.js":  "",
+		".git/HEAD":                  "",
+		"app/.gitignore":             "!important.log\ngenerated/\n",
+		"app/important.log":          "",
+		"app/other.log":              "",
+		"app/generated/code.go":      "",
+		"app/handler.go":             "",
+		"lib/.gitignore":             "*.go\n!keep.go\n",
+		"lib/drop.go":                "",
+		"lib/keep.go":                "",
+		"lib/nested/also-dropped.go": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	collect := func(noIgnore bool) []string {
+		ic := &IndexCommand{
+			config:    &CommandConfig{},
+			paths:     []string{root},
+			recursive: true,
+			maxDepth:  -1,
+			patterns:  []string{"*"},
+			noIgnore:  noIgnore,
+		}
+
+		collected, err := ic.collectFiles(context.Background(), &IndexStats{})
+		if err != nil {
+			t.Fatalf("collectFiles() returned error: %v", err)
+		}
+
+		var rel []string
+		for _, f := range collected {
+			r, _ := filepath.Rel(root, f)
+			rel = append(rel, filepath.ToSlash(r))
+		}
+		sort.Strings(rel)
+		return rel
+	}
+
+	expected := []string{
+		".gitignore",
+		".stroidexignore",
+		"app/.gitignore",
+		"app/handler.go",
+		"app/important.log",
+		"lib/.gitignore",
+		"lib/keep.go",
+		"main.go",
+	}
+	if got := collect(false); !reflect.DeepEqual(got, expected) {
+		t.Errorf("collectFiles() = %v, expected %v", got, expected)
+	}
+
+	if got := collect(true); len(got) != len(files) {
+		t.Errorf("collectFiles() with --no-ignore found %d files, expected %d: %v", len(got), len(files), got)
+	}
+}
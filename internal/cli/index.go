@@ -1,11 +1,18 @@
 package cli
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -13,28 +20,129 @@ import (
 
 // IndexCommand represents the index command configuration
 type IndexCommand struct {
-	config       *CommandConfig
-	paths        []string
-	recursive    bool
-	dryRun       bool
-	force        bool
-	patterns     []string
-	excludePaths []string
-	maxWorkers   int
-	batchSize    int
-	indexType    string
-}
-
-// IndexStats represents indexing statistics
+	config        *CommandConfig
+	paths         []string
+	recursive     bool
+	maxDepth      int
+	followLinks   bool
+	dryRun        bool
+	force         bool
+	patterns      []string
+	excludePaths  []string
+	excludeDirs   []string
+	includeFrom   string
+	excludeFrom   string
+	hashAlgorithm string
+	maxWorkers    int
+	batchSize     int
+	indexType     string
+	noIgnore      bool
+	ignoreCase    bool
+	includeBinary bool
+	detectMime    bool
+	fromStdin     bool
+	maxSize       string
+	minSize       string
+	maxBytes      int64
+	minBytes      int64
+
+	modifiedSince  string
+	modifiedCutoff time.Time
+
+	reportPath   string
+	printConfig  bool
+	manifestPath string
+	manifest     *RunManifest
+
+	// auditLogPath is where --audit-log appends a line per handled file
+	auditLogPath string
+	auditLog     *AuditLog
+
+	// indexManifestPath overrides the default .stroidex/manifest.json
+	indexManifestPath string
+	index             *IndexManifest
+
+	checkpointInterval int
+	resume             bool
+
+	// checkpointPath overrides the default .stroidex/checkpoint.json
+	checkpointPath string
+	checkpoint     *Checkpoint
+
+	progress     *ProgressGroup
+	totalBar     *ProgressBar
+	progressMode string
+	summaryOnly  bool
+
+	// bytes drives the overall bar with --progress=bytes
+	bytes *byteProgress
+
+	// watch keeps reindexing files as they change after the initial run
+	watch bool
+
+	// rootBars holds a bar per indexed root when there are several, and
+	// fileRoots the root each collected file came from
+	rootBars  map[string]*ProgressBar
+	fileRoots map[string]string
+
+	// progressOut receives the progress bars or --progress=json events;
+	// os.Stderr when nil
+	progressOut io.Writer
+
+	// stdin supplies the file list for --stdin; os.Stdin when nil
+	stdin      io.Reader
+	stdinFiles []string
+
+	// statsMu guards IndexStats while workers update it
+	statsMu sync.Mutex
+
+	// stats holds the statistics of the last run, for callers embedding
+	// an index run such as monitor --initial-index
+	stats *IndexStats
+
+	// processFn replaces processFile when set, e.g. in tests
+	processFn func(ctx context.Context, filePath string, stats *IndexStats) error
+}
+
+// IndexStats represents indexing statistics. It is also the document
+// written by --report; Duration is nanoseconds in JSON and a duration
+// string in YAML.
 type IndexStats struct {
-	TotalFiles    int
-	ProcessedFiles int
-	SkippedFiles   int
-	Errors         []error
-	Duration       time.Duration
-	StartTime      time.Time
-	EndTime        time.Time
-	FileTypes      map[string]int
+	TotalFiles     int            `json:"total_files" yaml:"total_files"`
+	ProcessedFiles int            `json:"processed_files" yaml:"processed_files"`
+	SkippedFiles   int            `json:"skipped_files" yaml:"skipped_files"`
+	FailedFiles    int            `json:"failed_files" yaml:"failed_files"`
+	SkippedBySize  int            `json:"skipped_by_size" yaml:"skipped_by_size"`
+	SkippedByAge   int            `json:"skipped_by_age" yaml:"skipped_by_age"`
+	Unchanged      int            `json:"unchanged" yaml:"unchanged"`
+	SkippedBinary  int            `json:"skipped_binary" yaml:"skipped_binary"`
+	Duplicates     int            `json:"duplicates" yaml:"duplicates"`
+	Resumed        int            `json:"resumed" yaml:"resumed"`
+	Errors         ErrorList      `json:"errors" yaml:"errors"`
+	Duration       time.Duration  `json:"duration" yaml:"duration"`
+	StartTime      time.Time      `json:"start_time" yaml:"start_time"`
+	EndTime        time.Time      `json:"end_time" yaml:"end_time"`
+	FileTypes      map[string]int `json:"file_types" yaml:"file_types"`
+	MimeTypes      map[string]int `json:"mime_types,omitempty" yaml:"mime_types,omitempty"`
+	HashAlgorithm  string         `json:"hash_algorithm,omitempty" yaml:"hash_algorithm,omitempty"`
+	Cancelled      bool           `json:"cancelled" yaml:"cancelled"`
+}
+
+// countSkipped totals the files deliberately left out of the run.
+// Failed files and files not reached before a cancellation are not
+// skipped.
+func (s *IndexStats) countSkipped() {
+	s.SkippedFiles = s.SkippedBySize + s.SkippedByAge + s.Unchanged + s.SkippedBinary
+}
+
+// successRate returns the percentage of attempted files that were
+// processed without error
+func (s *IndexStats) successRate() float64 {
+	attempted := s.ProcessedFiles + s.FailedFiles
+	if attempted == 0 {
+		return 0
+	}
+	return float64(s.ProcessedFiles) / float64(attempted) * 100
 }
 
 // NewIndexCommand creates a new index command
@@ -55,37 +163,88 @@ to the Stroidex search index.
 Examples:
   stroidex index ./docs                    # Index docs directory
   stroidex index ./src ./docs -r           # Index recursively
+  stroidex index . --max-depth 1           # Index the path and its direct subdirectories
+  stroidex index . --follow-symlinks       # Descend into symlinked directories
   stroidex index . --dry-run               # Show what would be indexed
   stroidex index . --force                 # Force reindex all files
+  stroidex index . --type incremental      # Only reindex changed files
   stroidex index . --pattern "*.md,*.txt"  # Index specific file patterns
   stroidex index . --exclude "*.tmp,*.log" # Exclude specific patterns
+  stroidex index . --exclude-from skip.txt # Read exclude patterns from a file
+  stroidex index . --exclude-dir vendor    # Skip whole directory trees
+  stroidex index . --pattern "src/**/*.go" # Match paths below an indexed root
   stroidex index . --workers 8              # Use 8 concurrent workers
-  stroidex index . --batch-size 200         # Process in batches of 200`,
+  stroidex index . --batch-size 200         # Process in batches of 200
+  stroidex index . --no-ignore             # Include files matched by .gitignore
+  stroidex index . --max-size 10MB         # Skip files larger than 10 MB
+  stroidex index . --modified-since 24h    # Only files changed in the last day
+  stroidex index . --print-config          # Print the resolved configuration
+  stroidex index . --manifest run.jsonl    # Record processed files to a manifest
+  stroidex index . --resume                # Continue a run interrupted with Ctrl+C
+  stroidex index . --detect-mime           # Count files per sniffed MIME type
+  git diff --name-only | stroidex index -  # Index the files listed on stdin
+  stroidex index . --progress json         # Progress as JSON lines on stderr
+  stroidex index . --progress bytes        # Overall progress in bytes read, for large files
+  stroidex index . --summary-only          # No progress, just the summary (for CI)
+  stroidex index . -t incremental --hash   # Detect changes by content hash
+  stroidex index . --watch                 # Keep reindexing files as they change
+  stroidex index . --report report.json    # Write a report of the run (-o yaml for YAML)`,
 		Args: cobra.ArbitraryArgs,
 		RunE: ic.runIndex,
 	}
 
 	// Add index-specific flags
 	cmd.Flags().BoolVarP(&ic.recursive, "recursive", "r", true, "Index directories recursively")
+	cmd.Flags().IntVar(&ic.maxDepth, "max-depth", -1, "Maximum directory depth below each path to descend into (0 = files directly in the path, negative = unlimited)")
+	cmd.Flags().BoolVar(&ic.followLinks, "follow-symlinks", false, "Follow symbolic links to files and directories, skipping symlink loops")
 	cmd.Flags().BoolVar(&ic.dryRun, "dry-run", false, "Show what would be indexed without processing")
 	cmd.Flags().BoolVar(&ic.force, "force", false, "Force reindex all files (ignore existing index)")
 	cmd.Flags().StringSliceVarP(&ic.patterns, "pattern", "p", []string{"*"}, "File patterns to index (comma-separated)")
 	cmd.Flags().StringSliceVarP(&ic.excludePaths, "exclude", "e", []string{}, "Exclude patterns (comma-separated)")
+	cmd.Flags().StringVar(&ic.includeFrom, "include-from", "", "Read further file patterns to index from this file, one per line (# starts a comment)")
+	cmd.Flags().StringVar(&ic.excludeFrom, "exclude-from", "", "Read further exclude patterns from this file, one per line (# starts a comment)")
+	cmd.Flags().StringSliceVar(&ic.excludeDirs, "exclude-dir", []string{}, "Skip directories whose name or path matches these patterns, without descending into them (comma-separated)")
 	cmd.Flags().IntVar(&ic.maxWorkers, "workers", 4, "Number of concurrent workers")
 	cmd.Flags().IntVar(&ic.batchSize, "batch-size", 100, "Batch size for processing")
 	cmd.Flags().StringVarP(&ic.indexType, "type", "t", "full", "Index type (full, incremental, partial)")
+	cmd.Flags().BoolVar(&ic.fromStdin, "stdin", false, "Read newline-separated file paths to index from stdin (same as passing -)")
+	cmd.Flags().BoolVar(&ic.includeBinary, "include-binary", false, "Index files that look binary instead of skipping them")
+	cmd.Flags().BoolVar(&ic.detectMime, "detect-mime", false, "Sniff each file's MIME type and report counts per type")
+	cmd.Flags().BoolVar(&ic.ignoreCase, "ignore-case", false, "Match --pattern, --exclude and --exclude-dir case-insensitively")
+	cmd.Flags().BoolVar(&ic.noIgnore, "no-ignore", false, "Do not apply .gitignore and .stroidexignore rules")
+	cmd.Flags().StringVar(&ic.maxSize, "max-size", "", "Skip files larger than this size (e.g. 10MB, 512KiB)")
+	cmd.Flags().StringVar(&ic.minSize, "min-size", "", "Skip files smaller than this size (e.g. 1KB)")
+	cmd.Flags().StringVar(&ic.modifiedSince, "modified-since", "", "Only index files modified since a duration ago (e.g. 24h) or an RFC3339 time")
+	cmd.Flags().BoolVar(&ic.printConfig, "print-config", false, "Print the resolved configuration and exit")
+	cmd.Flags().StringVar(&ic.reportPath, "report", "", "Write a JSON or YAML (with --output yaml) report of the run to this path")
+	cmd.Flags().IntVar(&ic.checkpointInterval, "checkpoint-interval", 1000, "Save a resumable checkpoint every N processed files (0 disables checkpoints)")
+	cmd.Flags().BoolVar(&ic.resume, "resume", false, "Resume an interrupted run, skipping files its checkpoint records as processed")
+	cmd.Flags().StringVar(&ic.manifestPath, "manifest", "", "Write a JSONL manifest of processed files to this path")
+	cmd.Flags().StringVar(&ic.auditLogPath, "audit-log", "", "Append a JSON line per processed file, with its size, hash and outcome, to this file")
+	cmd.Flags().StringVar(&ic.progressMode, "progress", "bar", "How to show progress: bar, bytes for a bar of the bytes read, or json for one JSON event per line on stderr")
+	cmd.Flags().BoolVar(&ic.summaryOnly, "summary-only", false, "Show no progress, only the summary at the end (unlike --quiet)")
+	cmd.Flags().StringVar(&ic.hashAlgorithm, "hash", "", "Detect changed files by content hash with incremental runs: xxhash, sha256 or crc32 (--hash=ALGORITHM; a bare --hash uses xxhash)")
+	cmd.Flags().Lookup("hash").NoOptDefVal = defaultHashAlgorithm
+	cmd.Flags().BoolVar(&ic.watch, "watch", false, "After indexing, keep running and reindex files as they change, with the same filters")
 
 	return cmd
 }
 
 // runIndex executes the index command
 func (ic *IndexCommand) runIndex(cmd *cobra.Command, args []string) error {
-	// Parse paths
-	if len(args) == 0 {
+	// Parse paths; "-" stands for the file list on stdin
+	ic.paths = nil
+	for _, arg := range args {
+		if arg == "-" {
+			ic.fromStdin = true
+			continue
+		}
+		ic.paths = append(ic.paths, arg)
+	}
+	if len(ic.paths) == 0 && !ic.fromStdin {
 		ic.paths = []string{"."}
-	} else {
-		ic.paths = args
 	}
+	ic.paths = ic.config.resolvePaths(ic.paths)
 
 	// Validate paths
 	for _, path := range ic.paths {
@@ -94,33 +253,273 @@ func (ic *IndexCommand) runIndex(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if err := ic.loadPatternFiles(); err != nil {
+		return err
+	}
+
 	// Validate configuration
 	if err := ic.validateConfig(); err != nil {
 		return fmt.Errorf("configuration validation failed: %w", err)
 	}
 
+	if ic.fromStdin {
+		files, err := readFileList(ic.stdinReader())
+		if err != nil {
+			return fmt.Errorf("failed to read file list from stdin: %w", err)
+		}
+		ic.stdinFiles = ic.config.resolvePaths(files)
+	}
+
+	if ic.printConfig {
+		data, err := json.MarshalIndent(ic.resolvedConfig(), "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal configuration: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
 	// Setup context for cancellation
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ic.config.Context())
 	defer cancel()
 
+	// Cancel on SIGINT or SIGTERM so workers stop promptly and the partial
+	// statistics are still reported
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	defer tempFiles.HandleShutdown()()
+	go func() {
+		select {
+		case <-sigChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
 	// Initialize statistics
 	stats := &IndexStats{
-		StartTime:  time.Now(),
-		FileTypes:  make(map[string]int),
-		Errors:     make([]error, 0),
+		StartTime: time.Now(),
+		FileTypes: make(map[string]int),
+		Errors:    make([]error, 0),
+	}
+	if ic.detectMime {
+		stats.MimeTypes = make(map[string]int)
 	}
+	ic.stats = stats
 
-	PrintInfo(fmt.Sprintf("Starting indexing on %d path(s)", len(ic.paths)))
-	for _, path := range ic.paths {
-		absPath, _ := filepath.Abs(path)
-		PrintInfo(fmt.Sprintf("Indexing: %s (recursive: %v)", absPath, ic.recursive))
+	if !isStructuredFormat(ic.config.OutputFormat) {
+		PrintInfo(fmt.Sprintf("Starting indexing on %d path(s)", len(ic.paths)))
+		for _, path := range ic.paths {
+			absPath, _ := filepath.Abs(path)
+			PrintInfo(fmt.Sprintf("Indexing: %s (recursive: %v)", absPath, ic.recursive))
+		}
+		if ic.fromStdin {
+			PrintInfo(fmt.Sprintf("Indexing %d path(s) read from stdin", len(ic.stdinFiles)))
+		}
+	}
+
+	if err := ic.loadIndexManifest(); err != nil {
+		return err
+	}
+	if ic.incremental() {
+		stats.HashAlgorithm = ic.hashAlgorithm
+	}
+
+	if err := ic.loadCheckpoint(); err != nil {
+		return err
 	}
 
 	if ic.dryRun {
 		return ic.runDryRun(ctx, stats)
 	}
 
-	return ic.runFullIndex(ctx, stats)
+	// Watch before the initial run so changes made meanwhile are queued
+	var watcher *MonitorCommand
+	if ic.watch {
+		mc, err := ic.startWatch(ctx)
+		if err != nil {
+			return err
+		}
+		defer mc.watcher.Close()
+		watcher = mc
+	}
+
+	if ic.manifestPath != "" {
+		manifest, err := NewRunManifest(ic.manifestPath, ic.resolvedConfig())
+		if err != nil {
+			return fmt.Errorf("failed to create manifest: %w", err)
+		}
+		ic.manifest = manifest
+	}
+
+	if ic.auditLogPath != "" {
+		auditLog, err := OpenAuditLog(ic.auditLogPath)
+		if err != nil {
+			return err
+		}
+		ic.auditLog = auditLog
+	}
+
+	err := ic.runFullIndex(ctx, stats)
+
+	if ic.manifest != nil {
+		if closeErr := ic.manifest.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+
+	if cpErr := ic.finishCheckpoint(err); cpErr != nil && err == nil {
+		err = cpErr
+	}
+
+	// Persist what was indexed, even after a failure, so the next
+	// incremental run does not redo it. A cancelled run may not have seen
+	// every file, so nothing is pruned.
+	if ctx.Err() == nil {
+		ic.index.Prune(ic.paths)
+	}
+	if saveErr := ic.index.Save(); saveErr != nil && err == nil {
+		err = saveErr
+	}
+
+	if ic.reportPath != "" {
+		if reportErr := writeIndexReport(ic.reportPath, ic.config.OutputFormat, stats); reportErr != nil && err == nil {
+			err = reportErr
+		}
+	}
+
+	if err == nil && watcher != nil {
+		err = ic.watchChanges(ctx, watcher)
+	}
+
+	if ic.auditLog != nil {
+		if closeErr := ic.auditLog.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		ic.auditLog = nil
+	}
+
+	return err
+}
+
+// loadIndexManifest loads the persistent index manifest. With --force
+// the existing manifest is ignored and rebuilt from scratch.
+func (ic *IndexCommand) loadIndexManifest() error {
+	path := ic.indexManifestPath
+	if path == "" {
+		path = ic.config.resolvePath(defaultIndexManifestPath())
+	}
+
+	if ic.force {
+		ic.index = NewIndexManifest(path)
+	} else {
+		index, err := LoadIndexManifest(path)
+		if err != nil {
+			return fmt.Errorf("%w (use --force to rebuild it)", err)
+		}
+		ic.index = index
+	}
+
+	if ic.hashAlgorithm != "" {
+		ic.index.SetHashAlgorithm(ic.hashAlgorithm)
+	}
+	return nil
+}
+
+// loadCheckpoint loads the checkpoint of an interrupted run with
+// --resume, or starts a new one when checkpoints are enabled
+func (ic *IndexCommand) loadCheckpoint() error {
+	path := ic.checkpointPath
+	if path == "" {
+		path = ic.config.resolvePath(defaultCheckpointPath())
+	}
+
+	if ic.resume {
+		checkpoint, err := LoadCheckpoint(path, ic.paths)
+		if err != nil {
+			return err
+		}
+		ic.checkpoint = checkpoint
+		return nil
+	}
+
+	if ic.checkpointInterval > 0 && !ic.dryRun {
+		ic.checkpoint = NewCheckpoint(path, ic.paths)
+	}
+	return nil
+}
+
+// finishCheckpoint saves the checkpoint of a run that did not complete so
+// it can be resumed, and removes it once a run has completed
+func (ic *IndexCommand) finishCheckpoint(runErr error) error {
+	if ic.checkpoint == nil {
+		return nil
+	}
+
+	if runErr == nil {
+		return ic.checkpoint.Remove()
+	}
+
+	if err := ic.checkpoint.Save(); err != nil {
+		return err
+	}
+	if !isStructuredFormat(ic.config.OutputFormat) {
+		PrintInfo(fmt.Sprintf("Saved a checkpoint of %d processed files; rerun with --resume to continue", ic.checkpoint.Len()))
+	}
+	return nil
+}
+
+// incremental reports whether unchanged files should be skipped
+func (ic *IndexCommand) incremental() bool {
+	return ic.indexType == "incremental" && !ic.force && ic.index != nil
+}
+
+// unchanged reports whether a file is unchanged since the last run, by
+// content hash with --hash and by size and modification time otherwise
+func (ic *IndexCommand) unchanged(filePath string, info os.FileInfo) bool {
+	if ic.hashAlgorithm != "" {
+		return ic.index.ContentUnchanged(filePath, info)
+	}
+	return ic.index.Unchanged(filePath, info)
+}
+
+// resolvedConfig returns the effective settings for this run
+func (ic *IndexCommand) resolvedConfig() IndexRunConfig {
+	return IndexRunConfig{
+		Version:            buildInfo().Version,
+		Paths:              ic.paths,
+		Recursive:          ic.recursive,
+		MaxDepth:           ic.maxDepth,
+		FollowLinks:        ic.followLinks,
+		DryRun:             ic.dryRun,
+		Force:              ic.force,
+		Resume:             ic.resume,
+		Watch:              ic.watch,
+		Patterns:           ic.patterns,
+		Exclude:            ic.excludePaths,
+		ExcludeDirs:        ic.excludeDirs,
+		IncludeFrom:        ic.includeFrom,
+		ExcludeFrom:        ic.excludeFrom,
+		Hash:               ic.hashAlgorithm,
+		NoIgnore:           ic.noIgnore,
+		IgnoreCase:         ic.ignoreCase,
+		Stdin:              ic.fromStdin,
+		IncludeBinary:      ic.includeBinary,
+		DetectMime:         ic.detectMime,
+		MaxSize:            ic.maxBytes,
+		MinSize:            ic.minBytes,
+		ModifiedSince:      formatCutoff(ic.modifiedCutoff),
+		Workers:            ic.maxWorkers,
+		BatchSize:          ic.batchSize,
+		CheckpointInterval: ic.checkpointInterval,
+		AuditLog:           ic.auditLogPath,
+		IndexType:          ic.indexType,
+		OutputFormat:       ic.config.OutputFormat,
+		Progress:           ic.progressMode,
+		SummaryOnly:        ic.summaryOnly,
+		Verbose:            ic.config.Verbose,
+	}
 }
 
 // validateConfig validates the index command configuration
@@ -143,39 +542,169 @@ func (ic *IndexCommand) validateConfig() error {
 	}
 
 	if !validTypes[ic.indexType] {
-		return fmt.Errorf("invalid index type: %s (valid: full, incremental, partial)", ic.indexType)
+		return fmt.Errorf("index type must be one of full, incremental, partial, got: %s", ic.indexType)
+	}
+
+	// Validate progress mode; empty draws bars
+	if ic.progressMode != "" && ic.progressMode != "bar" && ic.progressMode != "bytes" && ic.progressMode != "json" {
+		return fmt.Errorf("invalid --progress: %s (valid: bar, bytes, json)", ic.progressMode)
+	}
+	if ic.hashAlgorithm != "" {
+		if err := validateHashAlgorithm(ic.hashAlgorithm); err != nil {
+			return err
+		}
+	}
+
+	// Index results are not metrics; reject the format before any work
+	// rather than failing once the results are rendered
+	if ic.config != nil && ic.config.OutputFormat == "prometheus" {
+		return fmt.Errorf("prometheus output is not supported for index")
+	}
+
+	if ic.summaryOnly && ic.progressMode == "json" {
+		return fmt.Errorf("--summary-only cannot be combined with --progress=json")
+	}
+	if ic.watch && (ic.dryRun || ic.fromStdin) {
+		return fmt.Errorf("--watch cannot be combined with --dry-run or --stdin")
+	}
+
+	// Validate size limits
+	ic.maxBytes, ic.minBytes = 0, 0
+	if ic.maxSize != "" {
+		size, err := parseBytes(ic.maxSize)
+		if err != nil {
+			return fmt.Errorf("invalid --max-size: %w", err)
+		}
+		ic.maxBytes = size
+	}
+	if ic.minSize != "" {
+		size, err := parseBytes(ic.minSize)
+		if err != nil {
+			return fmt.Errorf("invalid --min-size: %w", err)
+		}
+		ic.minBytes = size
+	}
+	if ic.maxBytes > 0 && ic.minBytes > ic.maxBytes {
+		return fmt.Errorf("--min-size (%s) is larger than --max-size (%s)", ic.minSize, ic.maxSize)
+	}
+
+	// Validate modification cutoff
+	ic.modifiedCutoff = time.Time{}
+	if ic.modifiedSince != "" {
+		cutoff, err := parseModifiedSince(ic.modifiedSince, time.Now())
+		if err != nil {
+			return fmt.Errorf("invalid --modified-since: %w", err)
+		}
+		ic.modifiedCutoff = cutoff
 	}
 
 	return nil
 }
 
+// dryRunFileLimit caps the file list of a structured dry-run plan
+const dryRunFileLimit = 10000
+
+// DryRunPlan is the structured output of a dry run
+type DryRunPlan struct {
+	TotalFiles int            `json:"total_files" yaml:"total_files"`
+	FileTypes  map[string]int `json:"file_types" yaml:"file_types"`
+	Files      []string       `json:"files" yaml:"files"`
+	Truncated  bool           `json:"truncated" yaml:"truncated"`
+}
+
+// DryRunFile is a row of the csv dry-run file list
+type DryRunFile struct {
+	Path string `json:"path" yaml:"path"`
+	Type string `json:"type" yaml:"type"`
+}
+
+// FileTypeCount is a row of the csv per-type index statistics
+type FileTypeCount struct {
+	Type  string `json:"type" yaml:"type"`
+	Files int    `json:"files" yaml:"files"`
+}
+
+// sortedFileTypes orders per-type file counts by number of files, most
+// first, then by type, so listings do not follow map order
+func sortedFileTypes(counts map[string]int) []FileTypeCount {
+	sorted := make([]FileTypeCount, 0, len(counts))
+	for fileType, files := range counts {
+		sorted = append(sorted, FileTypeCount{Type: fileType, Files: files})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Files != sorted[j].Files {
+			return sorted[i].Files > sorted[j].Files
+		}
+		return sorted[i].Type < sorted[j].Type
+	})
+	return sorted
+}
+
+// fileType returns the lower-cased extension used to group files
+func fileType(file string) string {
+	ext := strings.ToLower(filepath.Ext(file))
+	if ext == "" {
+		return "no_extension"
+	}
+	return ext
+}
+
 // runDryRun performs a dry run of indexing
 func (ic *IndexCommand) runDryRun(ctx context.Context, stats *IndexStats) error {
-	PrintInfo("Running in dry-run mode (no processing)")
+	renderer := NewRenderer(ic.config.OutputFormat, ic.config.Stdout())
+	if renderer == nil {
+		PrintInfo("Running in dry-run mode (no processing)")
+	}
 
-	files, err := ic.collectFiles(ctx)
+	files, err := ic.collectFiles(ctx, stats)
 	if err != nil {
 		return fmt.Errorf("failed to collect files: %w", err)
 	}
 
 	stats.TotalFiles = len(files)
 
-	PrintInfo(fmt.Sprintf("Found %d files to index", len(files)))
-
 	// Group files by type
 	fileTypes := make(map[string]int)
 	for _, file := range files {
-		ext := strings.ToLower(filepath.Ext(file))
-		if ext == "" {
-			ext = "no_extension"
+		fileTypes[fileType(file)]++
+	}
+
+	// A CSV holds a single table: the file list
+	if ic.config.OutputFormat == "csv" {
+		for _, file := range files {
+			if err := renderer.Record("file", DryRunFile{Path: file, Type: fileType(file)}); err != nil {
+				return err
+			}
 		}
-		fileTypes[ext]++
+		return renderer.Flush()
 	}
 
+	if renderer != nil {
+		plan := DryRunPlan{
+			TotalFiles: len(files),
+			FileTypes:  fileTypes,
+			Files:      files,
+		}
+		if plan.Files == nil {
+			plan.Files = []string{}
+		}
+		if len(plan.Files) > dryRunFileLimit {
+			plan.Files = plan.Files[:dryRunFileLimit]
+			plan.Truncated = true
+		}
+
+		if err := renderer.Render("dry_run", plan); err != nil {
+			return err
+		}
+		return renderer.Flush()
+	}
+
+	PrintInfo(fmt.Sprintf("Found %s files to index", humanizeInt(int64(len(files)))))
+
 	// Display file type statistics
 	PrintInfo("=== File Types ===")
-	for ext, count := range fileTypes {
-		PrintInfo(fmt.Sprintf("  %s: %d files", ext, count))
+	for _, count := range sortedFileTypes(fileTypes) {
+		PrintInfo(fmt.Sprintf("  %s: %s files", count.Type, humanizeInt(int64(count.Files))))
 	}
 
 	// Show sample files
@@ -198,28 +727,74 @@ func (ic *IndexCommand) runDryRun(ctx context.Context, stats *IndexStats) error
 
 // runFullIndex performs full indexing
 func (ic *IndexCommand) runFullIndex(ctx context.Context, stats *IndexStats) error {
-	PrintInfo(fmt.Sprintf("Running full indexing with %d workers", ic.maxWorkers))
+	structured := isStructuredFormat(ic.config.OutputFormat)
+	if !structured {
+		PrintInfo(fmt.Sprintf("Running full indexing with %d workers", ic.maxWorkers))
+	}
 
-	files, err := ic.collectFiles(ctx)
+	collected, err := ic.collectTaggedFiles(ctx, stats)
 	if err != nil {
+		if ctx.Err() != nil {
+			PrintWarning("Indexing cancelled while collecting files")
+			stats.Cancelled = true
+			return ctx.Err()
+		}
 		return fmt.Errorf("failed to collect files: %w", err)
 	}
 
+	files := make([]string, len(collected))
+	for i, file := range collected {
+		files[i] = file.path
+	}
 	stats.TotalFiles = len(files)
 
 	if len(files) == 0 {
+		stats.countSkipped()
+
+		// A structured result is written even when there is nothing to do
+		if structured {
+			stats.EndTime = time.Now()
+			stats.Duration = stats.EndTime.Sub(stats.StartTime)
+			return ic.displayStats(stats)
+		}
+
+		if stats.Unchanged > 0 {
+			PrintSuccess(fmt.Sprintf("All %s files are unchanged since the last run", humanizeInt(int64(stats.Unchanged))))
+			return nil
+		}
+		if stats.Resumed > 0 {
+			PrintSuccess(fmt.Sprintf("All %s files were processed before the interruption", humanizeInt(int64(stats.Resumed))))
+			return nil
+		}
 		PrintWarning("No files found to index")
 		return nil
 	}
 
-	PrintInfo(fmt.Sprintf("Starting to index %d files...", len(files)))
+	if !structured {
+		PrintInfo(fmt.Sprintf("Starting to index %s files...", humanizeInt(int64(len(files)))))
+	}
 
-	// Create overall progress bar
-	totalPB := NewProgressBar("Indexing files", int64(len(files)))
-	totalPB.Start()
-	defer totalPB.Finish()
+	// Create overall progress bar, counting bytes read with
+	// --progress=bytes; per-root bars, when indexing several roots, and
+	// batch bars are stacked below it
+	ic.progress = ic.newProgressGroup()
+	if ic.progressMode == "bytes" {
+		ic.totalBar = NewBytesProgress("Indexing files", 0)
+		ic.progress.AddBar(ic.totalBar)
+		ic.bytes = newByteProgress(ic.totalBar, collected)
+	} else {
+		ic.totalBar = ic.progress.NewBar("Indexing files", int64(len(files)))
+	}
+	ic.addRootBars(collected)
+	defer func() { ic.progress, ic.totalBar, ic.bytes, ic.rootBars, ic.fileRoots = nil, nil, nil, nil, nil }()
+
+	ic.totalBar.Start()
+	for _, bar := range ic.rootBars {
+		bar.Start()
+	}
 
 	// Process files in batches
+	batches := (len(files) + ic.batchSize - 1) / ic.batchSize
 	processedFiles := 0
 	for i := 0; i < len(files); i += ic.batchSize {
 		end := i + ic.batchSize
@@ -229,211 +804,828 @@ func (ic *IndexCommand) runFullIndex(ctx context.Context, stats *IndexStats) err
 
 		batch := files[i:end]
 
-		batchProcessed, batchErrors := ic.processBatch(ctx, batch, stats)
+		batchProcessed, batchErrors := ic.processBatch(ctx, batch, i/ic.batchSize+1, batches, stats)
 		processedFiles += batchProcessed
 		stats.Errors = append(stats.Errors, batchErrors...)
 
-		// Update overall progress
-		totalPB.UpdateTo(int64(end))
+		// Update overall progress; the bytes bar follows the reads
+		if ic.bytes == nil {
+			ic.totalBar.UpdateTo(int64(end))
+		}
 
 		// Check for context cancellation
-		select {
-		case <-ctx.Done():
-			PrintInfo("Indexing cancelled")
-			return ctx.Err()
-		default:
+		if ctx.Err() != nil {
+			break
 		}
 	}
 
 	stats.ProcessedFiles = processedFiles
-	stats.SkippedFiles = stats.TotalFiles - processedFiles
+	stats.FailedFiles = len(stats.Errors)
+	stats.countSkipped()
 	stats.EndTime = time.Now()
 	stats.Duration = stats.EndTime.Sub(stats.StartTime)
+	stats.Cancelled = ctx.Err() != nil
 
-	// Clear progress line and display final statistics
-	ClearLine()
-	ic.displayStats(stats)
+	// Display final statistics, partial if cancelled; this also finishes
+	// the progress bars
+	if err := ic.displayStats(stats); err != nil {
+		return err
+	}
 
-	return nil
+	return ctx.Err()
 }
 
-// collectFiles collects all files to be indexed
-func (ic *IndexCommand) collectFiles(ctx context.Context) ([]string, error) {
-	var files []string
+// stdinRoot is the root of files read by --stdin
+const stdinRoot = "stdin"
 
-	for _, path := range ic.paths {
-		err := filepath.Walk(path, func(walkPath string, info os.FileInfo, err error) error {
-			if err != nil {
-				if ic.config.Verbose {
-					PrintWarning(fmt.Sprintf("Error accessing %s: %v", walkPath, err))
-				}
-				return nil // Skip errors
-			}
+// collectedFile is a file to index, its size and the root it was found
+// below: one of the indexed paths, or stdinRoot
+type collectedFile struct {
+	path string
+	root string
+	size int64
+}
 
-			// Skip directories unless we're at the root
-			if info.IsDir() {
-				if !ic.recursive && walkPath != path {
-					return filepath.SkipDir
-				}
-				return nil
+// collectFiles collects all files to be indexed, counting files it skips
+// in stats
+func (ic *IndexCommand) collectFiles(ctx context.Context, stats *IndexStats) ([]string, error) {
+	collected, err := ic.collectTaggedFiles(ctx, stats)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, len(collected))
+	for i, file := range collected {
+		files[i] = file.path
+	}
+	return files, nil
+}
+
+// collectTaggedFiles collects all files to be indexed with the root each
+// came from, counting files it skips in stats. Several roots are walked
+// concurrently, up to maxWorkers at a time; their files are then filtered
+// in the order the roots were given, so the result does not depend on
+// which walk finished first.
+func (ic *IndexCommand) collectTaggedFiles(ctx context.Context, stats *IndexStats) ([]collectedFile, error) {
+	walked := make([][]walkedFile, len(ic.paths))
+	errs := make([]error, len(ic.paths))
+
+	workers := ic.maxWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+
+	var wg sync.WaitGroup
+	for i, path := range ic.paths {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, path string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			walked[i], errs[i] = ic.walkRoot(ctx, path)
+		}(i, path)
+	}
+	wg.Wait()
+
+	var files []collectedFile
+
+	// Overlapping paths, e.g. ". ./docs", reach the same file more than
+	// once; seen holds the absolute paths collected so far
+	seen := make(map[string]bool)
+
+	for i, path := range ic.paths {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("error walking path %s: %w", path, errs[i])
+		}
+		for _, file := range walked[i] {
+			if isDuplicate(seen, file.path, stats) {
+				continue
+			}
+			if ic.acceptFile(file.path, file.rel, file.info, stats) {
+				files = append(files, collectedFile{path: file.path, root: path, size: file.info.Size()})
 			}
+		}
+	}
+
+	if ic.fromStdin {
+		files = append(files, ic.collectStdinFiles(seen, stats)...)
+	}
+
+	return files, nil
+}
+
+// walkedFile is a file found below a root that is not ignored, before
+// the filters of acceptFile
+type walkedFile struct {
+	path string
+	rel  string
+	info os.FileInfo
+}
 
-			// Check if file matches patterns
-			if !ic.matchesPattern(walkPath) {
-				return nil
+// walkRoot walks an indexed root, pruning directories and files that the
+// depth limit, --exclude-dir or the ignore files rule out. It is safe to
+// run for several roots at once.
+func (ic *IndexCommand) walkRoot(ctx context.Context, root string) ([]walkedFile, error) {
+	var files []walkedFile
+	ignore := ic.newIgnoreMatcher(root)
+	matcher := ic.pathMatcher(ignore)
+
+	err := ic.walk(root, func(walkPath string, info os.FileInfo, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			if ic.config.Verbose {
+				PrintWarning(fmt.Sprintf("Error accessing %s: %v", walkPath, err))
 			}
+			return nil // Skip errors
+		}
 
-			// Check if file should be excluded
-			if ic.shouldExclude(walkPath) {
-				if ic.config.Verbose {
-					PrintInfo(fmt.Sprintf("Excluding: %s", walkPath))
+		rel := relativePath(root, walkPath)
+
+		// Skip directories unless we're at the root
+		if info.IsDir() {
+			if walkPath != root && (!ic.recursive || !withinMaxDepth(rel, ic.maxDepth)) {
+				return filepath.SkipDir
+			}
+			// Never index stroidex's own state
+			if walkPath != root && info.Name() == stateDirName {
+				return filepath.SkipDir
+			}
+			if walkPath != root && matcher.ExcludesDir(rel) {
+				logger.Debug("excluding directory", "path", walkPath)
+				return filepath.SkipDir
+			}
+			if ignore != nil && walkPath != root {
+				if matcher.Ignored(rel, true) {
+					logger.Debug("ignoring", "path", walkPath)
+					return filepath.SkipDir
 				}
-				return nil
+				ic.addIgnoreFile(ignore, filepath.Join(walkPath, gitIgnoreFile), rel)
 			}
-
-			files = append(files, walkPath)
 			return nil
-		})
+		}
 
-		if err != nil {
-			return nil, fmt.Errorf("error walking path %s: %w", path, err)
+		if matcher.Ignored(rel, false) {
+			logger.Debug("ignoring", "path", walkPath)
+			return nil
 		}
-	}
 
-	return files, nil
+		files = append(files, walkedFile{path: walkPath, rel: rel, info: info})
+		return nil
+	})
+	return files, err
 }
 
-// matchesPattern checks if file matches inclusion patterns
-func (ic *IndexCommand) matchesPattern(filePath string) bool {
-	if len(ic.patterns) == 1 && ic.patterns[0] == "*" {
-		return true
+// acceptFile applies the pattern, exclude, age, size and incremental
+// filters to a collected file, counting files it skips in stats
+func (ic *IndexCommand) acceptFile(filePath, rel string, info os.FileInfo, stats *IndexStats) bool {
+	matcher := ic.pathMatcher(nil)
+
+	// The file is still present, whether or not this run indexes it
+	if ic.index != nil {
+		ic.index.Seen(filePath)
+	}
+
+	// Check if file matches patterns
+	if !matcher.Included(rel) {
+		return false
+	}
+
+	// Check if file should be excluded
+	if matcher.Excluded(rel) {
+		logger.Debug("excluding", "path", filePath)
+		return false
+	}
+
+	// Check modification time
+	if !ic.modifiedCutoff.IsZero() && info.ModTime().Before(ic.modifiedCutoff) {
+		stats.SkippedByAge++
+		return false
 	}
 
-	fileName := filepath.Base(filePath)
-	for _, pattern := range ic.patterns {
-		matched, err := filepath.Match(pattern, fileName)
+	// Check size limits
+	if !ic.withinSizeLimits(info.Size()) {
+		stats.SkippedBySize++
+		logger.Debug("skipping by size", "path", filePath, "size", formatBytes(info.Size()))
+		return false
+	}
+
+	// Skip files unchanged since the last run
+	if ic.index != nil && ic.incremental() && ic.unchanged(filePath, info) {
+		stats.Unchanged++
+		return false
+	}
+
+	// Skip files an interrupted run already processed
+	if ic.resume && ic.checkpoint != nil && ic.checkpoint.Done(filePath) {
+		stats.Resumed++
+		return false
+	}
+
+	return true
+}
+
+// collectStdinFiles filters the paths read by --stdin. They bypass the
+// directory walk and ignore files, but not the other filters.
+func (ic *IndexCommand) collectStdinFiles(seen map[string]bool, stats *IndexStats) []collectedFile {
+	var files []collectedFile
+	for _, filePath := range ic.stdinFiles {
+		info, err := os.Stat(filePath)
 		if err != nil {
+			if ic.config.Verbose {
+				PrintWarning(fmt.Sprintf("Error accessing %s: %v", filePath, err))
+			}
+			continue
+		}
+		if info.IsDir() {
+			logger.Debug("skipping directory read from stdin", "path", filePath)
+			continue
+		}
+
+		if isDuplicate(seen, filePath, stats) {
 			continue
 		}
-		if matched {
-			return true
+
+		if ic.acceptFile(filePath, filepath.Clean(filePath), info, stats) {
+			files = append(files, collectedFile{path: filePath, root: stdinRoot, size: info.Size()})
 		}
 	}
+	return files
+}
 
+// isDuplicate reports whether filePath was already collected, counting
+// it in stats if so, and otherwise marks it as collected
+func isDuplicate(seen map[string]bool, filePath string, stats *IndexStats) bool {
+	key := manifestKey(filePath)
+	if seen[key] {
+		stats.Duplicates++
+		return true
+	}
+	seen[key] = true
 	return false
 }
 
-// shouldExclude checks if file should be excluded
-func (ic *IndexCommand) shouldExclude(filePath string) bool {
-	fileName := filepath.Base(filePath)
-	for _, pattern := range ic.excludePaths {
-		matched, err := filepath.Match(pattern, fileName)
-		if err != nil {
+// readFileList reads newline-separated paths, skipping blank lines
+func readFileList(r io.Reader) ([]string, error) {
+	var files []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
 			continue
 		}
-		if matched {
-			return true
+		files = append(files, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// stdinReader returns the reader --stdin reads the file list from
+func (ic *IndexCommand) stdinReader() io.Reader {
+	if ic.stdin != nil {
+		return ic.stdin
+	}
+	return os.Stdin
+}
+
+// parseModifiedSince resolves a --modified-since value, either a
+// duration before now or an RFC3339 timestamp, to a cutoff time
+func parseModifiedSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("duration must not be negative: %s", value)
 		}
+		return now.Add(-d), nil
 	}
-	return false
+
+	cutoff, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected a duration (e.g. 24h) or an RFC3339 time, got %q", value)
+	}
+	return cutoff, nil
 }
 
-// processBatch processes a batch of files
-func (ic *IndexCommand) processBatch(ctx context.Context, files []string, stats *IndexStats) (int, []error) {
-	processed := 0
-	var errors []error
+// formatCutoff formats a modification cutoff for the run config, or ""
+// when there is none
+func formatCutoff(cutoff time.Time) string {
+	if cutoff.IsZero() {
+		return ""
+	}
+	return cutoff.Format(time.RFC3339)
+}
 
-	// Create progress bar for this batch
-	batchNum := (len(files) + ic.batchSize - 1) / ic.batchSize
-	pb := NewProgressBar(fmt.Sprintf("Processing batch %d", batchNum), int64(len(files)))
-	pb.Start()
-	defer pb.Finish()
+// relativePath returns walkPath relative to root. A root that is itself a
+// file is reported by its name.
+func relativePath(root, walkPath string) string {
+	rel, err := filepath.Rel(root, walkPath)
+	if err != nil || rel == "." {
+		return filepath.Base(walkPath)
+	}
+	return rel
+}
 
+// walk walks a collection root, following symlinks with --follow-symlinks
+func (ic *IndexCommand) walk(root string, fn filepath.WalkFunc) error {
+	if !ic.followLinks {
+		return filepath.Walk(root, fn)
+	}
+
+	return walkFollowingSymlinks(root, fn, func(path string) {
+		PrintWarning(fmt.Sprintf("Symlink loop detected, not following: %s", path))
+	})
+}
+
+// withinMaxDepth reports whether the directory at relPath, relative to a
+// walk root, may be descended into under maxDepth. The root's own files
+// are at depth 0; a negative maxDepth means unlimited.
+func withinMaxDepth(relPath string, maxDepth int) bool {
+	if maxDepth < 0 {
+		return true
+	}
+	return strings.Count(filepath.ToSlash(relPath), "/")+1 <= maxDepth
+}
+
+// withinSizeLimits reports whether a file of the given size passes
+// --min-size and --max-size
+func (ic *IndexCommand) withinSizeLimits(size int64) bool {
+	if ic.maxBytes > 0 && size > ic.maxBytes {
+		return false
+	}
+	return size >= ic.minBytes
+}
+
+// newIgnoreMatcher loads the ignore rules of a collection root, or
+// returns nil when ignore files are disabled
+func (ic *IndexCommand) newIgnoreMatcher(root string) *IgnoreMatcher {
+	if ic.noIgnore {
+		return nil
+	}
+
+	ignore := NewIgnoreMatcher()
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return ignore
+	}
+
+	ic.addIgnoreFile(ignore, filepath.Join(root, gitIgnoreFile), "")
+	ic.addIgnoreFile(ignore, filepath.Join(root, stroidexIgnoreFile), "")
+	return ignore
+}
+
+// addIgnoreFile adds the rules of an ignore file, warning when it
+// exists but cannot be read
+func (ic *IndexCommand) addIgnoreFile(ignore *IgnoreMatcher, filePath, base string) {
+	if err := ignore.AddFile(filePath, base); err != nil && ic.config.Verbose {
+		PrintWarning(fmt.Sprintf("Failed to read %s: %v", filePath, err))
+	}
+}
+
+// loadPatternFiles merges the patterns of --include-from and
+// --exclude-from into the pattern lists
+func (ic *IndexCommand) loadPatternFiles() error {
+	patterns, exclude, err := mergePatternFiles(ic.config, ic.patterns, ic.excludePaths, ic.includeFrom, ic.excludeFrom)
+	if err != nil {
+		return err
+	}
+	ic.patterns, ic.excludePaths = patterns, exclude
+	return nil
+}
+
+// pathMatcher returns the matcher of --pattern, --exclude, --exclude-dir
+// and --ignore-case, applying the gitignore rules of ignore, if any
+func (ic *IndexCommand) pathMatcher(ignore *IgnoreMatcher) *PathMatcher {
+	return &PathMatcher{
+		Include:     ic.patterns,
+		Exclude:     ic.excludePaths,
+		ExcludeDirs: ic.excludeDirs,
+		IgnoreCase:  ic.ignoreCase,
+		Ignore:      ignore,
+	}
+}
+
+// matchesPattern checks if file matches inclusion patterns. relPath is
+// the file's path relative to its indexed root.
+func (ic *IndexCommand) matchesPattern(relPath string) bool {
+	return ic.pathMatcher(nil).Included(relPath)
+}
+
+// shouldExclude checks if file should be excluded. relPath is the file's
+// path relative to its indexed root.
+func (ic *IndexCommand) shouldExclude(relPath string) bool {
+	return ic.pathMatcher(nil).Excluded(relPath)
+}
+
+// newProgressGroup creates the group the progress bars of a run are
+// shown in, as selected by --progress and --summary-only
+func (ic *IndexCommand) newProgressGroup() *ProgressGroup {
+	pg := NewProgressGroup().WithWriter(ic.progressWriter())
+	switch {
+	case ic.summaryOnly:
+		pg.WithMode(ProgressModeOff)
+	case ic.progressMode == "json":
+		pg.WithMode(ProgressModeJSON)
+	}
+	return pg
+}
+
+// applyProgressMode switches a bar outside the group to JSON events with
+// --progress=json, or silences it with --summary-only, and returns it
+func (ic *IndexCommand) applyProgressMode(pb *ProgressBar) *ProgressBar {
+	pb.WithWriter(ic.progressWriter())
+	switch {
+	case ic.summaryOnly:
+		pb.WithMode(ProgressModeOff)
+	case ic.progressMode == "json":
+		pb.WithMode(ProgressModeJSON)
+	}
+	return pb
+}
+
+// progressWriter returns where progress bars and --progress=json events
+// go, keeping stdout for results
+func (ic *IndexCommand) progressWriter() io.Writer {
+	if ic.progressOut != nil {
+		return ic.progressOut
+	}
+	return os.Stderr
+}
+
+// addRootBars adds a bar per root to the progress group when the files
+// come from more than one root, in the order the roots were given
+func (ic *IndexCommand) addRootBars(files []collectedFile) {
+	counts := make(map[string]int64)
+	var roots []string
 	for _, file := range files {
-		// Check for context cancellation
-		select {
-		case <-ctx.Done():
-			return processed, errors
-		default:
+		if counts[file.root] == 0 {
+			roots = append(roots, file.root)
 		}
+		counts[file.root]++
+	}
+	if len(roots) < 2 {
+		return
+	}
 
-		// Process file (placeholder implementation)
-		err := ic.processFile(file, stats)
-		if err != nil {
-			errors = append(errors, fmt.Errorf("error processing %s: %w", file, err))
-			if ic.config.Verbose {
-				PrintWarning(fmt.Sprintf("Error processing %s: %v", file, err))
-			}
-			continue
-		}
+	ic.rootBars = make(map[string]*ProgressBar, len(roots))
+	ic.fileRoots = make(map[string]string, len(files))
+	for _, root := range roots {
+		ic.rootBars[root] = ic.progress.NewBar(root, counts[root])
+	}
+	for _, file := range files {
+		ic.fileRoots[file.path] = file.root
+	}
+}
 
-		processed++
+// advanceRoot advances the bar of the root file came from, if any
+func (ic *IndexCommand) advanceRoot(file string) {
+	if bar := ic.rootBars[ic.fileRoots[file]]; bar != nil {
+		bar.Update()
+	}
+}
 
-		// Update file type statistics
-		ext := strings.ToLower(filepath.Ext(file))
-		if ext == "" {
-			ext = "no_extension"
-		}
-		stats.FileTypes[ext]++
+// batchLabel returns the progress label of the 1-based batch out of
+// batches
+func batchLabel(batch, batches int) string {
+	return fmt.Sprintf("Processing batch %d/%d", batch, batches)
+}
+
+// processBatch processes a batch of files, fanning them out across
+// maxWorkers goroutines. batch is the 1-based index of the batch out of
+// batches, for the progress label.
+func (ic *IndexCommand) processBatch(ctx context.Context, files []string, batch, batches int, stats *IndexStats) (int, []error) {
+	var (
+		processed int
+		errors    []error
+	)
+
+	// Create progress bar for this batch
+	label := batchLabel(batch, batches)
+
+	var pb *ProgressBar
+	if ic.progress != nil {
+		pb = ic.progress.NewBar(label, int64(len(files)))
+		defer ic.progress.RemoveBar(pb)
+	} else {
+		pb = ic.applyProgressMode(NewProgressBar(label, int64(len(files))))
+		defer pb.Finish()
+	}
+	pb.Start()
+
+	workers := ic.maxWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(files) {
+		workers = len(files)
+	}
+
+	jobs := make(chan string, workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for file := range jobs {
+				// Skip queued files once cancelled
+				if ctx.Err() != nil {
+					continue
+				}
+
+				err := ic.process(ctx, file, stats)
+				if err != nil && err == ctx.Err() {
+					// Interrupted: neither processed nor failed
+					continue
+				}
+				if ic.bytes != nil {
+					ic.bytes.done(file)
+				}
+
+				if err == errBinaryFile {
+					ic.statsMu.Lock()
+					stats.SkippedBinary++
+					ic.statsMu.Unlock()
+
+					logger.Debug("skipping binary file", "path", file)
+					ic.audit(file, auditSkippedBinary, nil)
+					pb.Update()
+					ic.advanceRoot(file)
+					continue
+				}
+
+				if err == nil && ic.index != nil {
+					if recErr := ic.index.Record(file); recErr != nil {
+						err = fmt.Errorf("failed to update index manifest: %w", recErr)
+					}
+				}
+
+				if ic.manifest != nil {
+					if recErr := ic.manifest.Record(file, err); recErr != nil && ic.config.Verbose {
+						PrintWarning(fmt.Sprintf("Failed to record %s in manifest: %v", file, recErr))
+					}
+				}
+				if err != nil {
+					ic.audit(file, auditFailed, err)
+
+					ic.statsMu.Lock()
+					errors = append(errors, fmt.Errorf("error processing %s: %w", file, err))
+					ic.statsMu.Unlock()
+
+					if ic.config.Verbose {
+						PrintWarning(fmt.Sprintf("Error processing %s: %v", file, err))
+					}
+					continue
+				}
+
+				// Update file type statistics
+				ic.statsMu.Lock()
+				processed++
+				stats.FileTypes[fileType(file)]++
+				ic.statsMu.Unlock()
 
-		// Update progress bar
-		pb.Update()
+				ic.audit(file, auditProcessed, nil)
+
+				if ic.checkpoint != nil {
+					if cpErr := ic.checkpoint.Add(file, ic.checkpointInterval); cpErr != nil && ic.config.Verbose {
+						PrintWarning(fmt.Sprintf("Failed to save checkpoint: %v", cpErr))
+					}
+				}
+
+				// Update progress bars
+				pb.Update()
+				ic.advanceRoot(file)
+			}
+		}()
 	}
 
+feed:
+	for _, file := range files {
+		select {
+		case jobs <- file:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
 	return processed, errors
 }
 
+// process runs processFn, or processFile when none is set
+func (ic *IndexCommand) process(ctx context.Context, filePath string, stats *IndexStats) error {
+	if ic.processFn != nil {
+		return ic.processFn(ctx, filePath, stats)
+	}
+	return ic.processFile(ctx, filePath, stats)
+}
+
 // processFile processes a single file (placeholder)
-func (ic *IndexCommand) processFile(filePath string, stats *IndexStats) error {
+func (ic *IndexCommand) processFile(ctx context.Context, filePath string, stats *IndexStats) error {
 	// In a real implementation, this would:
 	// 1. Read file content
 	// 2. Extract text and metadata
 	// 3. Analyze content
 	// 4. Add to search index
 
-	if ic.config.Verbose {
-		PrintInfo(fmt.Sprintf("Processing: %s", filePath))
+	// Classify the content from the head of the file
+	var head []byte
+	if !ic.includeBinary || ic.detectMime {
+		var err error
+		if head, err = readHead(filePath); err != nil {
+			return err
+		}
+	}
+
+	if !ic.includeBinary && isBinary(head) {
+		return errBinaryFile
+	}
+
+	logger.Debug("processing", "path", filePath)
+
+	// Read the content through, reporting it to the bytes bar
+	if ic.bytes != nil {
+		if err := ic.bytes.readFile(ctx, filePath); err != nil {
+			return err
+		}
 	}
 
 	// Simulate processing time
-	time.Sleep(time.Millisecond * 10)
+	select {
+	case <-time.After(time.Millisecond * 10):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if ic.detectMime {
+		mimeType := detectMimeType(head)
+
+		ic.statsMu.Lock()
+		if stats.MimeTypes == nil {
+			stats.MimeTypes = make(map[string]int)
+		}
+		stats.MimeTypes[mimeType]++
+		ic.statsMu.Unlock()
+	}
 
 	return nil
 }
 
-// displayStats displays indexing statistics
-func (ic *IndexCommand) displayStats(stats *IndexStats) {
-	PrintInfo("=== Indexing Summary ===")
-	PrintInfo(fmt.Sprintf("Total files found: %d", stats.TotalFiles))
-	PrintInfo(fmt.Sprintf("Files processed: %d", stats.ProcessedFiles))
-	PrintInfo(fmt.Sprintf("Files skipped: %d", stats.SkippedFiles))
-	PrintInfo(fmt.Sprintf("Processing time: %v", stats.Duration.Round(time.Millisecond)))
+// summaryRows returns the label and value of each line of the indexing
+// summary. Counters of optional filters appear only when they applied.
+func summaryRows(stats *IndexStats) [][]string {
+	count := func(n int) string { return humanizeInt(int64(n)) }
 
-	if len(stats.Errors) > 0 {
-		PrintWarning(fmt.Sprintf("Errors encountered: %d", len(stats.Errors)))
-		if ic.config.Verbose {
-			for _, err := range stats.Errors {
-				PrintWarning(fmt.Sprintf("  %v", err))
-			}
+	rows := [][]string{
+		{"Total files found", count(stats.TotalFiles)},
+		{"Files processed", count(stats.ProcessedFiles)},
+		{"Files skipped", count(stats.SkippedFiles)},
+		{"Files failed", count(stats.FailedFiles)},
+	}
+	optional := []struct {
+		label string
+		n     int
+	}{
+		{"Files skipped by size", stats.SkippedBySize},
+		{"Files skipped as unmodified", stats.SkippedByAge},
+		{"Files unchanged since last run", stats.Unchanged},
+		{"Files processed before resuming", stats.Resumed},
+		{"Duplicate paths skipped", stats.Duplicates},
+		{"Binary files skipped", stats.SkippedBinary},
+	}
+	for _, row := range optional {
+		if row.n > 0 {
+			rows = append(rows, []string{row.label, count(row.n)})
 		}
 	}
 
+	if stats.HashAlgorithm != "" {
+		rows = append(rows, []string{"Changes detected by", stats.HashAlgorithm + " content hash"})
+	}
+	rows = append(rows, []string{"Processing time", stats.Duration.Round(time.Millisecond).String()})
 	if stats.TotalFiles > 0 && stats.Duration > 0 {
 		rate := float64(stats.ProcessedFiles) / stats.Duration.Seconds()
-		PrintInfo(fmt.Sprintf("Processing rate: %.2f files/second", rate))
+		rows = append(rows, []string{"Processing rate", fmt.Sprintf("%.2f files/second", rate)})
 	}
+	if stats.ProcessedFiles+stats.FailedFiles > 0 {
+		rows = append(rows, []string{"Success rate", fmt.Sprintf("%.1f%%", stats.successRate())})
+	}
+	return rows
+}
 
-	PrintInfo("=== File Types Processed ===")
-	for ext, count := range stats.FileTypes {
-		PrintInfo(fmt.Sprintf("  %s: %d files", ext, count))
+// fileTypeRows returns a row per type with its file count and share of
+// the files counted, in the order of sortedFileTypes
+func fileTypeRows(counts map[string]int) [][]string {
+	total := 0
+	for _, n := range counts {
+		total += n
 	}
 
-	successRate := float64(stats.ProcessedFiles) / float64(stats.TotalFiles) * 100
-	PrintInfo(fmt.Sprintf("Success rate: %.1f%%", successRate))
+	rows := make([][]string, 0, len(counts))
+	for _, count := range sortedFileTypes(counts) {
+		share := float64(count.Files) / float64(total) * 100
+		rows = append(rows, []string{count.Type, humanizeInt(int64(count.Files)), fmt.Sprintf("%.1f%%", share)})
+	}
+	return rows
+}
 
-	if len(stats.Errors) == 0 {
+// writeFileTypesCSV writes one row per file type, ordered by type
+func writeFileTypesCSV(w io.Writer, fileTypes map[string]int) error {
+	types := make([]string, 0, len(fileTypes))
+	for ext := range fileTypes {
+		types = append(types, ext)
+	}
+	sort.Strings(types)
+
+	renderer := newCSVRenderer(w)
+	for _, ext := range types {
+		if err := renderer.Record("file_type", FileTypeCount{Type: ext, Files: fileTypes[ext]}); err != nil {
+			return err
+		}
+	}
+	return renderer.Flush()
+}
+
+// displayStats displays indexing statistics, as a table or as an index
+// document in the structured output formats
+func (ic *IndexCommand) displayStats(stats *IndexStats) error {
+	// Settle the progress bars before printing below them
+	if ic.progress != nil {
+		if stats.Cancelled {
+			ic.totalBar.Fail("cancelled")
+		} else if len(stats.Errors) > 0 {
+			ic.totalBar.Fail(fmt.Sprintf("%d error(s)", len(stats.Errors)))
+		}
+		// Roots whose files did not all complete would jump to 100%
+		for _, bar := range ic.rootBars {
+			if stats.Cancelled {
+				bar.Fail("cancelled")
+			} else if bar.GetProgress() < 1 {
+				bar.Fail("incomplete")
+			}
+		}
+		ic.progress.Finish()
+	}
+
+	// A CSV holds a single table: the file types
+	if ic.config.OutputFormat == "csv" {
+		if err := writeFileTypesCSV(ic.config.Stdout(), stats.FileTypes); err != nil {
+			return fmt.Errorf("failed to write statistics: %w", err)
+		}
+		return nil
+	}
+
+	// Structured formats get the statistics as one document on stdout;
+	// errors and the outcome go to stderr as warnings
+	if renderer := NewRenderer(ic.config.OutputFormat, ic.config.Stdout()); renderer != nil {
+		if err := renderer.Render("index", stats); err != nil {
+			return err
+		}
+		if err := renderer.Flush(); err != nil {
+			return err
+		}
+		if stats.Cancelled {
+			PrintWarning("Indexing cancelled; statistics cover the files processed so far")
+		} else if len(stats.Errors) > 0 {
+			PrintWarning(fmt.Sprintf("Indexing completed with %s error(s)", humanizeInt(int64(len(stats.Errors)))))
+		}
+		return nil
+	}
+
+	summary := summaryRows(stats)
+	fileTypes := fileTypeRows(stats.FileTypes)
+	mimeTypes := fileTypeRows(stats.MimeTypes)
+
+	// The table format aligns the summary and breakdowns in columns
+	PrintInfo("=== Indexing Summary ===")
+	PrintTable([]string{"Metric", "Value"}, summary)
+	if len(fileTypes) > 0 {
+		PrintInfo("=== File Types Processed ===")
+		PrintTable([]string{"Type", "Files", "Share"}, fileTypes)
+	}
+	if len(mimeTypes) > 0 {
+		PrintInfo("=== MIME Types Processed ===")
+		PrintTable([]string{"MIME Type", "Files", "Share"}, mimeTypes)
+	}
+
+	if len(stats.Errors) > 0 {
+		PrintWarning(fmt.Sprintf("Errors encountered: %s", humanizeInt(int64(len(stats.Errors)))))
+		if ic.config.Verbose {
+			for _, err := range stats.Errors {
+				PrintWarning(fmt.Sprintf("  %v", err))
+			}
+		}
+	}
+
+	if stats.Cancelled {
+		PrintWarning("Indexing cancelled; statistics cover the files processed so far")
+	} else if len(stats.Errors) == 0 {
 		PrintSuccess("Indexing completed successfully!")
 	} else {
 		PrintWarning("Indexing completed with errors")
 	}
+	return nil
 }
\ No newline at end of file
@@ -1,8 +1,26 @@
 package cli
 
 import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"testing/iotest"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestIndexCommandValidation(t *testing.T) {
@@ -89,6 +107,51 @@ func TestIndexCommandValidation(t *testing.T) {
 			},
 			expectErr: false,
 		},
+		{
+			name: "Valid size limits",
+			config: &IndexCommand{
+				maxWorkers: 4,
+				batchSize:  100,
+				indexType:  "full",
+				minSize:    "1KB",
+				maxSize:    "10MB",
+			},
+			expectErr: false,
+		},
+		{
+			name: "Invalid max size",
+			config: &IndexCommand{
+				maxWorkers: 4,
+				batchSize:  100,
+				indexType:  "full",
+				maxSize:    "huge",
+			},
+			expectErr: true,
+			errField:  "invalid --max-size",
+		},
+		{
+			name: "Invalid modified since",
+			config: &IndexCommand{
+				maxWorkers:    4,
+				batchSize:     100,
+				indexType:     "full",
+				modifiedSince: "last tuesday",
+			},
+			expectErr: true,
+			errField:  "invalid --modified-since",
+		},
+		{
+			name: "Min size above max size",
+			config: &IndexCommand{
+				maxWorkers: 4,
+				batchSize:  100,
+				indexType:  "full",
+				minSize:    "2MB",
+				maxSize:    "1MB",
+			},
+			expectErr: true,
+			errField:  "--min-size",
+		},
 	}
 
 	for _, tt := range tests {
@@ -134,6 +197,39 @@ func TestIndexPatternMatching(t *testing.T) {
 	}
 }
 
+func TestIndexIgnoreCase(t *testing.T) {
+	tests := []struct {
+		name        string
+		ignoreCase  bool
+		filePath    string
+		shouldMatch bool
+		excluded    bool
+	}{
+		{"Case sensitive upper", false, "README.MD", false, false},
+		{"Case sensitive lower", false, "readme.md", true, true},
+		{"Ignore case upper", true, "README.MD", true, true},
+		{"Ignore case mixed path", true, "Docs/Notes.Md", true, true},
+		{"Ignore case no match", true, "README.TXT", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ic := &IndexCommand{
+				patterns:     []string{"*.md"},
+				excludePaths: []string{"*.md"},
+				ignoreCase:   tt.ignoreCase,
+			}
+
+			if got := ic.matchesPattern(tt.filePath); got != tt.shouldMatch {
+				t.Errorf("matchesPattern(%s) = %v, expected %v", tt.filePath, got, tt.shouldMatch)
+			}
+			if got := ic.shouldExclude(tt.filePath); got != tt.excluded {
+				t.Errorf("shouldExclude(%s) = %v, expected %v", tt.filePath, got, tt.excluded)
+			}
+		})
+	}
+}
+
 func TestIndexShouldExclude(t *testing.T) {
 	ic := &IndexCommand{
 		excludePaths: []string{"*.tmp", "*.log", ".*"},
@@ -198,11 +294,12 @@ func TestIndexStatsCreation(t *testing.T) {
 
 func TestIndexDryRun(t *testing.T) {
 	ic := &IndexCommand{
-		config:     &CommandConfig{},
-		paths:      []string{"."},
-		recursive:  true,
-		dryRun:     true,
-		patterns:   []string{"*"},
+		config:    &CommandConfig{},
+		paths:     []string{"."},
+		recursive: true,
+		maxDepth:  -1,
+		dryRun:    true,
+		patterns:  []string{"*"},
 	}
 
 	// Test dry-run mode doesn't panic
@@ -227,6 +324,167 @@ func TestIndexDryRun(t *testing.T) {
 	_ = ic // Suppress unused variable warning
 }
 
+func TestIndexDryRunStructuredOutput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-dryrun")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"a.md", "b.md", "c.txt", "Makefile"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		format    string
+		unmarshal func([]byte, interface{}) error
+	}{
+		{"json", json.Unmarshal},
+		{"yaml", yaml.Unmarshal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			ic := &IndexCommand{
+				config:     &CommandConfig{OutputFormat: tt.format},
+				recursive:  true,
+				maxDepth:   -1,
+				dryRun:     true,
+				patterns:   []string{"*"},
+				maxWorkers: 1,
+				batchSize:  10,
+				indexType:  "full",
+
+				indexManifestPath: filepath.Join(dir, "manifest.json"),
+			}
+
+			output := captureStdout(t, func() {
+				if err := ic.runIndex(nil, []string{dir}); err != nil {
+					t.Errorf("runIndex() returned error: %v", err)
+				}
+			})
+
+			var plan DryRunPlan
+			if err := tt.unmarshal([]byte(output), &plan); err != nil {
+				t.Fatalf("Output is not valid %s: %v\n%s", tt.format, err, output)
+			}
+
+			if plan.TotalFiles != 4 || len(plan.Files) != 4 || plan.Truncated {
+				t.Errorf("Unexpected plan: %+v", plan)
+			}
+			expectedTypes := map[string]int{".md": 2, ".txt": 1, "no_extension": 1}
+			if !reflect.DeepEqual(plan.FileTypes, expectedTypes) {
+				t.Errorf("FileTypes = %v, expected %v", plan.FileTypes, expectedTypes)
+			}
+		})
+	}
+}
+
+func TestIndexRejectsPrometheusOutput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-dryrun-prometheus")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "a.md"), nil, 0644); err != nil {
+		t.Fatalf("Failed to write a.md: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"index", ".", "--dry-run", "-o", "prometheus"},
+		{"index", ".", "-o", "prometheus"},
+	} {
+		cli := NewCLI()
+		cli.RootCmd.SetArgs(append([]string{"--workspace", dir, "--no-color"}, args...))
+		var usage bytes.Buffer
+		cli.RootCmd.SetOut(&usage)
+		cli.RootCmd.SetErr(&usage)
+
+		var err error
+		output := captureStdout(t, func() {
+			err = cli.RootCmd.Execute()
+		})
+		if err == nil || !strings.Contains(err.Error(), "prometheus output is not supported for index") {
+			t.Errorf("%v: error = %v, expected prometheus to be unsupported", args, err)
+		}
+		if strings.Contains(usage.String(), "Usage:") || strings.Contains(output, "Usage:") {
+			t.Errorf("%v: printed the usage text for a runtime error:\n%s%s", args, output, usage.String())
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, defaultIndexManifestPath())); !os.IsNotExist(err) {
+		t.Errorf("index manifest exists after a rejected run, stat error = %v", err)
+	}
+}
+
+func TestIndexDryRunCSV(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-dryrun-csv")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"a.md", "c.txt", "Makefile"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	ic := &IndexCommand{
+		config:     &CommandConfig{OutputFormat: "csv"},
+		recursive:  true,
+		maxDepth:   -1,
+		dryRun:     true,
+		patterns:   []string{"*"},
+		maxWorkers: 1,
+		batchSize:  10,
+		indexType:  "full",
+
+		indexManifestPath: filepath.Join(dir, "manifest.json"),
+	}
+
+	output := captureStdout(t, func() {
+		if err := ic.runIndex(nil, []string{dir}); err != nil {
+			t.Errorf("runIndex() returned error: %v", err)
+		}
+	})
+
+	rows, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v\n%s", err, output)
+	}
+
+	expected := [][]string{
+		{"path", "type"},
+		{filepath.Join(dir, "Makefile"), "no_extension"},
+		{filepath.Join(dir, "a.md"), ".md"},
+		{filepath.Join(dir, "c.txt"), ".txt"},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("csv rows = %q, expected %q", rows, expected)
+	}
+}
+
+func TestWriteFileTypesCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeFileTypesCSV(&buf, map[string]int{".txt": 50, ".md": 45, "no_extension": 1}); err != nil {
+		t.Fatalf("writeFileTypesCSV() error = %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v", err)
+	}
+
+	expected := [][]string{{"type", "files"}, {".md", "45"}, {".txt", "50"}, {"no_extension", "1"}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("csv rows = %q, expected %q", rows, expected)
+	}
+}
+
 func TestIndexDisplayStats(t *testing.T) {
 	ic := &IndexCommand{
 		config: &CommandConfig{Verbose: true},
@@ -254,37 +512,1803 @@ func TestIndexDisplayStats(t *testing.T) {
 	ic.displayStats(stats)
 }
 
-// Benchmarks
-func BenchmarkIndexPatternMatching(b *testing.B) {
-	ic := &IndexCommand{
-		patterns: []string{"*.txt", "*.md", "*.go", "*.json"},
+// tableRows returns the trimmed cells of the rows of the tables in out
+func tableRows(out string) [][]string {
+	var rows [][]string
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "|") {
+			continue
+		}
+		var cells []string
+		for _, cell := range strings.Split(strings.Trim(line, "|"), "|") {
+			cells = append(cells, strings.TrimSpace(cell))
+		}
+		rows = append(rows, cells)
 	}
+	return rows
+}
 
-	testFiles := []string{
-		"document.txt", "README.md", "main.go", "config.json",
-		"script.py", "image.png", "data.csv", "notes.md",
+func TestIndexDisplayStatsTable(t *testing.T) {
+	preserveTheme(t)
+	setupOutput(&CommandConfig{NoColor: true})
+
+	ic := &IndexCommand{config: &CommandConfig{OutputFormat: "table"}}
+	stats := &IndexStats{
+		TotalFiles:     10,
+		ProcessedFiles: 10,
+		Duration:       time.Second,
+		FileTypes:      map[string]int{".md": 6, ".go": 3, "no_extension": 1},
 	}
 
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		file := testFiles[i%len(testFiles)]
-		_ = ic.matchesPattern(file)
+	out := captureStdout(t, func() { ic.displayStats(stats) })
+
+	for _, header := range []string{"METRIC", "VALUE", "TYPE", "FILES", "SHARE"} {
+		if !strings.Contains(out, header) {
+			t.Errorf("output is missing the %s header:\n%s", header, out)
+		}
+	}
+
+	values := make(map[string][]string)
+	for _, row := range tableRows(out) {
+		values[row[0]] = row[1:]
+	}
+	if got := values["Files processed"]; !reflect.DeepEqual(got, []string{"10"}) {
+		t.Errorf("Files processed row = %v, expected [10]", got)
+	}
+	expected := map[string][]string{
+		".md":          {"6", "60.0%"},
+		".go":          {"3", "30.0%"},
+		"no_extension": {"1", "10.0%"},
+	}
+	for ext, want := range expected {
+		if got := values[ext]; !reflect.DeepEqual(got, want) {
+			t.Errorf("row for %s = %v, expected %v", ext, got, want)
+		}
 	}
 }
 
-func BenchmarkIndexShouldExclude(b *testing.B) {
+func TestIndexDisplayStatsFileTypeOrder(t *testing.T) {
+	ic := &IndexCommand{config: &CommandConfig{}}
+	stats := &IndexStats{
+		TotalFiles:     10,
+		ProcessedFiles: 10,
+		FileTypes:      map[string]int{".txt": 2, ".md": 4, ".go": 2, ".yaml": 1, "no_extension": 1},
+	}
+
+	first := captureStdout(t, func() { ic.displayStats(stats) })
+	for i := 0; i < 20; i++ {
+		if got := captureStdout(t, func() { ic.displayStats(stats) }); got != first {
+			t.Fatalf("displayStats() output changed between runs:\n%s\n---\n%s", first, got)
+		}
+	}
+
+	var order []string
+	for _, row := range tableRows(first) {
+		if strings.HasPrefix(row[0], ".") || row[0] == "no_extension" {
+			order = append(order, row[0])
+		}
+	}
+	expected := []string{".md", ".go", ".txt", ".yaml", "no_extension"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("file types listed as %v, expected %v", order, expected)
+	}
+}
+
+func TestIndexCollectFilesSizeLimits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-size")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	sizes := map[string]int{"empty.txt": 0, "small.txt": 100, "medium.txt": 2000, "large.bin": 20000}
+	for name, size := range sizes {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), make([]byte, size), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		minSize     string
+		maxSize     string
+		wantFiles   int
+		wantSkipped int
+	}{
+		{"No limits", "", "", 4, 0},
+		{"Max size", "", "10KB", 3, 1},
+		{"Min size", "1KiB", "", 2, 2},
+		{"Both limits", "1", "2KB", 2, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ic := &IndexCommand{
+				config:     &CommandConfig{},
+				paths:      []string{dir},
+				recursive:  true,
+				maxDepth:   -1,
+				patterns:   []string{"*"},
+				maxWorkers: 1,
+				batchSize:  1,
+				indexType:  "full",
+				minSize:    tt.minSize,
+				maxSize:    tt.maxSize,
+			}
+			if err := ic.validateConfig(); err != nil {
+				t.Fatalf("validateConfig() returned error: %v", err)
+			}
+
+			stats := &IndexStats{}
+			files, err := ic.collectFiles(context.Background(), stats)
+			if err != nil {
+				t.Fatalf("collectFiles() returned error: %v", err)
+			}
+
+			if len(files) != tt.wantFiles || stats.SkippedBySize != tt.wantSkipped {
+				t.Errorf("collectFiles() = %d files, %d skipped by size, expected %d and %d",
+					len(files), stats.SkippedBySize, tt.wantFiles, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestIndexCollectFilesExcludeDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-exclude-dir")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Files below the excluded directories exceed --max-size, so any
+	// file visited there is counted in SkippedBySize
+	files := map[string]int{
+		"main.go":                      10,
+		"docs/readme.md":               10,
+		"node_modules/pkg/index.js":    100,
+		"node_modules/pkg/lib/util.js": 100,
+		"web/node_modules/dep/dep.js":  100,
+		"build/cache/object.o":         100,
+		"build/keep.txt":               10,
+	}
+	for name, size := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := ioutil.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
 	ic := &IndexCommand{
-		excludePaths: []string{"*.tmp", "*.log", ".*", "*.backup"},
+		config:      &CommandConfig{},
+		paths:       []string{dir},
+		recursive:   true,
+		maxDepth:    -1,
+		patterns:    []string{"*"},
+		excludeDirs: []string{"node_modules", "build/cache"},
+		maxWorkers:  1,
+		batchSize:   1,
+		indexType:   "full",
+		maxSize:     "50",
+	}
+	if err := ic.validateConfig(); err != nil {
+		t.Fatalf("validateConfig() returned error: %v", err)
 	}
 
-	testFiles := []string{
-		"temp.tmp", "app.log", ".gitignore", "file.backup",
-		"document.txt", "README.md", "main.go", "config.json",
+	stats := &IndexStats{}
+	got, err := ic.collectFiles(context.Background(), stats)
+	if err != nil {
+		t.Fatalf("collectFiles() returned error: %v", err)
 	}
 
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		file := testFiles[i%len(testFiles)]
-		_ = ic.shouldExclude(file)
+	var rel []string
+	for _, file := range got {
+		rel = append(rel, filepath.ToSlash(relativePath(dir, file)))
+	}
+	sort.Strings(rel)
+
+	expected := []string{"build/keep.txt", "docs/readme.md", "main.go"}
+	if !reflect.DeepEqual(rel, expected) {
+		t.Errorf("collectFiles() = %v, expected %v", rel, expected)
+	}
+	if stats.SkippedBySize != 0 {
+		t.Errorf("visited %d files below excluded directories", stats.SkippedBySize)
+	}
+}
+
+func TestParseModifiedSince(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Time
+		wantErr  bool
+	}{
+		{"24h", now.Add(-24 * time.Hour), false},
+		{"90m", now.Add(-90 * time.Minute), false},
+		{"2024-05-01T00:00:00Z", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), false},
+		{"-1h", time.Time{}, true},
+		{"yesterday", time.Time{}, true},
+		{"2024-05-01", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			cutoff, err := parseModifiedSince(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseModifiedSince(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !cutoff.Equal(tt.expected) {
+				t.Errorf("parseModifiedSince(%q) = %v, expected %v", tt.value, cutoff, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIndexCollectFilesModifiedSince(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-mtime")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ages := map[string]time.Duration{
+		"fresh.md": time.Hour,
+		"day.md":   30 * time.Hour,
+		"week.md":  7 * 24 * time.Hour,
+	}
+	for name, age := range ages {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		mtime := time.Now().Add(-age)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatalf("Failed to set mtime of %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		modifiedSince string
+		wantFiles     int
+		wantSkipped   int
+	}{
+		{"No cutoff", "", 3, 0},
+		{"Last day", "24h", 1, 2},
+		{"Last two days", "48h", 2, 1},
+		{"Timestamp", time.Now().Add(-10 * 24 * time.Hour).Format(time.RFC3339), 3, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ic := &IndexCommand{
+				config:        &CommandConfig{},
+				paths:         []string{dir},
+				recursive:     true,
+				maxDepth:      -1,
+				patterns:      []string{"*"},
+				maxWorkers:    1,
+				batchSize:     1,
+				indexType:     "full",
+				modifiedSince: tt.modifiedSince,
+			}
+			if err := ic.validateConfig(); err != nil {
+				t.Fatalf("validateConfig() returned error: %v", err)
+			}
+
+			stats := &IndexStats{}
+			files, err := ic.collectFiles(context.Background(), stats)
+			if err != nil {
+				t.Fatalf("collectFiles() returned error: %v", err)
+			}
+
+			if len(files) != tt.wantFiles || stats.SkippedByAge != tt.wantSkipped {
+				t.Errorf("collectFiles() = %d files, %d skipped by age, expected %d and %d",
+					len(files), stats.SkippedByAge, tt.wantFiles, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestIndexIncrementalSkipsUnchanged(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-incremental")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	oldDir := tempFiles.Dir()
+	tempFiles.SetDir(filepath.Join(dir, "tmp"))
+	defer tempFiles.SetDir(oldDir)
+
+	docs := filepath.Join(dir, "docs")
+	if err := os.Mkdir(docs, 0755); err != nil {
+		t.Fatalf("Failed to create docs dir: %v", err)
+	}
+	for _, name := range []string{"a.md", "b.md", "c.md"} {
+		if err := ioutil.WriteFile(filepath.Join(docs, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	indexManifestPath := filepath.Join(dir, "manifest.json")
+
+	run := func(force bool) int32 {
+		var calls int32
+		ic := &IndexCommand{
+			config:     &CommandConfig{},
+			recursive:  true,
+			maxDepth:   -1,
+			patterns:   []string{"*"},
+			maxWorkers: 2,
+			batchSize:  10,
+			indexType:  "incremental",
+			force:      force,
+			processFn: func(ctx context.Context, filePath string, stats *IndexStats) error {
+				atomic.AddInt32(&calls, 1)
+				return nil
+			},
+			indexManifestPath: indexManifestPath,
+		}
+
+		if err := ic.runIndex(nil, []string{docs}); err != nil {
+			t.Fatalf("runIndex() returned error: %v", err)
+		}
+		return calls
+	}
+
+	if calls := run(false); calls != 3 {
+		t.Errorf("First run processed %d files, expected 3", calls)
+	}
+	if calls := run(false); calls != 0 {
+		t.Errorf("Second run processed %d files, expected 0 unchanged files to be skipped", calls)
+	}
+
+	// Change one file and delete another
+	if err := ioutil.WriteFile(filepath.Join(docs, "a.md"), []byte("changed content"), 0644); err != nil {
+		t.Fatalf("Failed to modify a.md: %v", err)
+	}
+	if err := os.Remove(filepath.Join(docs, "c.md")); err != nil {
+		t.Fatalf("Failed to remove c.md: %v", err)
+	}
+	if calls := run(false); calls != 1 {
+		t.Errorf("Run after change processed %d files, expected 1", calls)
 	}
-}
\ No newline at end of file
+
+	manifest, err := LoadIndexManifest(indexManifestPath)
+	if err != nil {
+		t.Fatalf("LoadIndexManifest() returned error: %v", err)
+	}
+	if manifest.Len() != 2 {
+		t.Errorf("Manifest has %d entries, expected deleted file to be pruned leaving 2", manifest.Len())
+	}
+	entry := manifest.Files[manifestKey(filepath.Join(docs, "a.md"))]
+	if expected, _ := hashFile(filepath.Join(docs, "a.md"), defaultHashAlgorithm); entry.Hash != expected {
+		t.Errorf("Manifest hash for a.md = %q, expected %q", entry.Hash, expected)
+	}
+
+	if calls := run(true); calls != 2 {
+		t.Errorf("Forced run processed %d files, expected 2", calls)
+	}
+}
+
+func TestIndexDisplayStatsFailsProgress(t *testing.T) {
+	var buf bytes.Buffer
+	ic := &IndexCommand{
+		config:   &CommandConfig{},
+		progress: NewProgressGroup().WithWriter(&buf),
+	}
+	ic.totalBar = ic.progress.NewBar("Indexing files", 2)
+	ic.totalBar.Start()
+
+	stats := &IndexStats{
+		TotalFiles:     2,
+		ProcessedFiles: 1,
+		Errors:         []error{errors.New("boom")},
+		FileTypes:      map[string]int{},
+	}
+
+	ic.displayStats(stats)
+
+	if !ic.totalBar.IsFailed() {
+		t.Errorf("Expected overall bar to be failed when stats has errors")
+	}
+	if !strings.Contains(buf.String(), "✗ 1 error(s)") {
+		t.Errorf("Expected failure marker in progress output, got %q", buf.String())
+	}
+}
+
+func TestIndexProcessBatchConcurrency(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+	}{
+		{"Single worker", 1},
+		{"Four workers", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running, peak int32
+
+			ic := &IndexCommand{
+				config:     &CommandConfig{},
+				maxWorkers: tt.workers,
+				batchSize:  20,
+				processFn: func(ctx context.Context, filePath string, stats *IndexStats) error {
+					n := atomic.AddInt32(&running, 1)
+					for {
+						p := atomic.LoadInt32(&peak)
+						if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+							break
+						}
+					}
+					time.Sleep(5 * time.Millisecond)
+					atomic.AddInt32(&running, -1)
+
+					if strings.HasSuffix(filePath, "bad.txt") {
+						return errors.New("unreadable")
+					}
+					return nil
+				},
+			}
+
+			files := []string{"bad.txt"}
+			for i := 0; i < 19; i++ {
+				files = append(files, fmt.Sprintf("file%d.md", i))
+			}
+
+			stats := &IndexStats{FileTypes: make(map[string]int)}
+			processed, errs := ic.processBatch(context.Background(), files, 1, 1, stats)
+
+			if processed != 19 || len(errs) != 1 {
+				t.Errorf("processBatch() = %d processed, %d errors, expected 19 and 1", processed, len(errs))
+			}
+			if stats.FileTypes[".md"] != 19 {
+				t.Errorf("FileTypes[.md] = %d, expected 19", stats.FileTypes[".md"])
+			}
+
+			if tt.workers == 1 && peak != 1 {
+				t.Errorf("Peak concurrency = %d, expected 1", peak)
+			}
+			if tt.workers > 1 && peak < 2 {
+				t.Errorf("Peak concurrency = %d, expected more than one worker at a time", peak)
+			}
+			if int(peak) > tt.workers {
+				t.Errorf("Peak concurrency = %d exceeds %d workers", peak, tt.workers)
+			}
+		})
+	}
+}
+
+func TestIndexProcessBatchCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var calls int32
+	ic := &IndexCommand{
+		config:     &CommandConfig{},
+		maxWorkers: 2,
+		batchSize:  100,
+		processFn: func(ctx context.Context, filePath string, stats *IndexStats) error {
+			if atomic.AddInt32(&calls, 1) == 2 {
+				cancel()
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+
+	files := make([]string, 100)
+	for i := range files {
+		files[i] = fmt.Sprintf("file%d.txt", i)
+	}
+
+	processed, errs := ic.processBatch(ctx, files, 1, 1, &IndexStats{FileTypes: make(map[string]int)})
+
+	if processed != 0 || len(errs) != 0 {
+		t.Errorf("processBatch() = %d processed, %d errors, expected interrupted files to count as neither", processed, len(errs))
+	}
+	if n := atomic.LoadInt32(&calls); n > 4 {
+		t.Errorf("processFn called %d times after cancellation, expected workers to stop promptly", n)
+	}
+}
+
+// Benchmarks
+func BenchmarkIndexPatternMatching(b *testing.B) {
+	ic := &IndexCommand{
+		patterns: []string{"*.txt", "*.md", "*.go", "*.json"},
+	}
+
+	testFiles := []string{
+		"document.txt", "README.md", "main.go", "config.json",
+		"script.py", "image.png", "data.csv", "notes.md",
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		file := testFiles[i%len(testFiles)]
+		_ = ic.matchesPattern(file)
+	}
+}
+
+func BenchmarkIndexShouldExclude(b *testing.B) {
+	ic := &IndexCommand{
+		excludePaths: []string{"*.tmp", "*.log", ".*", "*.backup"},
+	}
+
+	testFiles := []string{
+		"temp.tmp", "app.log", ".gitignore", "file.backup",
+		"document.txt", "README.md", "main.go", "config.json",
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		file := testFiles[i%len(testFiles)]
+		_ = ic.shouldExclude(file)
+	}
+}
+
+// writeCollectRoots creates roots directories of filesPerRoot files each,
+// spread over a few subdirectories, and returns their paths
+func writeCollectRoots(tb testing.TB, dir string, roots, filesPerRoot int) []string {
+	var paths []string
+	for r := 0; r < roots; r++ {
+		root := filepath.Join(dir, fmt.Sprintf("root%d", r))
+		for f := 0; f < filesPerRoot; f++ {
+			sub := filepath.Join(root, fmt.Sprintf("sub%d", f%5))
+			if err := os.MkdirAll(sub, 0755); err != nil {
+				tb.Fatalf("Failed to create dir: %v", err)
+			}
+			if err := ioutil.WriteFile(filepath.Join(sub, fmt.Sprintf("file%03d.md", f)), []byte("x"), 0644); err != nil {
+				tb.Fatalf("Failed to write file: %v", err)
+			}
+		}
+		paths = append(paths, root)
+	}
+	return paths
+}
+
+func TestIndexCollectFilesConcurrentRoots(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-collect-roots")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	roots := writeCollectRoots(t, dir, 4, 20)
+	// The overlapping root makes the order files are deduplicated in
+	// matter
+	paths := append([]string{filepath.Join(roots[2], "sub1")}, roots...)
+
+	collect := func(workers int) ([]collectedFile, *IndexStats) {
+		ic := &IndexCommand{
+			config:     &CommandConfig{},
+			paths:      paths,
+			recursive:  true,
+			maxDepth:   -1,
+			patterns:   []string{"*"},
+			maxWorkers: workers,
+			batchSize:  1,
+			indexType:  "full",
+		}
+		stats := &IndexStats{}
+		files, err := ic.collectTaggedFiles(context.Background(), stats)
+		if err != nil {
+			t.Fatalf("collectTaggedFiles() returned error: %v", err)
+		}
+		return files, stats
+	}
+
+	sequential, sequentialStats := collect(1)
+	if len(sequential) != 80 || sequentialStats.Duplicates != 4 {
+		t.Fatalf("collected %d files with %d duplicates, expected 80 and 4", len(sequential), sequentialStats.Duplicates)
+	}
+	if sequential[0].root != paths[0] {
+		t.Errorf("first file is from %s, expected the first root %s", sequential[0].root, paths[0])
+	}
+
+	for i := 0; i < 10; i++ {
+		concurrent, concurrentStats := collect(4)
+		if !reflect.DeepEqual(concurrent, sequential) {
+			t.Fatalf("concurrent collection differs from sequential")
+		}
+		if concurrentStats.Duplicates != sequentialStats.Duplicates {
+			t.Fatalf("concurrent duplicates = %d, expected %d", concurrentStats.Duplicates, sequentialStats.Duplicates)
+		}
+	}
+}
+
+func BenchmarkIndexCollectFilesRoots(b *testing.B) {
+	dir, err := ioutil.TempDir("", "stroidex-collect-bench")
+	if err != nil {
+		b.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	roots := writeCollectRoots(b, dir, 8, 500)
+
+	for _, workers := range []int{1, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			ic := &IndexCommand{
+				config:     &CommandConfig{},
+				paths:      roots,
+				recursive:  true,
+				maxDepth:   -1,
+				patterns:   []string{"*"},
+				maxWorkers: workers,
+				batchSize:  1,
+				indexType:  "full",
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := ic.collectFiles(context.Background(), &IndexStats{}); err != nil {
+					b.Fatalf("collectFiles() returned error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestIndexIncrementalHash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-incremental-hash")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	oldDir := tempFiles.Dir()
+	tempFiles.SetDir(filepath.Join(dir, "tmp"))
+	defer tempFiles.SetDir(oldDir)
+
+	docs := filepath.Join(dir, "docs")
+	if err := os.Mkdir(docs, 0755); err != nil {
+		t.Fatalf("Failed to create docs dir: %v", err)
+	}
+	a, b := filepath.Join(docs, "a.md"), filepath.Join(docs, "b.md")
+	indexManifestPath := filepath.Join(dir, "manifest.json")
+
+	run := func(t *testing.T, hash string) []string {
+		var mu sync.Mutex
+		var processed []string
+		ic := &IndexCommand{
+			config:        &CommandConfig{},
+			recursive:     true,
+			maxDepth:      -1,
+			patterns:      []string{"*"},
+			maxWorkers:    1,
+			batchSize:     10,
+			indexType:     "incremental",
+			hashAlgorithm: hash,
+			processFn: func(ctx context.Context, filePath string, stats *IndexStats) error {
+				mu.Lock()
+				processed = append(processed, filepath.Base(filePath))
+				mu.Unlock()
+				return nil
+			},
+			indexManifestPath: indexManifestPath,
+		}
+
+		if err := ic.runIndex(nil, []string{docs}); err != nil {
+			t.Fatalf("runIndex() returned error: %v", err)
+		}
+		sort.Strings(processed)
+		return processed
+	}
+
+	// change indexes both files, then rewrites a.md with content of the
+	// same size and its old modification time, and touches b.md
+	change := func(t *testing.T, hash string) {
+		for _, file := range []string{a, b} {
+			if err := ioutil.WriteFile(file, []byte("original"), 0644); err != nil {
+				t.Fatalf("Failed to write %s: %v", file, err)
+			}
+		}
+		run(t, hash)
+
+		info, err := os.Stat(a)
+		if err != nil {
+			t.Fatalf("Failed to stat a.md: %v", err)
+		}
+		if err := ioutil.WriteFile(a, []byte("modified"), 0644); err != nil {
+			t.Fatalf("Failed to modify a.md: %v", err)
+		}
+		if err := os.Chtimes(a, info.ModTime(), info.ModTime()); err != nil {
+			t.Fatalf("Failed to restore a.md mtime: %v", err)
+		}
+
+		later := info.ModTime().Add(time.Hour)
+		if err := os.Chtimes(b, later, later); err != nil {
+			t.Fatalf("Failed to touch b.md: %v", err)
+		}
+	}
+
+	t.Run("Without hash", func(t *testing.T) {
+		change(t, "")
+		if got := run(t, ""); !reflect.DeepEqual(got, []string{"b.md"}) {
+			t.Errorf("processed %v, expected only the touched b.md", got)
+		}
+	})
+
+	for _, algorithm := range hashAlgorithmNames() {
+		t.Run(algorithm, func(t *testing.T) {
+			change(t, algorithm)
+			if got := run(t, algorithm); !reflect.DeepEqual(got, []string{"a.md"}) {
+				t.Errorf("processed %v, expected only a.md, whose content changed", got)
+			}
+
+			manifest, err := LoadIndexManifest(indexManifestPath)
+			if err != nil {
+				t.Fatalf("LoadIndexManifest() returned error: %v", err)
+			}
+			entry := manifest.Files[manifestKey(a)]
+			if expected, _ := hashFile(a, algorithm); entry.Hash != expected || entry.HashAlgorithm != algorithm {
+				t.Errorf("manifest entry = %+v, expected %s hash %q", entry, algorithm, expected)
+			}
+		})
+	}
+}
+
+func TestIndexManifestHeader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-manifest")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	oldDir := tempFiles.Dir()
+	tempFiles.SetDir(filepath.Join(dir, "tmp"))
+	defer tempFiles.SetDir(oldDir)
+
+	docs := filepath.Join(dir, "docs")
+	if err := os.Mkdir(docs, 0755); err != nil {
+		t.Fatalf("Failed to create docs dir: %v", err)
+	}
+	for _, name := range []string{"a.md", "b.txt"} {
+		if err := ioutil.WriteFile(filepath.Join(docs, name), []byte("content"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	manifestPath := filepath.Join(dir, "run.jsonl")
+	ic := &IndexCommand{
+		config:       &CommandConfig{OutputFormat: "json"},
+		recursive:    true,
+		maxDepth:     -1,
+		patterns:     []string{"*.md", "*.txt"},
+		excludePaths: []string{"*.tmp"},
+		maxWorkers:   2,
+		batchSize:    10,
+		indexType:    "full",
+		manifestPath: manifestPath,
+
+		indexManifestPath: filepath.Join(dir, "index.json"),
+	}
+
+	if err := ic.runIndex(nil, []string{docs}); err != nil {
+		t.Fatalf("runIndex() returned error: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected header and 2 file records, got %d lines", len(lines))
+	}
+
+	var header ManifestHeader
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		t.Fatalf("Failed to unmarshal header: %v", err)
+	}
+
+	if header.Type != "header" {
+		t.Errorf("Expected first record type 'header', got '%s'", header.Type)
+	}
+
+	expected := ic.resolvedConfig()
+	if !reflect.DeepEqual(header.Config, expected) {
+		t.Errorf("Header config = %+v, expected %+v", header.Config, expected)
+	}
+
+	for _, line := range lines[1:] {
+		var entry ManifestEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Failed to unmarshal entry: %v", err)
+		}
+		if entry.Type != "file" || entry.Status != "processed" {
+			t.Errorf("Unexpected manifest entry: %+v", entry)
+		}
+	}
+}
+
+func TestIndexReadsFilesFromStdin(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-stdin")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	oldDir := tempFiles.Dir()
+	tempFiles.SetDir(filepath.Join(dir, "tmp"))
+	defer tempFiles.SetDir(oldDir)
+
+	for _, name := range []string{"a.md", "b.md", "c.txt", "d.log"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("content"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		stdin    bool
+		expected []string
+	}{
+		{"Dash argument", []string{"-"}, false, []string{"a.md", "c.txt"}},
+		{"Stdin flag", nil, true, []string{"a.md", "c.txt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := strings.Join([]string{
+				filepath.Join(dir, "a.md"),
+				"",
+				filepath.Join(dir, "c.txt") + "\r",
+				filepath.Join(dir, "d.log"),   // excluded
+				filepath.Join(dir, "gone.md"), // deleted since listed
+				dir,                           // directories are skipped
+			}, "\n")
+
+			var (
+				mu        sync.Mutex
+				processed []string
+			)
+			ic := &IndexCommand{
+				config:       &CommandConfig{},
+				fromStdin:    tt.stdin,
+				patterns:     []string{"*"},
+				excludePaths: []string{"*.log"},
+				maxWorkers:   2,
+				batchSize:    10,
+				indexType:    "full",
+				stdin:        strings.NewReader(list),
+				processFn: func(ctx context.Context, filePath string, stats *IndexStats) error {
+					mu.Lock()
+					defer mu.Unlock()
+					processed = append(processed, filepath.Base(filePath))
+					return nil
+				},
+
+				indexManifestPath: filepath.Join(dir, "index.json"),
+			}
+
+			captureStdout(t, func() {
+				if err := ic.runIndex(nil, tt.args); err != nil {
+					t.Errorf("runIndex() returned error: %v", err)
+				}
+			})
+
+			sort.Strings(processed)
+			if !reflect.DeepEqual(processed, tt.expected) {
+				t.Errorf("processed = %v, expected %v", processed, tt.expected)
+			}
+			if len(ic.paths) != 0 {
+				t.Errorf("paths = %v, expected no directories to walk", ic.paths)
+			}
+		})
+	}
+}
+
+func TestReadFileList(t *testing.T) {
+	files, err := readFileList(strings.NewReader("a.md\r\n\n  \nsub dir/b.txt\n"))
+	if err != nil {
+		t.Fatalf("readFileList() error = %v", err)
+	}
+
+	expected := []string{"a.md", "sub dir/b.txt"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("readFileList() = %q, expected %q", files, expected)
+	}
+}
+
+func TestIndexCollectFilesDeduplicatesOverlappingPaths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-overlap")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	docs := filepath.Join(dir, "docs")
+	if err := os.Mkdir(docs, 0755); err != nil {
+		t.Fatalf("Failed to create docs dir: %v", err)
+	}
+	for _, path := range []string{filepath.Join(dir, "top.md"), filepath.Join(docs, "a.md"), filepath.Join(docs, "b.md")} {
+		if err := ioutil.WriteFile(path, []byte("content"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	ic := &IndexCommand{
+		config:    &CommandConfig{},
+		paths:     []string{dir, docs, filepath.Join(docs, ".")},
+		recursive: true,
+		maxDepth:  -1,
+		patterns:  []string{"*"},
+		noIgnore:  true,
+	}
+
+	stats := &IndexStats{}
+	files, err := ic.collectFiles(context.Background(), stats)
+	if err != nil {
+		t.Fatalf("collectFiles() error = %v", err)
+	}
+
+	counts := make(map[string]int)
+	for _, file := range files {
+		counts[manifestKey(file)]++
+	}
+	if len(files) != 3 || len(counts) != 3 {
+		t.Errorf("collectFiles() = %v, expected 3 distinct files", files)
+	}
+	if stats.Duplicates != 4 {
+		t.Errorf("Duplicates = %d, expected 4", stats.Duplicates)
+	}
+}
+
+func TestIndexCollectFilesMaxDepth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-depth")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, rel := range []string{"root.md", "a/one.md", "a/b/two.md", "a/b/c/three.md"} {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir for %s: %v", rel, err)
+		}
+		if err := ioutil.WriteFile(path, []byte("content"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", rel, err)
+		}
+	}
+
+	tests := []struct {
+		maxDepth int
+		expected []string
+	}{
+		{0, []string{"root.md"}},
+		{1, []string{"one.md", "root.md"}},
+		{2, []string{"one.md", "root.md", "two.md"}},
+		{-1, []string{"one.md", "root.md", "three.md", "two.md"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("Depth %d", tt.maxDepth), func(t *testing.T) {
+			ic := &IndexCommand{
+				config:    &CommandConfig{},
+				paths:     []string{dir},
+				recursive: true,
+				maxDepth:  tt.maxDepth,
+				patterns:  []string{"*"},
+				noIgnore:  true,
+			}
+
+			files, err := ic.collectFiles(context.Background(), &IndexStats{})
+			if err != nil {
+				t.Fatalf("collectFiles() error = %v", err)
+			}
+
+			var names []string
+			for _, file := range files {
+				names = append(names, filepath.Base(file))
+			}
+			sort.Strings(names)
+
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("collectFiles() = %v, expected %v", names, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIndexRunFullIndexCancellation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-cancel")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for i := 0; i < 50; i++ {
+		if err := ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("file%02d.txt", i)), []byte("content"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int32
+	ic := &IndexCommand{
+		config:     &CommandConfig{},
+		paths:      []string{dir},
+		recursive:  true,
+		maxDepth:   -1,
+		patterns:   []string{"*"},
+		maxWorkers: 2,
+		batchSize:  10,
+		processFn: func(ctx context.Context, filePath string, stats *IndexStats) error {
+			if atomic.AddInt32(&calls, 1) == 15 {
+				cancel()
+			}
+			return nil
+		},
+	}
+
+	stats := &IndexStats{StartTime: time.Now(), FileTypes: make(map[string]int)}
+
+	var runErr error
+	captureStdout(t, func() {
+		runErr = ic.runFullIndex(ctx, stats)
+	})
+
+	if !errors.Is(runErr, context.Canceled) {
+		t.Fatalf("runFullIndex() error = %v, expected %v", runErr, context.Canceled)
+	}
+	if !stats.Cancelled {
+		t.Error("Expected stats to be marked cancelled")
+	}
+	if stats.ProcessedFiles < 15 || stats.ProcessedFiles >= stats.TotalFiles {
+		t.Errorf("ProcessedFiles = %d, expected a partial count of %d files", stats.ProcessedFiles, stats.TotalFiles)
+	}
+	if stats.SkippedFiles != 0 || stats.FailedFiles != 0 {
+		t.Errorf("SkippedFiles = %d, FailedFiles = %d, expected files not reached to count as neither",
+			stats.SkippedFiles, stats.FailedFiles)
+	}
+	if stats.EndTime.IsZero() || stats.Duration <= 0 {
+		t.Errorf("Expected partial timing, got end %v and duration %v", stats.EndTime, stats.Duration)
+	}
+}
+
+func TestIndexBatchProgressLabels(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-batches")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for i := 0; i < 25; i++ {
+		if err := ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("file%02d.txt", i)), []byte("content"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	var (
+		mu     sync.Mutex
+		labels = make(map[string]bool)
+	)
+	ic := &IndexCommand{
+		config:     &CommandConfig{},
+		paths:      []string{dir},
+		recursive:  true,
+		maxDepth:   -1,
+		patterns:   []string{"*"},
+		maxWorkers: 2,
+		batchSize:  10,
+	}
+	ic.processFn = func(ctx context.Context, filePath string, stats *IndexStats) error {
+		// The newest bar in the group belongs to the running batch
+		ic.progress.mu.Lock()
+		label := ic.progress.bars[len(ic.progress.bars)-1].description
+		ic.progress.mu.Unlock()
+
+		mu.Lock()
+		labels[label] = true
+		mu.Unlock()
+		return nil
+	}
+
+	stats := &IndexStats{StartTime: time.Now(), FileTypes: make(map[string]int)}
+	captureStdout(t, func() {
+		if err := ic.runFullIndex(context.Background(), stats); err != nil {
+			t.Errorf("runFullIndex() returned error: %v", err)
+		}
+	})
+
+	expected := map[string]bool{
+		"Processing batch 1/3": true,
+		"Processing batch 2/3": true,
+		"Processing batch 3/3": true,
+	}
+	if !reflect.DeepEqual(labels, expected) {
+		t.Errorf("batch labels = %v, expected %v", labels, expected)
+	}
+}
+
+func TestIndexStatsSeparateSkippedAndFailed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-failed")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for i := 0; i < 10; i++ {
+		if err := ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("file%02d.txt", i)), []byte("content"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	ic := &IndexCommand{
+		config:     &CommandConfig{},
+		paths:      []string{dir},
+		recursive:  true,
+		maxDepth:   -1,
+		patterns:   []string{"*"},
+		maxWorkers: 2,
+		batchSize:  4,
+		processFn: func(ctx context.Context, filePath string, stats *IndexStats) error {
+			switch filepath.Base(filePath) {
+			case "file01.txt", "file05.txt":
+				return errors.New("unreadable")
+			case "file09.txt":
+				return errBinaryFile
+			}
+			return nil
+		},
+	}
+
+	stats := &IndexStats{StartTime: time.Now(), FileTypes: make(map[string]int)}
+	captureStdout(t, func() {
+		if err := ic.runFullIndex(context.Background(), stats); err != nil {
+			t.Errorf("runFullIndex() returned error: %v", err)
+		}
+	})
+
+	if stats.ProcessedFiles != 7 || stats.FailedFiles != 2 || stats.SkippedFiles != 1 {
+		t.Errorf("stats = %d processed, %d failed, %d skipped, expected 7, 2 and 1",
+			stats.ProcessedFiles, stats.FailedFiles, stats.SkippedFiles)
+	}
+
+	if rate := stats.successRate(); math.Abs(rate-700.0/9) > 0.001 {
+		t.Errorf("successRate() = %.3f, expected %.3f", rate, 700.0/9)
+	}
+}
+
+func TestIndexStatsSuccessRate(t *testing.T) {
+	tests := []struct {
+		name      string
+		processed int
+		failed    int
+		expected  float64
+	}{
+		{"All processed", 10, 0, 100},
+		{"Half failed", 5, 5, 50},
+		{"Nothing attempted", 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := &IndexStats{ProcessedFiles: tt.processed, FailedFiles: tt.failed, SkippedFiles: 3}
+			if got := stats.successRate(); got != tt.expected {
+				t.Errorf("successRate() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIndexProgressJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-progress-json")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	const total = 20
+	for i := 0; i < total; i++ {
+		if err := ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("file%02d.md", i)), []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	var events bytes.Buffer
+	ic := &IndexCommand{
+		config:       &CommandConfig{},
+		recursive:    true,
+		maxDepth:     -1,
+		patterns:     []string{"*"},
+		maxWorkers:   1,
+		batchSize:    5,
+		indexType:    "full",
+		progressMode: "json",
+		progressOut:  &events,
+		processFn: func(ctx context.Context, filePath string, stats *IndexStats) error {
+			time.Sleep(40 * time.Millisecond)
+			return nil
+		},
+		indexManifestPath: filepath.Join(dir, "manifest.json"),
+	}
+
+	stdout := captureStdout(t, func() {
+		if err := ic.runIndex(nil, []string{dir}); err != nil {
+			t.Fatalf("runIndex() error = %v", err)
+		}
+	})
+	if strings.Contains(stdout, "\033[") {
+		t.Errorf("stdout has terminal progress with --progress=json: %q", stdout)
+	}
+
+	var overall []ProgressEvent
+	for _, event := range decodeProgressEvents(t, events.Bytes()) {
+		if event.Description == "Indexing files" {
+			overall = append(overall, event)
+		}
+	}
+	if len(overall) < 3 {
+		t.Fatalf("got %d overall progress events, expected periodic updates:\n%s", len(overall), events.String())
+	}
+
+	for i := 1; i < len(overall); i++ {
+		if overall[i].Processed < overall[i-1].Processed {
+			t.Errorf("processed went from %d to %d", overall[i-1].Processed, overall[i].Processed)
+		}
+	}
+	if overall[0].Processed >= overall[len(overall)-1].Processed {
+		t.Errorf("processed did not increase: %+v", overall)
+	}
+
+	last := overall[len(overall)-1]
+	if last.Event != "done" || last.Processed != total || last.Total != total {
+		t.Errorf("last event = %+v, expected done at %d/%d", last, total, total)
+	}
+}
+
+func TestByteProgressReader(t *testing.T) {
+	var buf bytes.Buffer
+	bar := NewBytesProgress("Indexing files", 0).WithWriter(&buf).WithRenderInterval(0)
+	bp := newByteProgress(bar, []collectedFile{
+		{path: "a.txt", size: 10},
+		{path: "b.bin", size: 30},
+	})
+	if bar.total != 40 {
+		t.Fatalf("bar total = %d, expected the 40 bytes of both files", bar.total)
+	}
+	bar.Start()
+
+	// Reads arrive a byte at a time and each advances the bar
+	r := bp.reader("a.txt", iotest.OneByteReader(strings.NewReader("0123456789")))
+	p := make([]byte, 4)
+	last := bar.GetProgress()
+	for i := 0; i < 4; i++ {
+		if _, err := r.Read(p); err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		if got := bar.GetProgress(); got <= last {
+			t.Fatalf("progress after read %d = %v, expected more than %v", i+1, got, last)
+		} else {
+			last = got
+		}
+	}
+	if bar.current != 4 {
+		t.Errorf("bar at %d bytes, expected 4", bar.current)
+	}
+
+	// Finishing a file accounts for what was not read, and nothing more
+	bp.done("a.txt")
+	bp.done("a.txt")
+	if bar.current != 10 {
+		t.Errorf("bar at %d bytes after a.txt, expected 10", bar.current)
+	}
+	bp.done("b.bin")
+	if bar.GetProgress() != 1 {
+		t.Errorf("progress = %v after both files, expected 1", bar.GetProgress())
+	}
+}
+
+func TestIndexProgressBytes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-progress-bytes")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	sizes := map[string]int{"small.md": 1024, "large.md": 3 * 1024}
+	for name, size := range sizes {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), bytes.Repeat([]byte("x"), size), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	ic := &IndexCommand{
+		config:            &CommandConfig{},
+		recursive:         true,
+		maxDepth:          -1,
+		patterns:          []string{"*"},
+		maxWorkers:        1,
+		batchSize:         10,
+		indexType:         "full",
+		progressMode:      "bytes",
+		indexManifestPath: filepath.Join(dir, "manifest.json"),
+	}
+	if err := ic.validateConfig(); err != nil {
+		t.Fatalf("validateConfig() rejected --progress=bytes: %v", err)
+	}
+
+	var progress bytes.Buffer
+	ic.progressOut = &progress
+	captureStdout(t, func() {
+		if err := ic.runIndex(nil, []string{dir}); err != nil {
+			t.Fatalf("runIndex() error = %v", err)
+		}
+	})
+	if !strings.Contains(progress.String(), "4.0 KiB/4.0 KiB") {
+		t.Errorf("Expected the overall bar to count bytes up to 4.0 KiB, got %q", progress.String())
+	}
+	if ic.stats == nil || ic.stats.ProcessedFiles != 2 {
+		t.Errorf("stats = %+v, expected 2 files processed", ic.stats)
+	}
+}
+
+func TestIndexAuditLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-audit")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	root := filepath.Join(dir, "docs")
+	if err := os.MkdirAll(root, 0755); err != nil {
+		t.Fatalf("Failed to create root: %v", err)
+	}
+	outcomes := map[string]string{
+		"a.md":     auditProcessed,
+		"b.md":     auditProcessed,
+		"c.md":     auditProcessed,
+		"bad.md":   auditFailed,
+		"blob.bin": auditSkippedBinary,
+	}
+	for name := range outcomes {
+		if err := ioutil.WriteFile(filepath.Join(root, name), []byte("content of "+name), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	// The log is appended to, keeping earlier runs
+	auditPath := filepath.Join(dir, "audit", "index.jsonl")
+	if err := os.MkdirAll(filepath.Dir(auditPath), 0755); err != nil {
+		t.Fatalf("Failed to create audit dir: %v", err)
+	}
+	if err := ioutil.WriteFile(auditPath, []byte("{\"outcome\":\"earlier\"}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write audit log: %v", err)
+	}
+
+	ic := &IndexCommand{
+		config:       &CommandConfig{},
+		recursive:    true,
+		maxDepth:     -1,
+		patterns:     []string{"*"},
+		maxWorkers:   4,
+		batchSize:    2,
+		indexType:    "full",
+		auditLogPath: auditPath,
+		processFn: func(ctx context.Context, filePath string, stats *IndexStats) error {
+			switch filepath.Base(filePath) {
+			case "bad.md":
+				return errors.New("unreadable")
+			case "blob.bin":
+				return errBinaryFile
+			}
+			return nil
+		},
+		indexManifestPath: filepath.Join(dir, "manifest.json"),
+	}
+
+	captureStdout(t, func() {
+		if err := ic.runIndex(nil, []string{root}); err != nil {
+			t.Fatalf("runIndex() error = %v", err)
+		}
+	})
+
+	data, err := ioutil.ReadFile(auditPath)
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != len(outcomes)+1 || lines[0] != `{"outcome":"earlier"}` {
+		t.Fatalf("audit log = %q, expected the earlier line and one line per file", lines)
+	}
+
+	seen := make(map[string]bool)
+	for _, line := range lines[1:] {
+		var entry AuditEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Failed to parse audit line %q: %v", line, err)
+		}
+
+		name := filepath.Base(entry.Path)
+		if seen[name] {
+			t.Errorf("%s audited twice", name)
+		}
+		seen[name] = true
+
+		if !filepath.IsAbs(entry.Path) {
+			t.Errorf("audit path %q is not absolute", entry.Path)
+		}
+		if entry.Outcome != outcomes[name] {
+			t.Errorf("%s outcome = %q, expected %q", name, entry.Outcome, outcomes[name])
+		}
+		if want := int64(len("content of " + name)); entry.Size != want {
+			t.Errorf("%s size = %d, expected %d", name, entry.Size, want)
+		}
+		if entry.Time.IsZero() {
+			t.Errorf("%s has no timestamp", name)
+		}
+		if entry.Outcome == auditProcessed && (entry.Hash == "" || entry.HashAlgorithm != defaultHashAlgorithm) {
+			t.Errorf("%s hash = %q (%s), expected a %s hash", name, entry.Hash, entry.HashAlgorithm, defaultHashAlgorithm)
+		}
+		if entry.Outcome == auditFailed && entry.Error != "unreadable" {
+			t.Errorf("%s error = %q, expected the processing error", name, entry.Error)
+		}
+	}
+	for name := range outcomes {
+		if !seen[name] {
+			t.Errorf("%s has no audit line", name)
+		}
+	}
+}
+
+func TestIndexPerRootProgress(t *testing.T) {
+	base, err := ioutil.TempDir("", "stroidex-roots")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(base)
+
+	counts := map[string]int{
+		filepath.Join(base, "docs"): 6,
+		filepath.Join(base, "src"):  3,
+	}
+	var roots []string
+	for root, n := range counts {
+		if err := os.MkdirAll(root, 0755); err != nil {
+			t.Fatalf("Failed to create root: %v", err)
+		}
+		for i := 0; i < n; i++ {
+			if err := ioutil.WriteFile(filepath.Join(root, fmt.Sprintf("file%d.md", i)), []byte("x"), 0644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+		}
+		roots = append(roots, root)
+	}
+
+	var events bytes.Buffer
+	ic := &IndexCommand{
+		config:       &CommandConfig{},
+		recursive:    true,
+		maxDepth:     -1,
+		patterns:     []string{"*"},
+		maxWorkers:   1,
+		batchSize:    4,
+		indexType:    "full",
+		progressMode: "json",
+		progressOut:  &events,
+		processFn: func(ctx context.Context, filePath string, stats *IndexStats) error {
+			time.Sleep(30 * time.Millisecond)
+			return nil
+		},
+		indexManifestPath: filepath.Join(base, "manifest.json"),
+	}
+
+	captureStdout(t, func() {
+		if err := ic.runIndex(nil, roots); err != nil {
+			t.Fatalf("runIndex() error = %v", err)
+		}
+	})
+
+	perRoot := make(map[string][]ProgressEvent)
+	for _, event := range decodeProgressEvents(t, events.Bytes()) {
+		if _, ok := counts[event.Description]; ok {
+			perRoot[event.Description] = append(perRoot[event.Description], event)
+		}
+	}
+
+	for root, n := range counts {
+		got := perRoot[root]
+		if len(got) < 2 {
+			t.Fatalf("root %s: got %d events, expected it to advance:\n%s", root, len(got), events.String())
+		}
+		if got[0].Processed != 0 {
+			t.Errorf("root %s: first processed = %d, want 0", root, got[0].Processed)
+		}
+		last := got[len(got)-1]
+		if last.Event != "done" || last.Processed != int64(n) || last.Total != int64(n) {
+			t.Errorf("root %s: last event = %+v, want done with %d/%d", root, last, n, n)
+		}
+	}
+}
+
+func TestIndexSummaryOnly(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-summary-only")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	const total = 12
+	for i := 0; i < total; i++ {
+		if err := ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("file%02d.md", i)), []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	var processed int32
+	ic := &IndexCommand{
+		config:      &CommandConfig{},
+		recursive:   true,
+		maxDepth:    -1,
+		patterns:    []string{"*"},
+		maxWorkers:  3,
+		batchSize:   5,
+		indexType:   "full",
+		summaryOnly: true,
+		processFn: func(ctx context.Context, filePath string, stats *IndexStats) error {
+			atomic.AddInt32(&processed, 1)
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		},
+		indexManifestPath: filepath.Join(dir, "manifest.json"),
+	}
+
+	stdout := captureStdout(t, func() {
+		if err := ic.runIndex(nil, []string{dir}); err != nil {
+			t.Fatalf("runIndex() error = %v", err)
+		}
+	})
+
+	if strings.Contains(stdout, "\r") || strings.Contains(stdout, "\033[") {
+		t.Errorf("stdout has progress frames with --summary-only: %q", stdout)
+	}
+	if !strings.Contains(stdout, "Indexing Summary") {
+		t.Errorf("stdout has no summary with --summary-only: %q", stdout)
+	}
+	if got := atomic.LoadInt32(&processed); got != total {
+		t.Errorf("processed %d files, want %d", got, total)
+	}
+
+	ic.progressMode = "json"
+	if err := ic.validateConfig(); err == nil {
+		t.Error("validateConfig() accepted --summary-only with --progress=json")
+	}
+}
+
+func TestIndexWatchReindexesChanges(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-index-watch")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	oldDir := tempFiles.Dir()
+	tempFiles.SetDir(filepath.Join(dir, "tmp"))
+	defer tempFiles.SetDir(oldDir)
+
+	docs := filepath.Join(dir, "docs")
+	if err := os.Mkdir(docs, 0755); err != nil {
+		t.Fatalf("Failed to create docs dir: %v", err)
+	}
+	a := filepath.Join(docs, "a.md")
+	for _, file := range []string{a, filepath.Join(docs, "b.md")} {
+		if err := ioutil.WriteFile(file, []byte("original"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", file, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	processed := make(chan string, 16)
+	ic := &IndexCommand{
+		config:       &CommandConfig{ctx: ctx},
+		recursive:    true,
+		maxDepth:     -1,
+		patterns:     []string{"*"},
+		excludePaths: []string{"*.tmp"},
+		maxWorkers:   1,
+		batchSize:    10,
+		indexType:    "full",
+		summaryOnly:  true,
+		watch:        true,
+		processFn: func(ctx context.Context, filePath string, stats *IndexStats) error {
+			processed <- filepath.Base(filePath)
+			return nil
+		},
+		indexManifestPath: filepath.Join(dir, "manifest.json"),
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- ic.runIndex(nil, []string{docs})
+	}()
+
+	next := func() string {
+		t.Helper()
+		select {
+		case name := <-processed:
+			return name
+		case err := <-done:
+			t.Fatalf("runIndex() returned early: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a file to be processed")
+		}
+		return ""
+	}
+
+	initial := []string{next(), next()}
+	sort.Strings(initial)
+	if !reflect.DeepEqual(initial, []string{"a.md", "b.md"}) {
+		t.Fatalf("initial pass processed %v, expected a.md and b.md", initial)
+	}
+
+	// Excluded by the same filters as the initial pass
+	if err := ioutil.WriteFile(filepath.Join(docs, "scratch.tmp"), []byte("skip"), 0644); err != nil {
+		t.Fatalf("Failed to write scratch.tmp: %v", err)
+	}
+	if err := ioutil.WriteFile(a, []byte("modified"), 0644); err != nil {
+		t.Fatalf("Failed to modify a.md: %v", err)
+	}
+
+	if name := next(); name != "a.md" {
+		t.Errorf("reprocessed %s, expected a.md", name)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("runIndex() error = %v", err)
+	}
+	close(processed)
+	for name := range processed {
+		t.Errorf("unexpectedly processed %s", name)
+	}
+}
+
+func TestIndexStructuredOutput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-index-structured")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	root := filepath.Join(dir, "docs")
+	if err := os.MkdirAll(root, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	for _, name := range []string{"a.md", "b.md"} {
+		if err := ioutil.WriteFile(filepath.Join(root, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		format    string
+		unmarshal func([]byte, interface{}) error
+	}{
+		{"json", json.Unmarshal},
+		{"ndjson", json.Unmarshal},
+		{"yaml", yaml.Unmarshal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var progress bytes.Buffer
+			ic := &IndexCommand{
+				config:            &CommandConfig{OutputFormat: tt.format},
+				recursive:         true,
+				maxDepth:          -1,
+				patterns:          []string{"*"},
+				maxWorkers:        2,
+				batchSize:         10,
+				indexType:         "full",
+				indexManifestPath: filepath.Join(dir, tt.format+"-manifest.json"),
+				progressOut:       &progress,
+			}
+
+			stdout := captureStdout(t, func() {
+				if err := ic.runIndex(nil, []string{root}); err != nil {
+					t.Fatalf("runIndex() error = %v", err)
+				}
+			})
+
+			var doc map[string]interface{}
+			if err := tt.unmarshal([]byte(stdout), &doc); err != nil {
+				t.Fatalf("stdout is not a single %s document: %v\n%s", tt.format, err, stdout)
+			}
+			if doc["processed_files"] != 2 && doc["processed_files"] != 2.0 {
+				t.Errorf("processed_files = %v, expected 2", doc["processed_files"])
+			}
+			if tt.format == "ndjson" && doc["type"] != "index" {
+				t.Errorf("type = %v, expected index", doc["type"])
+			}
+			if strings.Contains(stdout, "\033") || strings.Contains(stdout, "[INFO]") {
+				t.Errorf("stdout carries progress or messages:\n%q", stdout)
+			}
+			if !strings.Contains(progress.String(), "Indexing files") {
+				t.Errorf("Expected the progress bars on the progress writer, got %q", progress.String())
+			}
+
+			// A rerun with nothing to do still writes a document
+			ic.indexType = "incremental"
+			stdout = captureStdout(t, func() {
+				if err := ic.runIndex(nil, []string{root}); err != nil {
+					t.Fatalf("runIndex() error = %v", err)
+				}
+			})
+			if err := tt.unmarshal([]byte(stdout), &doc); err != nil || doc["unchanged"] == nil {
+				t.Errorf("incremental rerun output = %q, expected an index document", stdout)
+			}
+		})
+	}
+}
+
+func TestIndexKeepsEntriesLeftOutByFilters(t *testing.T) {
+	corpus := []string{"a.md", "b.txt", "docs/c.md"}
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"Pattern", []string{"--pattern", "*.md"}},
+		{"Exclude", []string{"--exclude", "docs/**"}},
+		{"Modified since", []string{"--modified-since", "2999-01-01T00:00:00Z"}},
+		{"Size", []string{"--max-size", "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := indexWorkspace(t, corpus)
+
+			if _, err := runWorkspaceCommand(t, dir, append([]string{"index", "."}, tt.args...)...); err != nil {
+				t.Fatalf("index error = %v", err)
+			}
+			if got := indexedFiles(t, dir); !reflect.DeepEqual(got, corpus) {
+				t.Errorf("manifest = %v after a filtered run, expected %v", got, corpus)
+			}
+
+			// Deleted files still drop out, filtered or not
+			if err := os.Remove(filepath.Join(dir, "b.txt")); err != nil {
+				t.Fatalf("Failed to remove b.txt: %v", err)
+			}
+			if _, err := runWorkspaceCommand(t, dir, append([]string{"index", "."}, tt.args...)...); err != nil {
+				t.Fatalf("index error = %v", err)
+			}
+			expected := []string{"a.md", "docs/c.md"}
+			if got := indexedFiles(t, dir); !reflect.DeepEqual(got, expected) {
+				t.Errorf("manifest = %v after deleting b.txt, expected %v", got, expected)
+			}
+		})
+	}
+}
+
+func TestIndexSkipsStateDir(t *testing.T) {
+	dir := indexWorkspace(t, []string{"a.md"})
+
+	output, err := runWorkspaceCommand(t, dir, "index", ".", "-o", "json")
+	if err != nil {
+		t.Fatalf("index error = %v", err)
+	}
+
+	var stats struct {
+		TotalFiles int `json:"total_files"`
+	}
+	if err := json.Unmarshal([]byte(output), &stats); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, output)
+	}
+	if stats.TotalFiles != 1 {
+		t.Errorf("total_files = %d, expected only a.md besides the state directory", stats.TotalFiles)
+	}
+	if got := indexedFiles(t, dir); !reflect.DeepEqual(got, []string{"a.md"}) {
+		t.Errorf("manifest = %v, expected only a.md", got)
+	}
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+)
+
+// byteReadSize is the chunk size files are read in with --progress=bytes
+const byteReadSize = 32 * 1024
+
+// byteProgress drives the bytes bar of --progress=bytes. Files report
+// bytes as they are read; when a file is done, whatever was not read,
+// e.g. of a skipped binary file, is added so the bar ends at the total.
+type byteProgress struct {
+	bar *ProgressBar
+
+	mu    sync.Mutex
+	sizes map[string]int64
+	read  map[string]int64
+}
+
+// newByteProgress creates the byte progress of files on bar, sizing the
+// bar to their total
+func newByteProgress(bar *ProgressBar, files []collectedFile) *byteProgress {
+	bp := &byteProgress{
+		bar:   bar,
+		sizes: make(map[string]int64, len(files)),
+		read:  make(map[string]int64),
+	}
+
+	var total int64
+	for _, file := range files {
+		bp.sizes[file.path] = file.size
+		total += file.size
+	}
+	bar.SetTotal(total)
+	return bp
+}
+
+// add reports n more bytes read of filePath, up to its collected size
+func (bp *byteProgress) add(filePath string, n int64) {
+	bp.mu.Lock()
+	if remaining := bp.sizes[filePath] - bp.read[filePath]; n > remaining {
+		n = remaining
+	}
+	if n <= 0 {
+		bp.mu.Unlock()
+		return
+	}
+	bp.read[filePath] += n
+	bp.mu.Unlock()
+
+	bp.bar.Add(n)
+}
+
+// done accounts for the bytes of filePath that were not read
+func (bp *byteProgress) done(filePath string) {
+	bp.mu.Lock()
+	n := bp.sizes[filePath] - bp.read[filePath]
+	bp.mu.Unlock()
+
+	bp.add(filePath, n)
+}
+
+// reader returns r reporting the bytes read from it as bytes of filePath
+func (bp *byteProgress) reader(filePath string, r io.Reader) io.Reader {
+	return &progressReader{r: r, report: func(n int64) { bp.add(filePath, n) }}
+}
+
+// readFile reads filePath to the end, reporting progress as it goes and
+// stopping early when ctx is done
+func (bp *byteProgress) readFile(ctx context.Context, filePath string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bp.reader(filePath, f)
+	buf := make([]byte, byteReadSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := r.Read(buf); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// progressReader passes reads through to r, reporting how many bytes
+// each returned
+type progressReader struct {
+	r      io.Reader
+	report func(n int64)
+}
+
+// Read reads from the underlying reader and reports the bytes read
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	if n > 0 {
+		pr.report(int64(n))
+	}
+	return n, err
+}
@@ -0,0 +1,254 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// indexManifestFile is the persistent index manifest inside the state
+	// directory
+	indexManifestFile = "manifest.json"
+
+	// indexManifestVersion is the current manifest format version
+	indexManifestVersion = 1
+)
+
+// IndexManifestEntry describes a file as it was when last indexed.
+// HashAlgorithm is empty for entries hashed with legacyHashAlgorithm.
+type IndexManifestEntry struct {
+	Size          int64     `json:"size"`
+	ModTime       time.Time `json:"mod_time"`
+	Hash          string    `json:"hash"`
+	HashAlgorithm string    `json:"hash_algorithm,omitempty"`
+}
+
+// hashAlgorithm returns the algorithm the entry's hash was computed with
+func (e IndexManifestEntry) hashAlgorithm() string {
+	if e.HashAlgorithm == "" {
+		return legacyHashAlgorithm
+	}
+	return e.HashAlgorithm
+}
+
+// IndexManifest is the persistent record of indexed files that lets
+// incremental runs skip files that have not changed. Unlike a
+// RunManifest, which logs a single run, it accumulates across runs.
+type IndexManifest struct {
+	mu        sync.Mutex
+	path      string
+	seen      map[string]bool
+	algorithm string
+	Version   int                           `json:"version"`
+	UpdatedAt time.Time                     `json:"updated_at"`
+	Files     map[string]IndexManifestEntry `json:"files"`
+}
+
+// defaultIndexManifestPath returns the manifest path inside the state
+// directory
+func defaultIndexManifestPath() string {
+	return filepath.Join(stateDirName, indexManifestFile)
+}
+
+// NewIndexManifest creates an empty index manifest that saves to path
+func NewIndexManifest(path string) *IndexManifest {
+	return &IndexManifest{
+		path:      path,
+		seen:      make(map[string]bool),
+		algorithm: defaultHashAlgorithm,
+		Version:   indexManifestVersion,
+		Files:     make(map[string]IndexManifestEntry),
+	}
+}
+
+// LoadIndexManifest reads the index manifest at path. A missing file
+// yields an empty manifest.
+func LoadIndexManifest(path string) (*IndexManifest, error) {
+	m := NewIndexManifest(path)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+		return nil, fmt.Errorf("failed to read index manifest: %w", err)
+	}
+
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to parse index manifest %s: %w", path, err)
+	}
+	if m.Version != indexManifestVersion {
+		return nil, fmt.Errorf("unsupported index manifest version %d in %s", m.Version, path)
+	}
+	if m.Files == nil {
+		m.Files = make(map[string]IndexManifestEntry)
+	}
+
+	return m, nil
+}
+
+// manifestKey returns the key a file is stored under
+func manifestKey(filePath string) string {
+	if abs, err := filepath.Abs(filePath); err == nil {
+		return abs
+	}
+	return filepath.Clean(filePath)
+}
+
+// SetHashAlgorithm sets the algorithm Record hashes files with
+func (m *IndexManifest) SetHashAlgorithm(algorithm string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.algorithm = algorithm
+}
+
+// Entry returns the recorded entry of a file
+func (m *IndexManifest) Entry(filePath string) (IndexManifestEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.Files[manifestKey(filePath)]
+	return entry, ok
+}
+
+// Seen marks a file as present in the tree being indexed
+func (m *IndexManifest) Seen(filePath string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.seen[manifestKey(filePath)] = true
+}
+
+// Unchanged reports whether the file matches its manifest entry by size
+// and modification time
+func (m *IndexManifest) Unchanged(filePath string, info os.FileInfo) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.Files[manifestKey(filePath)]
+	return ok && entry.Size == info.Size() && entry.ModTime.Equal(info.ModTime())
+}
+
+// ContentUnchanged reports whether the file has the size and content
+// hash of its manifest entry. Unlike Unchanged it reads the file, so a
+// change that kept the modification time is caught and a file that was
+// only touched is not reported. Entries hashed with another algorithm
+// count as changed.
+func (m *IndexManifest) ContentUnchanged(filePath string, info os.FileInfo) bool {
+	m.mu.Lock()
+	entry, ok := m.Files[manifestKey(filePath)]
+	algorithm := m.algorithm
+	m.mu.Unlock()
+
+	if !ok || entry.Size != info.Size() || entry.hashAlgorithm() != algorithm {
+		return false
+	}
+
+	hash, err := hashFile(filePath, algorithm)
+	return err == nil && hash == entry.Hash
+}
+
+// Record hashes the file and stores its current size, modification time
+// and hash
+func (m *IndexManifest) Record(filePath string) error {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	algorithm := m.algorithm
+	m.mu.Unlock()
+
+	hash, err := hashFile(filePath, algorithm)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Files[manifestKey(filePath)] = IndexManifestEntry{
+		Size:          info.Size(),
+		ModTime:       info.ModTime(),
+		Hash:          hash,
+		HashAlgorithm: algorithm,
+	}
+	return nil
+}
+
+// Prune removes entries below any of roots whose files were deleted
+// since the last run. Entries not marked Seen are only removed once the
+// file is confirmed gone, as a run with narrower filters does not see
+// every indexed file.
+func (m *IndexManifest) Prune(roots []string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	removed := 0
+	for key := range m.Files {
+		if m.seen[key] {
+			continue
+		}
+		for _, root := range roots {
+			root = manifestKey(root)
+			if key != root && !strings.HasPrefix(key, root+string(filepath.Separator)) {
+				continue
+			}
+			if _, err := os.Lstat(key); os.IsNotExist(err) {
+				delete(m.Files, key)
+				removed++
+			}
+			break
+		}
+	}
+	return removed
+}
+
+// Remove deletes the entries for the given manifest keys and returns how
+// many were present
+func (m *IndexManifest) Remove(keys ...string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	removed := 0
+	for _, key := range keys {
+		if _, ok := m.Files[key]; ok {
+			delete(m.Files, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Len returns the number of files in the manifest
+func (m *IndexManifest) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.Files)
+}
+
+// Save writes the manifest atomically
+func (m *IndexManifest) Save() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal index manifest: %w", err)
+	}
+
+	if err := writeFileAtomic(m.path, data); err != nil {
+		return fmt.Errorf("failed to save index manifest: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrorList is a list of errors that serializes as a list of messages
+type ErrorList []error
+
+// Strings returns the error messages
+func (el ErrorList) Strings() []string {
+	messages := make([]string, len(el))
+	for i, err := range el {
+		messages[i] = err.Error()
+	}
+	return messages
+}
+
+// MarshalJSON encodes the errors as a JSON array of strings
+func (el ErrorList) MarshalJSON() ([]byte, error) {
+	return json.Marshal(el.Strings())
+}
+
+// UnmarshalJSON decodes a JSON array of strings into errors
+func (el *ErrorList) UnmarshalJSON(data []byte) error {
+	var messages []string
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return err
+	}
+	*el = errorsFromStrings(messages)
+	return nil
+}
+
+// MarshalYAML encodes the errors as a YAML sequence of strings
+func (el ErrorList) MarshalYAML() (interface{}, error) {
+	return el.Strings(), nil
+}
+
+// UnmarshalYAML decodes a YAML sequence of strings into errors
+func (el *ErrorList) UnmarshalYAML(node *yaml.Node) error {
+	var messages []string
+	if err := node.Decode(&messages); err != nil {
+		return err
+	}
+	*el = errorsFromStrings(messages)
+	return nil
+}
+
+// errorsFromStrings turns messages back into errors
+func errorsFromStrings(messages []string) ErrorList {
+	el := make(ErrorList, len(messages))
+	for i, msg := range messages {
+		el[i] = errors.New(msg)
+	}
+	return el
+}
+
+// writeIndexReport writes stats to path as YAML for the yaml output
+// format and as JSON otherwise
+func writeIndexReport(path, format string, stats *IndexStats) error {
+	var (
+		data []byte
+		err  error
+	)
+
+	if format == "yaml" {
+		data, err = yaml.Marshal(stats)
+	} else {
+		data, err = json.MarshalIndent(stats, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal index report: %w", err)
+	}
+
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write index report: %w", err)
+	}
+	return nil
+}
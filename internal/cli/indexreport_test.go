@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestIndexReportRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-report")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	stats := &IndexStats{
+		TotalFiles:     10,
+		ProcessedFiles: 8,
+		SkippedFiles:   2,
+		SkippedBySize:  1,
+		Errors:         ErrorList{errors.New("error processing a.bin: unreadable"), errors.New("error processing b.bin: denied")},
+		Duration:       1500 * time.Millisecond,
+		StartTime:      start,
+		EndTime:        start.Add(1500 * time.Millisecond),
+		FileTypes:      map[string]int{".md": 5, ".txt": 3},
+	}
+
+	tests := []struct {
+		format    string
+		unmarshal func([]byte, interface{}) error
+	}{
+		{"json", json.Unmarshal},
+		{"yaml", yaml.Unmarshal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			path := filepath.Join(dir, "report."+tt.format)
+			if err := writeIndexReport(path, tt.format, stats); err != nil {
+				t.Fatalf("writeIndexReport() returned error: %v", err)
+			}
+
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read report: %v", err)
+			}
+			if !strings.Contains(string(data), "b.bin: denied") {
+				t.Errorf("Report does not contain error messages:\n%s", data)
+			}
+
+			var got IndexStats
+			if err := tt.unmarshal(data, &got); err != nil {
+				t.Fatalf("Failed to unmarshal %s report: %v", tt.format, err)
+			}
+
+			if !reflect.DeepEqual(got.Errors.Strings(), stats.Errors.Strings()) {
+				t.Errorf("Errors = %v, expected %v", got.Errors.Strings(), stats.Errors.Strings())
+			}
+
+			got.Errors, got.StartTime, got.EndTime = stats.Errors, stats.StartTime, stats.EndTime
+			if !reflect.DeepEqual(&got, stats) {
+				t.Errorf("Round-tripped report = %+v, expected %+v", got, *stats)
+			}
+		})
+	}
+}
+
+func TestIndexReportJSONErrorsAreStrings(t *testing.T) {
+	data, err := json.Marshal(&IndexStats{Errors: ErrorList{errors.New("boom")}})
+	if err != nil {
+		t.Fatalf("json.Marshal() returned error: %v", err)
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	if !reflect.DeepEqual(raw["errors"], []interface{}{"boom"}) {
+		t.Errorf("errors = %#v, expected [\"boom\"]", raw["errors"])
+	}
+}
@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// indexWatchDebounce coalesces the events of a file being saved before
+// index --watch reindexes it
+const indexWatchDebounce = 200 * time.Millisecond
+
+// startWatch watches the indexed paths for index --watch. It runs before
+// the initial pass so changes made meanwhile are queued rather than
+// missed. Filtering is left to acceptChange, which applies the index
+// filters rather than the monitor's.
+func (ic *IndexCommand) startWatch(ctx context.Context) (*MonitorCommand, error) {
+	mc := &MonitorCommand{
+		config:         ic.config,
+		paths:          ic.paths,
+		recursive:      ic.recursive,
+		maxDepth:       ic.maxDepth,
+		patterns:       []string{"*"},
+		debounceWindow: indexWatchDebounce,
+	}
+	if err := mc.startWatching(); err != nil {
+		return nil, err
+	}
+	mc.events = mc.debounce(ctx, mc.watch(ctx))
+	return mc, nil
+}
+
+// watchChanges reindexes files as they change until ctx is done,
+// updating the index manifest after each burst of changes
+func (ic *IndexCommand) watchChanges(ctx context.Context, mc *MonitorCommand) error {
+	// The run manifest and checkpoint describe the initial pass only
+	ic.manifest, ic.checkpoint = nil, nil
+
+	if !isStructuredFormat(ic.config.OutputFormat) {
+		PrintInfo("Watching for changes; press Ctrl+C to stop")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-mc.events:
+			if !ok {
+				return nil
+			}
+			if err := ic.reindex(ctx, mc, mc.pendingEvents(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// reindex processes a burst of changes: removed files leave the index
+// and changed files that pass the filters are indexed again
+func (ic *IndexCommand) reindex(ctx context.Context, mc *MonitorCommand, events []FileEvent) error {
+	stats := &IndexStats{
+		StartTime: time.Now(),
+		FileTypes: make(map[string]int),
+	}
+
+	var files, removed []string
+	seen := make(map[string]bool)
+	for _, event := range events {
+		if event.Op == "remove" || (event.Op == "rename" && event.OldPath == "") {
+			removed = append(removed, manifestKey(event.Path))
+			continue
+		}
+		if event.OldPath != "" {
+			removed = append(removed, manifestKey(event.OldPath))
+		}
+		if isDuplicate(seen, event.Path, stats) {
+			continue
+		}
+		if ic.acceptChange(mc.rootOf(event.Path), event.Path, stats) {
+			files = append(files, event.Path)
+		}
+	}
+
+	removedCount := ic.index.Remove(removed...)
+	processed, errs := 0, []error(nil)
+	if len(files) > 0 {
+		processed, errs = ic.processBatch(ctx, files, 1, 1, stats)
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	if removedCount == 0 && len(files) == 0 {
+		return nil
+	}
+
+	if err := ic.index.Save(); err != nil {
+		return err
+	}
+
+	if !isStructuredFormat(ic.config.OutputFormat) {
+		if processed > 0 {
+			PrintSuccess(fmt.Sprintf("Reindexed %s file(s)", humanizeInt(int64(processed))))
+		}
+		if removedCount > 0 {
+			PrintInfo(fmt.Sprintf("Removed %s file(s) from the index", humanizeInt(int64(removedCount))))
+		}
+		for _, err := range errs {
+			PrintWarning(err.Error())
+		}
+	}
+	return nil
+}
+
+// acceptChange applies the filters of the initial walk to a changed file
+// below root: --exclude-dir and the ignore files for its directories,
+// then those of acceptFile
+func (ic *IndexCommand) acceptChange(root, filePath string, stats *IndexStats) bool {
+	info, err := os.Stat(filePath)
+	if err != nil || info.IsDir() {
+		return false
+	}
+
+	rel := relativePath(root, filePath)
+	ignore := ic.newIgnoreMatcher(root)
+	matcher := ic.pathMatcher(ignore)
+
+	// Check the directories from the root down, as the walk would
+	var dirs []string
+	for dir := filepath.Dir(rel); dir != "." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+	}
+	for _, dir := range dirs {
+		if matcher.ExcludesDir(dir) || matcher.Ignored(dir, true) {
+			return false
+		}
+		if ignore != nil {
+			ic.addIgnoreFile(ignore, filepath.Join(root, dir, gitIgnoreFile), dir)
+		}
+	}
+	if matcher.Ignored(rel, false) {
+		return false
+	}
+
+	return ic.acceptFile(filePath, rel, info, stats)
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// logLevel is the level of the package logger, set from --log-level
+var logLevel = new(slog.LevelVar)
+
+// logger writes leveled diagnostics to stderr, as key=value text
+var logger = newLogger(os.Stderr)
+
+// logLevels maps --log-level values to slog levels
+var logLevels = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"warn":  slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
+// newLogger creates a logger writing to w at the package log level
+func newLogger(w io.Writer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: logLevel}))
+}
+
+// parseLogLevel returns the slog level for a --log-level value
+func parseLogLevel(name string) (slog.Level, error) {
+	level, ok := logLevels[name]
+	if !ok {
+		return 0, fmt.Errorf("log level must be one of debug, info, warn, error, got: %s", name)
+	}
+	return level, nil
+}
+
+// setupLogging sets the log level from config. Verbose mode lowers it to
+// debug and quiet mode raises it to error.
+func setupLogging(config *CommandConfig) error {
+	level := slog.LevelInfo
+	if config.LogLevel != "" {
+		parsed, err := parseLogLevel(config.LogLevel)
+		if err != nil {
+			return err
+		}
+		level = parsed
+	}
+
+	switch {
+	case config.Verbose && level > slog.LevelDebug:
+		level = slog.LevelDebug
+	case config.Quiet && level < slog.LevelError:
+		level = slog.LevelError
+	}
+
+	logLevel.Set(level)
+	return nil
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// captureLog redirects the package logger to a buffer for the test
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	previous, level := logger, logLevel.Level()
+	logger = newLogger(&buf)
+	t.Cleanup(func() {
+		logger = previous
+		logLevel.Set(level)
+	})
+	return &buf
+}
+
+func TestParseLogLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected slog.Level
+		wantErr  bool
+	}{
+		{"debug", slog.LevelDebug, false},
+		{"info", slog.LevelInfo, false},
+		{"warn", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"trace", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, err := parseLogLevel(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseLogLevel(%s) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if !tt.wantErr && level != tt.expected {
+				t.Errorf("parseLogLevel(%s) = %v, expected %v", tt.name, level, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoggingLevels(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    *CommandConfig
+		wantDebug bool
+		wantInfo  bool
+	}{
+		{"Info level", &CommandConfig{LogLevel: "info"}, false, true},
+		{"Debug level", &CommandConfig{LogLevel: "debug"}, true, true},
+		{"Error level", &CommandConfig{LogLevel: "error"}, false, false},
+		{"Verbose implies debug", &CommandConfig{LogLevel: "warn", Verbose: true}, true, true},
+		{"Quiet implies error", &CommandConfig{LogLevel: "info", Quiet: true}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureLog(t)
+			if err := setupLogging(tt.config); err != nil {
+				t.Fatalf("setupLogging() error = %v", err)
+			}
+
+			logger.Debug("scanning for changes")
+			logger.Info("watching", "path", "docs")
+
+			if got := strings.Contains(buf.String(), "level=DEBUG msg=\"scanning for changes\""); got != tt.wantDebug {
+				t.Errorf("debug message logged = %v, expected %v: %q", got, tt.wantDebug, buf.String())
+			}
+			if got := strings.Contains(buf.String(), "level=INFO msg=watching path=docs"); got != tt.wantInfo {
+				t.Errorf("info message logged = %v, expected %v: %q", got, tt.wantInfo, buf.String())
+			}
+		})
+	}
+}
+
+func TestSetupLoggingInvalidLevel(t *testing.T) {
+	captureLog(t)
+	if err := setupLogging(&CommandConfig{LogLevel: "loud"}); err == nil {
+		t.Error("setupLogging() with an invalid level should fail")
+	}
+}
+
+func TestMonitorProcessEventsLogsDebug(t *testing.T) {
+	buf := captureLog(t)
+	logLevel.Set(slog.LevelDebug)
+
+	mc := &MonitorCommand{config: &CommandConfig{}}
+	if err := mc.processEvents(context.Background(), []FileEvent{{Path: "a.md", Op: "write"}}); err != nil {
+		t.Fatalf("processEvents() error = %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "msg=\"processing change\" path=a.md op=write") {
+		t.Errorf("log = %q, expected a debug record for the change", buf.String())
+	}
+}
@@ -3,31 +3,111 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/cobra"
 )
 
+// defaultShutdownTimeout bounds how long a graceful shutdown waits for
+// events to be processed
+const defaultShutdownTimeout = 10 * time.Second
+
 // MonitorCommand represents the monitor command configuration
 type MonitorCommand struct {
-	config     *CommandConfig
-	paths      []string
-	recursive  bool
-	interval   time.Duration
-	daemon     bool
-	statsOnly  bool
-	followMode bool
-	patterns   []string
+	config      *CommandConfig
+	paths       []string
+	recursive   bool
+	maxDepth    int
+	interval    time.Duration
+	daemon      bool
+	statsOnly   bool
+	polling     bool
+	followMode  bool
+	patterns    []string
+	exclude     []string
+	statWorkers int
+
+	// includeFrom and excludeFrom name files of further patterns
+	includeFrom string
+	excludeFrom string
+
+	debounceWindow time.Duration
+	renameWindow   time.Duration
+	execCommand    string
+	execDebounce   time.Duration
+	runner         *changeRunner
+	pidFile        string
+
+	// once compares a single snapshot with the baseline stored at
+	// baselinePath, or the default in the state directory, and exits
+	once         bool
+	baselinePath string
+
+	// initialIndex indexes the files already present before monitoring;
+	// initialIndexed counts the files it processed
+	initialIndex   bool
+	initialIndexed int
+
+	// httpAddr is the --http address of the health and metrics server
+	httpAddr     string
+	server       *http.Server
+	httpListener net.Listener
+
+	// statsInterval is how often daemon mode logs a stats line; zero
+	// disables it
+	statsInterval time.Duration
+
+	// startTime is when monitoring started; eventsProcessed counts the
+	// file events handled since, updated atomically
+	startTime       time.Time
+	eventsProcessed int64
+
+	// indexFn replaces the processing of initially indexed files when set,
+	// e.g. in tests
+	indexFn func(ctx context.Context, filePath string, stats *IndexStats) error
+
+	watcher *fsnotify.Watcher
+	events  <-chan FileEvent
+
+	// stopEvents stops the watcher or poller feeding events, which then
+	// closes once pending debounced events are delivered
+	stopEvents context.CancelFunc
+
+	// inFlight tracks processEvents calls for a graceful shutdown, which
+	// waits up to shutdownTimeout, or defaultShutdownTimeout when zero,
+	// for them
+	inFlight        sync.WaitGroup
+	shutdownTimeout time.Duration
+
+	// handleFn is called for each processed event when set, e.g. in tests
+	handleFn func(ctx context.Context, event FileEvent)
+
+	// stream receives a record per event in stream mode, including
+	// the events drained on shutdown. streamMu guards it against a
+	// drain that outlives a timed out shutdown.
+	streamMu sync.Mutex
+	stream   Renderer
 }
 
 // NewMonitorCommand creates a new monitor command
 func NewMonitorCommand(config *CommandConfig) *cobra.Command {
 	mc := &MonitorCommand{
-		config: config,
+		config:          config,
+		statWorkers:     4, // default number of stat workers
+		maxDepth:        -1,
+		shutdownTimeout: defaultShutdownTimeout,
+		renameWindow:    defaultRenameWindow,
 	}
 
 	cmd := &cobra.Command{
@@ -39,21 +119,79 @@ triggers document indexing when changes are detected.
 Examples:
   stroidex monitor ./docs                    # Monitor docs directory
   stroidex monitor ./src ./docs -r           # Monitor recursively
+  stroidex monitor . -r --max-depth 2        # Monitor two levels below the path
   stroidex monitor . --interval 5s           # Check every 5 seconds
+  stroidex monitor /mnt/share -r --poll      # Poll where file events are unreliable
   stroidex monitor . --daemon                # Run as daemon
+  stroidex monitor . --daemon --pid-file .stroidex/monitor.pid &
+  stroidex monitor stop --pid-file .stroidex/monitor.pid   # Stop it again
+  stroidex monitor . --debounce 500ms        # Wait for editors to finish saving
+  stroidex monitor . -o json | jq .path      # Stream events as NDJSON
+  stroidex monitor . -r --exec "make build" --exec-debounce 1s  # Rebuild on change
   stroidex monitor . --stats-only           # Show stats only
-  stroidex monitor . --pattern "*.md,*.txt"  # Monitor specific file patterns`,
+  stroidex monitor . -r --once               # Report changes since the last --once run, e.g. from cron
+  stroidex monitor . -r --initial-index      # Index existing files, then watch
+  stroidex monitor . --daemon --http :9090   # Serve /healthz, /metrics and /status
+  stroidex monitor . --daemon --stats-interval 1m  # Log activity every minute
+  stroidex monitor . --pattern "*.md,*.txt"  # Monitor specific file patterns
+  stroidex monitor . -r --exclude ".git/**,*.o"  # Ignore VCS data and build output
+  stroidex monitor . -r --stats-only --stat-workers 16  # Parallel stats walk`,
 		Args: cobra.ArbitraryArgs,
 		RunE: mc.runMonitor,
 	}
 
 	// Add monitor-specific flags
 	cmd.Flags().BoolVarP(&mc.recursive, "recursive", "r", false, "Monitor directories recursively")
-	cmd.Flags().DurationVarP(&mc.interval, "interval", "i", time.Second*10, "Monitoring interval (e.g., 1s, 1m, 1h)")
+	cmd.Flags().IntVar(&mc.maxDepth, "max-depth", -1, "Maximum directory depth below each path to descend into (0 = files directly in the path, negative = unlimited)")
+	cmd.Flags().DurationVarP(&mc.interval, "interval", "i", time.Second*10, "Interval between periodic scans in daemon mode and between --poll snapshots (e.g., 1s, 1m, 1h)")
 	cmd.Flags().BoolVar(&mc.daemon, "daemon", false, "Run as daemon process")
+	cmd.Flags().BoolVar(&mc.polling, "poll", false, "Detect changes by comparing snapshots every --interval instead of watching for file events")
 	cmd.Flags().BoolVar(&mc.statsOnly, "stats-only", false, "Show monitoring statistics without processing")
 	cmd.Flags().BoolVarP(&mc.followMode, "follow", "f", false, "Follow file changes in real-time")
 	cmd.Flags().StringSliceVarP(&mc.patterns, "pattern", "p", []string{"*"}, "File patterns to monitor (comma-separated)")
+	cmd.Flags().StringSliceVarP(&mc.exclude, "exclude", "e", []string{}, "Exclude patterns (comma-separated)")
+	cmd.Flags().StringVar(&mc.includeFrom, "include-from", "", "Read further file patterns to monitor from this file, one per line (# starts a comment)")
+	cmd.Flags().StringVar(&mc.excludeFrom, "exclude-from", "", "Read further exclude patterns from this file, one per line (# starts a comment)")
+	cmd.Flags().DurationVar(&mc.debounceWindow, "debounce", 100*time.Millisecond, "Coalesce events for the same path until it has been quiet this long (0 disables)")
+	cmd.Flags().DurationVar(&mc.renameWindow, "rename-window", defaultRenameWindow, "Report a file removed and one with the same size and modification time created within this long as a single rename (0 disables)")
+	cmd.Flags().StringVar(&mc.execCommand, "exec", "", "Run a shell command for each change; {} is replaced with the changed path")
+	cmd.Flags().DurationVar(&mc.execDebounce, "exec-debounce", 0, "Run --exec once per burst of changes, for the last changed path, after this quiet period")
+	cmd.Flags().IntVar(&mc.statWorkers, "stat-workers", 4, "Number of concurrent workers for the --stats-only walk")
+	cmd.Flags().StringVar(&mc.pidFile, "pid-file", "", "Write the process ID to this file while monitoring")
+	cmd.Flags().DurationVar(&mc.shutdownTimeout, "shutdown-timeout", defaultShutdownTimeout, "On SIGINT or SIGTERM, wait this long for queued and in-progress events to be processed")
+	cmd.Flags().DurationVar(&mc.statsInterval, "stats-interval", 0, "In daemon mode, log a stats line this often, e.g. 1m (0 disables)")
+	cmd.Flags().StringVar(&mc.httpAddr, "http", "", "Serve /healthz, /metrics (Prometheus) and /status (JSON) on this address, e.g. :9090")
+	cmd.Flags().BoolVar(&mc.once, "once", false, "Report changes since the previous --once run and exit; the exit status is 2 when there were any")
+	cmd.Flags().BoolVar(&mc.initialIndex, "initial-index", false, "Index existing files that changed since the last index run before watching for changes")
+
+	cmd.AddCommand(newMonitorStopCommand())
+
+	return cmd
+}
+
+// newMonitorStopCommand creates the command stopping a monitor started
+// with --pid-file
+func newMonitorStopCommand() *cobra.Command {
+	var pidFile string
+
+	cmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop a running monitor",
+		Long: `Stop sends SIGTERM to the monitor whose process ID is recorded in the
+given PID file. The monitor shuts down gracefully and removes the file.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pid, err := stopProcess(pidFile)
+			if err != nil {
+				return err
+			}
+			PrintSuccess(fmt.Sprintf("Sent stop signal to monitor (pid %d)", pid))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&pidFile, "pid-file", "", "PID file written by monitor --pid-file")
+	_ = cmd.MarkFlagRequired("pid-file")
 
 	return cmd
 }
@@ -66,6 +204,7 @@ func (mc *MonitorCommand) runMonitor(cmd *cobra.Command, args []string) error {
 	} else {
 		mc.paths = args
 	}
+	mc.paths = mc.config.resolvePaths(mc.paths)
 
 	// Validate paths
 	for _, path := range mc.paths {
@@ -74,24 +213,120 @@ func (mc *MonitorCommand) runMonitor(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if err := mc.loadPatternFiles(); err != nil {
+		return err
+	}
+
+	// Events are a stream, not metrics; /metrics of --http serves those
+	if mc.config.OutputFormat == "prometheus" {
+		return fmt.Errorf("prometheus output is not supported for monitor")
+	}
+	if mc.polling && mc.interval <= 0 {
+		return fmt.Errorf("--interval must be positive with --poll")
+	}
+	if mc.statsInterval < 0 {
+		return fmt.Errorf("--stats-interval must not be negative")
+	}
+	if mc.shutdownTimeout < 0 {
+		return fmt.Errorf("--shutdown-timeout must not be negative")
+	}
+	if mc.once && (mc.daemon || mc.httpAddr != "") {
+		return fmt.Errorf("--once cannot be combined with --daemon or --http")
+	}
+
 	// Setup context for graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(mc.config.Context())
 	defer cancel()
 
 	// Setup signal handling
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer tempFiles.HandleShutdown()()
 
 	// Start monitoring
-	PrintInfo(fmt.Sprintf("Starting monitoring on %d path(s)", len(mc.paths)))
-	for _, path := range mc.paths {
-		absPath, _ := filepath.Abs(path)
-		PrintInfo(fmt.Sprintf("Watching: %s (recursive: %v)", absPath, mc.recursive))
+	if !isStructuredFormat(mc.config.OutputFormat) {
+		PrintInfo(fmt.Sprintf("Starting monitoring on %d path(s)", len(mc.paths)))
+		for _, path := range mc.paths {
+			absPath, _ := filepath.Abs(path)
+			PrintInfo(fmt.Sprintf("Watching: %s (recursive: %v)", absPath, mc.recursive))
+		}
 	}
 
 	if mc.statsOnly {
 		return mc.runStatsMode(ctx)
 	}
+	if mc.once {
+		// Finding changes is reported through the exit status, not
+		// as a usage error
+		if cmd != nil {
+			cmd.SilenceUsage = true
+		}
+		return mc.runOnce(ctx)
+	}
+
+	// The event source stops on its own context so a graceful shutdown
+	// can end it while the debouncer still delivers what is pending
+	sourceCtx, stopEvents := context.WithCancel(ctx)
+	defer stopEvents()
+	mc.stopEvents = stopEvents
+
+	// Renames and debouncing outlive ctx so a shutdown after it is done
+	// still delivers what they hold; they end when monitoring returns
+	stageCtx, stopStages := context.WithCancel(context.WithoutCancel(ctx))
+	defer stopStages()
+
+	if mc.polling {
+		mc.events = mc.poll(sourceCtx)
+	} else {
+		if err := mc.startWatching(); err != nil {
+			return err
+		}
+		defer mc.watcher.Close()
+		mc.events = mc.watch(sourceCtx)
+		if mc.renameWindow > 0 {
+			mc.events = mc.detectRenames(stageCtx, mc.events)
+		}
+	}
+	if mc.debounceWindow > 0 {
+		mc.events = mc.debounce(stageCtx, mc.events)
+	}
+
+	// Index after the watch starts so changes made meanwhile are queued
+	// rather than missed
+	mc.startTime = time.Now()
+	if mc.initialIndex {
+		if err := mc.runInitialIndex(args); err != nil {
+			return err
+		}
+	}
+
+	if mc.httpAddr != "" {
+		if err := mc.startHTTPServer(); err != nil {
+			return err
+		}
+		defer mc.stopHTTPServer()
+		if !isStructuredFormat(mc.config.OutputFormat) {
+			PrintInfo(fmt.Sprintf("Serving health and metrics on http://%s", mc.httpListener.Addr()))
+		}
+	}
+
+	if mc.pidFile != "" {
+		if err := writePIDFile(mc.pidFile); err != nil {
+			return err
+		}
+		defer mc.removePIDFile()
+	}
+
+	// Structured formats stream events instead of human messages
+	renderer := NewStreamRenderer(mc.config.OutputFormat, mc.config.Stdout())
+	if mc.execCommand != "" {
+		mc.runner = mc.newExecRunner(renderer != nil)
+		defer mc.runner.Stop()
+	}
+
+	if renderer != nil {
+		return mc.runStreamMode(ctx, sigChan, renderer)
+	}
 
 	if mc.daemon {
 		return mc.runDaemonMode(ctx, sigChan)
@@ -100,14 +335,123 @@ func (mc *MonitorCommand) runMonitor(cmd *cobra.Command, args []string) error {
 	return mc.runInteractiveMode(ctx, sigChan)
 }
 
+// newExecRunner creates the --exec runner. Commands run in the workspace
+// directory; their output goes to stderr when stdout carries a
+// structured event stream.
+func (mc *MonitorCommand) newExecRunner(structured bool) *changeRunner {
+	out := io.Writer(os.Stdout)
+	if structured {
+		out = os.Stderr
+	}
+	return newChangeRunner(mc.execCommand, mc.config.resolvePath("."), mc.execDebounce, out)
+}
+
+// runInitialIndex runs an incremental index over the monitored paths,
+// given as on the command line, with the settings of the monitor
+func (mc *MonitorCommand) runInitialIndex(args []string) error {
+	ic := &IndexCommand{
+		config:       mc.config,
+		recursive:    mc.recursive,
+		maxDepth:     mc.maxDepth,
+		patterns:     mc.patterns,
+		excludePaths: mc.exclude,
+		maxWorkers:   4,
+		batchSize:    100,
+		indexType:    "incremental",
+		processFn:    mc.indexFn,
+	}
+
+	if err := ic.runIndex(nil, args); err != nil {
+		return fmt.Errorf("initial index failed: %w", err)
+	}
+	mc.initialIndexed = ic.stats.ProcessedFiles
+	return nil
+}
+
+// runOnce compares a snapshot of the monitored files with the baseline
+// stored by the previous run, processes the differences and stores the
+// snapshot as the new baseline. It returns ErrChangesFound when there
+// were any; the first run only records the baseline.
+func (mc *MonitorCommand) runOnce(ctx context.Context) error {
+	path := mc.baselinePath
+	if path == "" {
+		path = mc.config.resolvePath(defaultMonitorBaselinePath())
+	}
+
+	baseline, err := LoadMonitorBaseline(path, mc.paths)
+	if err != nil {
+		return err
+	}
+
+	structured := isStructuredFormat(mc.config.OutputFormat)
+	snap := absoluteSnapshot(mc.takeSnapshot())
+
+	var events []FileEvent
+	if baseline == nil {
+		baseline = NewMonitorBaseline(path, mc.paths)
+		if !structured {
+			PrintInfo(fmt.Sprintf("No baseline yet; recorded %d file(s) for the next run", len(snap)))
+		}
+	} else {
+		events = diffSnapshots(baseline.Files, snap, time.Now())
+	}
+
+	renderer := NewStreamRenderer(mc.config.OutputFormat, mc.config.Stdout())
+	if mc.execCommand != "" {
+		mc.runner = mc.newExecRunner(renderer != nil)
+		defer mc.runner.Stop()
+	}
+
+	if renderer != nil {
+		for _, event := range events {
+			if err := renderer.Record("event", newEventRecord(event)); err != nil {
+				return err
+			}
+		}
+		if err := renderer.Flush(); err != nil {
+			return err
+		}
+	} else {
+		for _, event := range events {
+			if event.OldPath != "" {
+				PrintInfo(fmt.Sprintf("%s: %s -> %s", event.Op, event.OldPath, event.Path))
+			} else {
+				PrintInfo(fmt.Sprintf("%s: %s", event.Op, event.Path))
+			}
+		}
+	}
+
+	if err := mc.processEvents(ctx, events); err != nil {
+		return err
+	}
+	if mc.runner != nil {
+		mc.runner.Flush(ctx)
+	}
+
+	if err := baseline.Save(snap); err != nil {
+		return err
+	}
+
+	if len(events) == 0 {
+		if !structured {
+			PrintInfo("No changes detected")
+		}
+		return nil
+	}
+	if !structured {
+		PrintSuccess(fmt.Sprintf("Detected %d change(s)", len(events)))
+	}
+	return ErrChangesFound
+}
+
 // runStatsMode runs monitor in statistics-only mode
 func (mc *MonitorCommand) runStatsMode(ctx context.Context) error {
-	PrintInfo("Running in statistics mode (no processing)")
+	if !isStructuredFormat(mc.config.OutputFormat) {
+		PrintInfo("Running in statistics mode (no processing)")
+	}
 
 	stats := mc.collectStats()
-	mc.displayStats(stats)
-
-	return nil
+	return mc.displayStats(stats)
 }
 
 // runDaemonMode runs monitor as a daemon
@@ -124,6 +468,14 @@ func (mc *MonitorCommand) runDaemonMode(ctx context.Context, sigChan chan os.Sig
 	ticker := time.NewTicker(mc.interval)
 	defer ticker.Stop()
 
+	var statsTick <-chan time.Time
+	if mc.statsInterval > 0 {
+		statsTicker := time.NewTicker(mc.statsInterval)
+		defer statsTicker.Stop()
+		statsTick = statsTicker.C
+	}
+	var lastEvents int64
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -132,14 +484,38 @@ func (mc *MonitorCommand) runDaemonMode(ctx context.Context, sigChan chan os.Sig
 		case <-sigChan:
 			PrintInfo("Received shutdown signal")
 			return mc.gracefulShutdown(ctx)
+		case event, ok := <-mc.events:
+			if !ok {
+				PrintInfo("Daemon stopped")
+				return nil
+			}
+			if err := mc.processEvents(ctx, mc.pendingEvents(event)); err != nil {
+				PrintWarning(fmt.Sprintf("Error processing events: %v", err))
+			}
 		case <-ticker.C:
 			if err := mc.processChanges(ctx); err != nil {
 				PrintWarning(fmt.Sprintf("Error processing changes: %v", err))
 			}
+		case <-statsTick:
+			lastEvents = mc.logStats(lastEvents)
 		}
 	}
 }
 
+// logStats logs the activity of the monitor at info level, given the
+// events processed by the previous call, and returns the events
+// processed so far
+func (mc *MonitorCommand) logStats(previous int64) int64 {
+	total := atomic.LoadInt64(&mc.eventsProcessed)
+	logger.Info("monitor stats",
+		"uptime", time.Since(mc.startTime).Round(time.Second),
+		"events_total", total,
+		"events_interval", total-previous,
+		"watched_files", mc.collectStats()["files"],
+	)
+	return total
+}
+
 // runInteractiveMode runs monitor in interactive mode
 func (mc *MonitorCommand) runInteractiveMode(ctx context.Context, sigChan chan os.Signal) error {
 	PrintInfo("Starting interactive monitoring...")
@@ -151,9 +527,6 @@ func (mc *MonitorCommand) runInteractiveMode(ctx context.Context, sigChan chan o
 	defer spinner.Stop()
 
 	// Interactive monitoring loop
-	ticker := time.NewTicker(mc.interval)
-	defer ticker.Stop()
-
 	eventCount := 0
 	startTime := time.Now()
 
@@ -165,100 +538,293 @@ func (mc *MonitorCommand) runInteractiveMode(ctx context.Context, sigChan chan o
 		case <-sigChan:
 			mc.printSummary(eventCount, startTime)
 			return mc.gracefulShutdown(ctx)
-		case <-ticker.C:
-			events, err := mc.detectChanges()
-			if err != nil {
-				PrintWarning(fmt.Sprintf("Error detecting changes: %v", err))
-				continue
+		case event, ok := <-mc.events:
+			if !ok {
+				mc.printSummary(eventCount, startTime)
+				return nil
 			}
 
-			if len(events) > 0 {
-				// Stop spinner temporarily to show events
-				spinner.Stop()
+			events := mc.pendingEvents(event)
 
-				eventCount += len(events)
-				PrintSuccess(fmt.Sprintf("Detected %d change(s)", len(events)))
+			// Stop spinner temporarily to show events
+			spinner.Stop()
 
-				if err := mc.processEvents(ctx, events); err != nil {
-					PrintWarning(fmt.Sprintf("Error processing events: %v", err))
-				}
+			eventCount += len(events)
+			PrintSuccess(fmt.Sprintf("Detected %d change(s)", len(events)))
 
-				// Restart spinner
-				spinner.Start()
+			if err := mc.processEvents(ctx, events); err != nil {
+				PrintWarning(fmt.Sprintf("Error processing events: %v", err))
 			}
+
+			// Restart spinner
+			spinner.Start()
 		}
 	}
 }
 
-// collectStats collects monitoring statistics
-func (mc *MonitorCommand) collectStats() map[string]interface{} {
-	stats := make(map[string]interface{})
+// runStreamMode writes one record per event until interrupted, for
+// structured output formats. Events drained by the graceful shutdown
+// are written too.
+func (mc *MonitorCommand) runStreamMode(ctx context.Context, sigChan chan os.Signal, renderer Renderer) error {
+	mc.streamMu.Lock()
+	mc.stream = renderer
+	mc.streamMu.Unlock()
+
+	shutdown := func(ctx context.Context) error {
+		err := mc.gracefulShutdown(ctx)
+		mc.streamMu.Lock()
+		mc.stream = nil
+		mc.streamMu.Unlock()
+		if flushErr := renderer.Flush(); err == nil {
+			err = flushErr
+		}
+		return err
+	}
 
-	fileCount := 0
-	dirCount := 0
-	totalSize := int64(0)
+	for {
+		select {
+		case <-ctx.Done():
+			// Pending events are still processed, so they must not see
+			// the cancellation
+			return shutdown(context.WithoutCancel(ctx))
+		case <-sigChan:
+			return shutdown(ctx)
+		case event, ok := <-mc.events:
+			if !ok {
+				return renderer.Flush()
+			}
 
-	for _, path := range mc.paths {
-		filepath.Walk(path, func(walkPath string, info os.FileInfo, err error) error {
-			if err != nil {
-				return nil // Skip errors
+			events := mc.pendingEvents(event)
+			if err := mc.recordEvents(events); err != nil {
+				return err
 			}
 
-			if !mc.recursive && walkPath != path && info.IsDir() {
-				return filepath.SkipDir
+			if err := mc.processEvents(ctx, events); err != nil {
+				fmt.Fprintf(os.Stderr, "Error processing events: %v\n", err)
 			}
+		}
+	}
+}
 
-			if info.IsDir() {
-				dirCount++
-			} else {
+// collectStats collects monitoring statistics. Directories are read by a
+// fixed pool of statWorkers goroutines pulling from a shared queue, with
+// counts aggregated atomically. Files and directories are filtered as
+// the snapshot of --poll filters them.
+func (mc *MonitorCommand) collectStats() map[string]interface{} {
+	stats := make(map[string]interface{})
+
+	var fileCount, dirCount, totalSize int64
+	matcher := mc.pathMatcher()
+	queue := newDirQueue()
+
+	for _, path := range mc.paths {
+		info, err := os.Lstat(path)
+		if err != nil {
+			continue // Skip errors
+		}
+
+		if !info.IsDir() {
+			if matcher.Matches(relativePath(path, path)) {
 				fileCount++
 				totalSize += info.Size()
 			}
+			continue
+		}
 
-			return nil
-		})
+		dirCount++
+		queue.push(dirTask{root: path, dir: path})
 	}
 
-	stats["files"] = fileCount
-	stats["directories"] = dirCount
+	workers := mc.statWorkers
+	if workers < 1 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				task, ok := queue.pop()
+				if !ok {
+					return
+				}
+
+				entries, err := ioutil.ReadDir(task.dir)
+				if err != nil {
+					queue.done()
+					continue // Skip errors
+				}
+
+				for _, entry := range entries {
+					path := filepath.Join(task.dir, entry.Name())
+					rel := relativePath(task.root, path)
+
+					if entry.IsDir() {
+						if !mc.recursive || (mc.maxDepth >= 0 && task.depth+1 > mc.maxDepth) ||
+							isStatePath(rel) || matcher.ExcludesTree(rel) {
+							continue
+						}
+						atomic.AddInt64(&dirCount, 1)
+						queue.push(dirTask{root: task.root, dir: path, depth: task.depth + 1})
+						continue
+					}
+
+					if isStatePath(rel) || !matcher.Matches(rel) {
+						continue
+					}
+
+					atomic.AddInt64(&fileCount, 1)
+					atomic.AddInt64(&totalSize, entry.Size())
+				}
+				queue.done()
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats["files"] = int(fileCount)
+	stats["directories"] = int(dirCount)
 	stats["total_size"] = totalSize
 	stats["paths"] = len(mc.paths)
 	stats["patterns"] = mc.patterns
+	stats["exclude"] = mc.exclude
 
 	return stats
 }
 
+// dirTask is a directory for the stats walk to read, depth levels below
+// the monitored root
+type dirTask struct {
+	root  string
+	dir   string
+	depth int
+}
+
+// dirQueue hands directories to the stats workers. It is unbounded, as
+// workers add the directories they find, and is drained once every
+// queued directory has been read.
+type dirQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	tasks   []dirTask
+	pending int
+}
+
+// newDirQueue creates an empty queue
+func newDirQueue() *dirQueue {
+	q := &dirQueue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push queues a directory
+func (q *dirQueue) push(task dirTask) {
+	q.mu.Lock()
+	q.tasks = append(q.tasks, task)
+	q.pending++
+	q.mu.Unlock()
+	q.cond.Signal()
+}
+
+// pop waits for a directory, reporting false once the queue is drained
+func (q *dirQueue) pop() (dirTask, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.tasks) == 0 && q.pending > 0 {
+		q.cond.Wait()
+	}
+	if len(q.tasks) == 0 {
+		return dirTask{}, false
+	}
+
+	task := q.tasks[len(q.tasks)-1]
+	q.tasks = q.tasks[:len(q.tasks)-1]
+	return task, true
+}
+
+// done marks a popped directory as read
+func (q *dirQueue) done() {
+	q.mu.Lock()
+	q.pending--
+	drained := q.pending == 0
+	q.mu.Unlock()
+
+	if drained {
+		q.cond.Broadcast()
+	}
+}
+
 // displayStats displays monitoring statistics
-func (mc *MonitorCommand) displayStats(stats map[string]interface{}) {
+func (mc *MonitorCommand) displayStats(stats map[string]interface{}) error {
+	if renderer := NewRenderer(mc.config.OutputFormat, mc.config.Stdout()); renderer != nil {
+		if err := renderer.Render("monitor_stats", stats); err != nil {
+			return err
+		}
+		return renderer.Flush()
+	}
+
 	PrintInfo("=== Monitoring Statistics ===")
 	PrintInfo(fmt.Sprintf("Paths monitored: %v", stats["paths"]))
 	PrintInfo(fmt.Sprintf("Files found: %v", stats["files"]))
 	PrintInfo(fmt.Sprintf("Directories found: %v", stats["directories"]))
 	PrintInfo(fmt.Sprintf("Total size: %v bytes", stats["total_size"]))
 	PrintInfo(fmt.Sprintf("File patterns: %v", stats["patterns"]))
+	if len(mc.exclude) > 0 {
+		PrintInfo(fmt.Sprintf("Exclude patterns: %v", stats["exclude"]))
+	}
+
+	return nil
 }
 
-// detectChanges detects file system changes
-func (mc *MonitorCommand) detectChanges() ([]string, error) {
-	// This is a placeholder implementation
-	// In a real implementation, this would use file system watchers
-	// or compare file modification times
+// detectChanges returns the file events queued by the watcher without
+// waiting for new ones
+func (mc *MonitorCommand) detectChanges() ([]FileEvent, error) {
+	events := []FileEvent{}
+	for {
+		select {
+		case event, ok := <-mc.events:
+			if !ok {
+				return events, nil
+			}
+			events = append(events, event)
+		default:
+			return events, nil
+		}
+	}
+}
 
-	// For now, return empty slice
-	return []string{}, nil
+// pendingEvents returns event followed by any others already queued
+func (mc *MonitorCommand) pendingEvents(event FileEvent) []FileEvent {
+	queued, _ := mc.detectChanges()
+	return append([]FileEvent{event}, queued...)
 }
 
 // processEvents processes detected events
-func (mc *MonitorCommand) processEvents(ctx context.Context, events []string) error {
+func (mc *MonitorCommand) processEvents(ctx context.Context, events []FileEvent) error {
+	mc.inFlight.Add(1)
+	defer mc.inFlight.Done()
+
 	for _, event := range events {
-		if mc.config.Verbose {
-			PrintInfo(fmt.Sprintf("Processing: %s", event))
+		if event.OldPath != "" {
+			logger.Debug("processing change", "path", event.Path, "op", event.Op, "old_path", event.OldPath)
+		} else {
+			logger.Debug("processing change", "path", event.Path, "op", event.Op)
+		}
+
+		if mc.handleFn != nil {
+			mc.handleFn(ctx, event)
 		}
 
-		// Process the event (placeholder)
-		// In a real implementation, this would trigger indexing
+		if mc.runner != nil {
+			mc.runner.Changed(ctx, event.Path)
+		}
 	}
 
+	atomic.AddInt64(&mc.eventsProcessed, int64(len(events)))
 	return nil
 }
 
@@ -268,36 +834,111 @@ func (mc *MonitorCommand) processChanges(ctx context.Context) error {
 	// In a real implementation, this would scan for changes
 	// and trigger appropriate indexing actions
 
-	if mc.config.Verbose {
-		PrintInfo("Scanning for changes...")
-	}
+	logger.Debug("scanning for changes")
+
+	return nil
+}
 
+// recordEvents writes a record per event to the stream renderer, if any
+func (mc *MonitorCommand) recordEvents(events []FileEvent) error {
+	mc.streamMu.Lock()
+	defer mc.streamMu.Unlock()
+	if mc.stream == nil {
+		return nil
+	}
+	for _, event := range events {
+		if err := mc.stream.Record("event", newEventRecord(event)); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// gracefulShutdown performs graceful shutdown
+// gracefulShutdown stops taking new events, processes the events already
+// queued or pending in the debouncer and waits for in-progress ones, for
+// up to shutdownTimeout. It returns an error when that times out.
 func (mc *MonitorCommand) gracefulShutdown(ctx context.Context) error {
-	PrintInfo("Performing graceful shutdown...")
+	structured := isStructuredFormat(mc.config.OutputFormat)
+	if !structured {
+		PrintInfo("Performing graceful shutdown...")
+	}
 
-	// Perform cleanup operations
-	// In a real implementation, this would:
-	// - Stop all file watchers
-	// - Complete in-progress indexing
-	// - Save state
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		mc.drainEvents(ctx)
+		mc.inFlight.Wait()
+	}()
 
-	PrintSuccess("Shutdown complete")
+	timeout := mc.shutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	var err error
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-drained:
+	case <-timer.C:
+		err = fmt.Errorf("shutdown timed out after %v with events still being processed", timeout)
+	}
+
+	if mc.watcher != nil {
+		mc.watcher.Close()
+	}
+	mc.stopHTTPServer()
+	mc.removePIDFile()
+
+	if err != nil {
+		return err
+	}
+	if !structured {
+		PrintSuccess("Shutdown complete")
+	}
 	return nil
 }
 
+// drainEvents stops the event source and processes the remaining events
+// until the event channel closes
+func (mc *MonitorCommand) drainEvents(ctx context.Context) {
+	if mc.stopEvents == nil || mc.events == nil {
+		return
+	}
+	mc.stopEvents()
+
+	for event := range mc.events {
+		if err := mc.recordEvents([]FileEvent{event}); err != nil {
+			PrintWarning(fmt.Sprintf("Error writing event: %v", err))
+		}
+		if err := mc.processEvents(ctx, []FileEvent{event}); err != nil {
+			PrintWarning(fmt.Sprintf("Error processing events: %v", err))
+		}
+	}
+}
+
+// removePIDFile removes the --pid-file, if any, warning on failure
+func (mc *MonitorCommand) removePIDFile() {
+	if mc.pidFile == "" {
+		return
+	}
+	if err := removePIDFile(mc.pidFile); err != nil {
+		PrintWarning(err.Error())
+	}
+}
+
 // printSummary prints monitoring summary
 func (mc *MonitorCommand) printSummary(eventCount int, startTime time.Time) {
 	duration := time.Since(startTime)
 	PrintInfo("=== Monitoring Summary ===")
 	PrintInfo(fmt.Sprintf("Duration: %v", duration.Round(time.Second)))
+	if mc.initialIndex {
+		PrintInfo(fmt.Sprintf("Files indexed on startup: %s", humanizeInt(int64(mc.initialIndexed))))
+	}
 	PrintInfo(fmt.Sprintf("Total events: %d", eventCount))
 
 	if duration > 0 {
 		rate := float64(eventCount) / duration.Seconds()
 		PrintInfo(fmt.Sprintf("Event rate: %.2f events/second", rate))
 	}
-}
\ No newline at end of file
+}
@@ -1,6 +1,18 @@
 package cli
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -62,7 +74,7 @@ func TestMonitorProcessEvents(t *testing.T) {
 		config: &CommandConfig{},
 	}
 
-	events := []string{"file1.txt", "file2.md"}
+	events := []FileEvent{{Path: "file1.txt", Op: "create"}, {Path: "file2.md", Op: "write"}}
 
 	// Test that processEvents doesn't panic
 	defer func() {
@@ -92,6 +104,363 @@ func TestMonitorPrintSummary(t *testing.T) {
 	mc.printSummary(10, time.Now().Add(-time.Minute))
 }
 
+func TestMonitorInitialIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-initial-index")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	existing := []string{"a.md", "b.md", "c.txt"}
+	for _, name := range existing {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	config := &CommandConfig{Workspace: dir, Timeout: time.Second}
+	config.startTimeout()
+	defer config.stopTimeout()
+
+	var mu sync.Mutex
+	var indexed []string
+	mc := &MonitorCommand{
+		config:       config,
+		maxDepth:     -1,
+		patterns:     []string{"*"},
+		initialIndex: true,
+		indexFn: func(ctx context.Context, filePath string, stats *IndexStats) error {
+			mu.Lock()
+			defer mu.Unlock()
+			indexed = append(indexed, filepath.Base(filePath))
+
+			// A change made while indexing is reported once monitoring
+			// starts
+			if len(indexed) == 1 {
+				return ioutil.WriteFile(filepath.Join(dir, "late.md"), []byte("late"), 0644)
+			}
+			return nil
+		},
+	}
+
+	stdout := captureStdout(t, func() {
+		if err := mc.runMonitor(nil, nil); err != nil {
+			t.Errorf("runMonitor() error = %v", err)
+		}
+	})
+
+	sort.Strings(indexed)
+	if !reflect.DeepEqual(indexed, existing) {
+		t.Errorf("initially indexed %v, expected %v", indexed, existing)
+	}
+
+	summary := strings.Index(stdout, "Indexing completed")
+	detected := strings.Index(stdout, "Detected")
+	if summary < 0 || detected < 0 || summary > detected {
+		t.Errorf("expected the initial index to finish before the first change is reported:\n%s", stdout)
+	}
+	if !strings.Contains(stdout, "Files indexed on startup: 3") {
+		t.Errorf("summary does not report the initially indexed files:\n%s", stdout)
+	}
+}
+
+func TestMonitorOnce(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-monitor-once")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"a.md", "b.md"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	// The first run records the baseline and reports nothing
+	if _, err := runWorkspaceCommand(t, dir, "monitor", "--once", "-o", "ndjson"); err != nil {
+		t.Fatalf("first monitor --once error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, defaultMonitorBaselinePath())); err != nil {
+		t.Fatalf("baseline not saved: %v", err)
+	}
+
+	modified := filepath.Join(dir, "a.md")
+	if err := ioutil.WriteFile(modified, []byte("a.md, edited"), 0644); err != nil {
+		t.Fatalf("Failed to modify a.md: %v", err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(modified, later, later); err != nil {
+		t.Fatalf("Failed to touch a.md: %v", err)
+	}
+
+	output, err := runWorkspaceCommand(t, dir, "monitor", "--once", "-o", "ndjson")
+	if !errors.Is(err, ErrChangesFound) {
+		t.Fatalf("second monitor --once error = %v, expected ErrChangesFound", err)
+	}
+	if code := ExitCode(err); code != ExitChangesFound {
+		t.Errorf("ExitCode() = %d, expected %d", code, ExitChangesFound)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected one event, got:\n%s", output)
+	}
+	var record EventRecord
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("Failed to parse event %q: %v", lines[0], err)
+	}
+	if filepath.Base(record.Path) != "a.md" || record.Event != "write" {
+		t.Errorf("event = %+v, expected a write to a.md", record)
+	}
+
+	// The baseline moved on, so nothing changed since the second run
+	if _, err := runWorkspaceCommand(t, dir, "monitor", "--once", "-o", "ndjson"); err != nil {
+		t.Errorf("third monitor --once error = %v, expected no changes", err)
+	}
+}
+
+func TestMonitorDaemonStatsInterval(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-daemon-stats")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"a.md", "b.md"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	logs := captureLog(t)
+
+	events := make(chan FileEvent, 3)
+	for i := 0; i < 3; i++ {
+		events <- FileEvent{Path: filepath.Join(dir, "a.md")}
+	}
+
+	mc := &MonitorCommand{
+		config:        &CommandConfig{},
+		paths:         []string{dir},
+		maxDepth:      -1,
+		interval:      time.Hour,
+		statsInterval: 20 * time.Millisecond,
+		startTime:     time.Now(),
+		events:        events,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+
+	captureStdout(t, func() {
+		if err := mc.runDaemonMode(ctx, make(chan os.Signal)); err != nil {
+			t.Errorf("runDaemonMode() error = %v", err)
+		}
+	})
+
+	var lines []string
+	for _, line := range strings.Split(logs.String(), "\n") {
+		if strings.Contains(line, `msg="monitor stats"`) {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		t.Fatalf("no stats lines logged:\n%s", logs.String())
+	}
+
+	last := lines[len(lines)-1]
+	for _, want := range []string{"events_total=3", "events_interval=0", "watched_files=2", "uptime="} {
+		if !strings.Contains(last, want) {
+			t.Errorf("stats line %q does not contain %s", last, want)
+		}
+	}
+}
+
+func TestMonitorStatsOnlyStructuredOutput(t *testing.T) {
+	preserveTheme(t)
+
+	dir := indexWorkspace(t, []string{"a.md"})
+	for _, format := range []string{"json", "ndjson"} {
+		output, err := runWorkspaceCommand(t, dir, "monitor", ".", "--stats-only", "-o", format)
+		if err != nil {
+			t.Fatalf("monitor --stats-only -o %s error = %v", format, err)
+		}
+
+		var stats map[string]interface{}
+		if err := json.Unmarshal([]byte(output), &stats); err != nil {
+			t.Errorf("-o %s output is not one JSON document: %v\n%s", format, err, output)
+		}
+	}
+}
+
+func TestMonitorRejectsPrometheusOutput(t *testing.T) {
+	preserveTheme(t)
+
+	dir := indexWorkspace(t, []string{"a.md"})
+	for _, args := range [][]string{
+		{"monitor", ".", "--once", "-o", "prometheus"},
+		{"monitor", ".", "--stats-only", "-o", "prometheus"},
+	} {
+		output, err := runWorkspaceCommand(t, dir, args...)
+		if err == nil || !strings.Contains(err.Error(), "prometheus output is not supported for monitor") {
+			t.Errorf("%v: error = %v, expected prometheus to be unsupported", args, err)
+		}
+		if output != "" {
+			t.Errorf("%v: printed %q, expected nothing on stdout", args, output)
+		}
+	}
+}
+
+func TestMonitorGracefulShutdownWaits(t *testing.T) {
+	tests := []struct {
+		name    string
+		handle  time.Duration
+		timeout time.Duration
+		wantErr bool
+		minWait time.Duration
+		maxWait time.Duration
+	}{
+		{"Finishes in time", 150 * time.Millisecond, 2 * time.Second, false, 100 * time.Millisecond, time.Second},
+		{"Times out", 2 * time.Second, 50 * time.Millisecond, true, 40 * time.Millisecond, time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan struct{})
+			release := make(chan struct{})
+			defer close(release)
+
+			mc := &MonitorCommand{
+				config:          &CommandConfig{},
+				shutdownTimeout: tt.timeout,
+				handleFn: func(ctx context.Context, event FileEvent) {
+					close(started)
+					select {
+					case <-time.After(tt.handle):
+					case <-release:
+					}
+				},
+			}
+
+			go mc.processEvents(context.Background(), []FileEvent{{Path: "slow.md"}})
+			<-started
+
+			var err error
+			begin := time.Now()
+			captureStdout(t, func() {
+				err = mc.gracefulShutdown(context.Background())
+			})
+			waited := time.Since(begin)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("gracefulShutdown() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if waited < tt.minWait || waited > tt.maxWait {
+				t.Errorf("gracefulShutdown() took %v, expected between %v and %v", waited, tt.minWait, tt.maxWait)
+			}
+		})
+	}
+}
+
+func TestMonitorGracefulShutdownFlushesDebounced(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	source := make(chan FileEvent)
+	var mu sync.Mutex
+	var handled []string
+	mc := &MonitorCommand{
+		config:         &CommandConfig{},
+		debounceWindow: time.Hour,
+		stopEvents:     func() { close(source) },
+		handleFn: func(ctx context.Context, event FileEvent) {
+			mu.Lock()
+			handled = append(handled, event.Path)
+			mu.Unlock()
+		},
+	}
+	mc.events = mc.debounce(ctx, source)
+
+	source <- FileEvent{Path: "pending.md", Op: "write"}
+
+	var err error
+	captureStdout(t, func() {
+		err = mc.gracefulShutdown(ctx)
+	})
+	if err != nil {
+		t.Fatalf("gracefulShutdown() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(handled, []string{"pending.md"}) {
+		t.Errorf("handled %v, expected the pending debounced event", handled)
+	}
+}
+
+func TestMonitorStreamShutdownDrainsDebounced(t *testing.T) {
+	for _, exit := range []string{"signal", "cancel"} {
+		t.Run(exit, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			source := make(chan FileEvent)
+			var mu sync.Mutex
+			var handled []string
+			mc := &MonitorCommand{
+				config:         &CommandConfig{OutputFormat: "ndjson"},
+				debounceWindow: time.Hour,
+				stopEvents:     func() { close(source) },
+				handleFn: func(ctx context.Context, event FileEvent) {
+					mu.Lock()
+					handled = append(handled, event.Path)
+					mu.Unlock()
+				},
+			}
+			mc.events = mc.debounce(context.Background(), source)
+
+			source <- FileEvent{Path: "pending.md", Op: "write"}
+
+			var out bytes.Buffer
+			sigChan := make(chan os.Signal, 1)
+			if exit == "signal" {
+				sigChan <- os.Interrupt
+			} else {
+				cancel()
+			}
+
+			var err error
+			stdout := captureStdout(t, func() {
+				err = mc.runStreamMode(ctx, sigChan, NewStreamRenderer("ndjson", &out))
+			})
+			if err != nil {
+				t.Fatalf("runStreamMode() error = %v", err)
+			}
+			if stdout != "" {
+				t.Errorf("runStreamMode() printed %q, expected nothing besides the stream", stdout)
+			}
+
+			var record struct {
+				Type string `json:"type"`
+				Path string `json:"path"`
+			}
+			if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+				t.Fatalf("stream %q is not one JSON record: %v", out.String(), err)
+			}
+			if record.Type != "event" || record.Path != "pending.md" {
+				t.Errorf("record = %+v, expected the pending debounced event", record)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if !reflect.DeepEqual(handled, []string{"pending.md"}) {
+				t.Errorf("handled %v, expected the pending debounced event", handled)
+			}
+		})
+	}
+}
+
 // Benchmarks
 func BenchmarkMonitorDetectChanges(b *testing.B) {
 	mc := &MonitorCommand{
@@ -102,4 +471,186 @@ func BenchmarkMonitorDetectChanges(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_, _ = mc.detectChanges()
 	}
-}
\ No newline at end of file
+}
+
+func TestMonitorCollectStatsParallel(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-stats")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Build a synthetic tree three levels deep with files of varying size
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 3; j++ {
+			sub := filepath.Join(dir, fmt.Sprintf("d%d", i), fmt.Sprintf("s%d", j))
+			if err := os.MkdirAll(sub, 0755); err != nil {
+				t.Fatalf("Failed to create %s: %v", sub, err)
+			}
+			for k := 0; k < 5; k++ {
+				name := filepath.Join(sub, fmt.Sprintf("f%d.txt", k))
+				if err := ioutil.WriteFile(name, make([]byte, i*100+j*10+k), 0644); err != nil {
+					t.Fatalf("Failed to write %s: %v", name, err)
+				}
+			}
+		}
+	}
+
+	// Sequential baseline
+	var files, dirs int
+	var size int64
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			dirs++
+		} else {
+			files++
+			size += info.Size()
+		}
+		return nil
+	})
+
+	mc := &MonitorCommand{
+		config:      &CommandConfig{},
+		paths:       []string{dir},
+		recursive:   true,
+		maxDepth:    -1,
+		statWorkers: 8,
+	}
+
+	stats := mc.collectStats()
+
+	if stats["files"] != files {
+		t.Errorf("Expected %d files, got %v", files, stats["files"])
+	}
+	if stats["directories"] != dirs {
+		t.Errorf("Expected %d directories, got %v", dirs, stats["directories"])
+	}
+	if stats["total_size"] != size {
+		t.Errorf("Expected total size %d, got %v", size, stats["total_size"])
+	}
+}
+
+func TestMonitorCollectStatsMaxDepth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-monitor-depth")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, rel := range []string{"root.md", "a/one.md", "a/b/two.md", "a/b/c/three.md"} {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir for %s: %v", rel, err)
+		}
+		if err := ioutil.WriteFile(path, []byte("content"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", rel, err)
+		}
+	}
+
+	tests := []struct {
+		maxDepth  int
+		wantFiles int
+		wantDirs  int
+	}{
+		{0, 1, 1},
+		{1, 2, 2},
+		{2, 3, 3},
+		{-1, 4, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("Depth %d", tt.maxDepth), func(t *testing.T) {
+			mc := &MonitorCommand{
+				config:      &CommandConfig{},
+				paths:       []string{dir},
+				recursive:   true,
+				maxDepth:    tt.maxDepth,
+				statWorkers: 2,
+			}
+
+			stats := mc.collectStats()
+			if stats["files"] != tt.wantFiles || stats["directories"] != tt.wantDirs {
+				t.Errorf("collectStats() = %v files, %v directories, expected %d and %d",
+					stats["files"], stats["directories"], tt.wantFiles, tt.wantDirs)
+			}
+		})
+	}
+}
+
+func TestMonitorCollectStatsExclude(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-monitor-exclude")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, rel := range []string{"a.md", "build/out.o", ".git/HEAD", "docs/b.md"} {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir for %s: %v", rel, err)
+		}
+		if err := ioutil.WriteFile(path, []byte("content"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", rel, err)
+		}
+	}
+
+	mc := &MonitorCommand{
+		config:      &CommandConfig{},
+		paths:       []string{dir},
+		recursive:   true,
+		maxDepth:    -1,
+		exclude:     []string{".git/**", "*.o"},
+		statWorkers: 2,
+	}
+
+	stats := mc.collectStats()
+	if stats["files"] != 2 {
+		t.Errorf("collectStats() = %v files, expected 2 with .git and build output excluded", stats["files"])
+	}
+}
+
+func TestMonitorCollectStatsFilters(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-monitor-filters")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, rel := range []string{
+		"a.md", "notes.txt", "build/out.md", "build/deep/x.md", "src/build/y.md", "docs/b.md", ".stroidex/manifest.json",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir for %s: %v", rel, err)
+		}
+		if err := ioutil.WriteFile(path, []byte("content"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", rel, err)
+		}
+	}
+
+	for _, workers := range []int{1, 4} {
+		t.Run(fmt.Sprintf("%d worker(s)", workers), func(t *testing.T) {
+			mc := &MonitorCommand{
+				config:      &CommandConfig{},
+				paths:       []string{dir},
+				recursive:   true,
+				maxDepth:    -1,
+				patterns:    []string{"*.md"},
+				exclude:     []string{"build/**"},
+				statWorkers: workers,
+			}
+
+			// a.md, src/build/y.md and docs/b.md below the root, src,
+			// src/build and docs; build and the state directory are
+			// neither counted nor walked
+			stats := mc.collectStats()
+			if stats["files"] != 3 || stats["directories"] != 4 {
+				t.Errorf("collectStats() = %v files, %v directories, expected 3 and 4",
+					stats["files"], stats["directories"])
+			}
+		})
+	}
+}
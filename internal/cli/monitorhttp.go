@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// httpShutdownTimeout bounds how long in-flight requests may delay a
+// monitor shutdown
+const httpShutdownTimeout = 5 * time.Second
+
+// MonitorStatus is the state of a running monitor served on /status and
+// /metrics
+type MonitorStatus struct {
+	Running         bool      `json:"running" yaml:"running"`
+	StartedAt       time.Time `json:"started_at" yaml:"started_at"`
+	UptimeSeconds   float64   `json:"uptime_seconds" yaml:"uptime_seconds"`
+	Paths           []string  `json:"paths" yaml:"paths"`
+	EventsProcessed int64     `json:"events_processed" yaml:"events_processed"`
+	InitialIndexed  int       `json:"initial_indexed" yaml:"initial_indexed"`
+}
+
+// monitorStatus returns the current state of the monitor
+func (mc *MonitorCommand) monitorStatus() MonitorStatus {
+	return MonitorStatus{
+		Running:         true,
+		StartedAt:       mc.startTime,
+		UptimeSeconds:   time.Since(mc.startTime).Seconds(),
+		Paths:           mc.paths,
+		EventsProcessed: atomic.LoadInt64(&mc.eventsProcessed),
+		InitialIndexed:  mc.initialIndexed,
+	}
+}
+
+// startHTTPServer listens on --http and serves /healthz, /metrics and
+// /status in the background. Listening before returning reports a bad
+// or busy address right away.
+func (mc *MonitorCommand) startHTTPServer() error {
+	listener, err := net.Listen("tcp", mc.httpAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", mc.httpAddr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", mc.handleHealthz)
+	mux.HandleFunc("/metrics", mc.handleMetrics)
+	mux.HandleFunc("/status", mc.handleStatus)
+
+	mc.server = &http.Server{Handler: mux}
+	mc.httpListener = listener
+
+	go func() {
+		if err := mc.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			PrintWarning(fmt.Sprintf("HTTP server stopped: %v", err))
+		}
+	}()
+
+	logger.Debug("serving monitor endpoints", "addr", listener.Addr().String())
+	return nil
+}
+
+// stopHTTPServer shuts the HTTP server down, letting in-flight requests
+// finish. It is safe to call more than once.
+func (mc *MonitorCommand) stopHTTPServer() {
+	if mc.server == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+	defer cancel()
+	if err := mc.server.Shutdown(ctx); err != nil {
+		PrintWarning(fmt.Sprintf("Failed to stop HTTP server: %v", err))
+	}
+	mc.server = nil
+}
+
+// handleHealthz answers 200 while the monitor runs
+func (mc *MonitorCommand) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, "ok")
+}
+
+// handleMetrics serves the monitor state in the Prometheus text format
+func (mc *MonitorCommand) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	m := newMetricSet()
+	mc.monitorStatus().collectMetrics(m)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := m.write(w); err != nil {
+		logger.Debug("failed to write metrics", "error", err)
+	}
+}
+
+// handleStatus serves the monitor state as JSON
+func (mc *MonitorCommand) handleStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(mc.monitorStatus()); err != nil {
+		logger.Debug("failed to write status", "error", err)
+	}
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMonitorHTTPServer(t *testing.T) {
+	mc := &MonitorCommand{
+		config:         &CommandConfig{},
+		paths:          []string{"docs", "src"},
+		httpAddr:       "127.0.0.1:0",
+		startTime:      time.Now().Add(-time.Minute),
+		initialIndexed: 4,
+	}
+	if err := mc.startHTTPServer(); err != nil {
+		t.Fatalf("startHTTPServer() error = %v", err)
+	}
+	defer mc.stopHTTPServer()
+
+	if err := mc.processEvents(context.Background(), []FileEvent{{Path: "docs/a.md"}, {Path: "docs/b.md"}}); err != nil {
+		t.Fatalf("processEvents() error = %v", err)
+	}
+
+	base := fmt.Sprintf("http://%s", mc.httpListener.Addr())
+	get := func(path string) string {
+		t.Helper()
+		resp, err := http.Get(base + path)
+		if err != nil {
+			t.Fatalf("GET %s error = %v", path, err)
+		}
+		defer resp.Body.Close()
+
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("GET %s status = %d, expected 200", path, resp.StatusCode)
+		}
+		return string(body)
+	}
+
+	if body := get("/healthz"); strings.TrimSpace(body) != "ok" {
+		t.Errorf("/healthz body = %q, expected ok", body)
+	}
+
+	metrics := get("/metrics")
+	for _, want := range []string{
+		"stroidex_monitor_up 1",
+		"stroidex_monitor_events_processed_total 2",
+		"# TYPE stroidex_monitor_events_processed_total counter",
+		"stroidex_monitor_initial_indexed_files 4",
+		"stroidex_monitor_watched_paths 2",
+		`stroidex_monitor_watched_path{path="src"} 1`,
+		"# TYPE stroidex_monitor_uptime_seconds gauge",
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("/metrics missing %q:\n%s", want, metrics)
+		}
+	}
+
+	var status MonitorStatus
+	if err := json.Unmarshal([]byte(get("/status")), &status); err != nil {
+		t.Fatalf("/status is not valid JSON: %v", err)
+	}
+	if !status.Running || status.EventsProcessed != 2 || len(status.Paths) != 2 || status.UptimeSeconds < 60 {
+		t.Errorf("/status = %+v", status)
+	}
+
+	mc.stopHTTPServer()
+	if _, err := http.Get(base + "/healthz"); err == nil {
+		t.Error("server still answers after stopHTTPServer()")
+	}
+}
+
+func TestMonitorHTTPServerBadAddress(t *testing.T) {
+	mc := &MonitorCommand{config: &CommandConfig{}, httpAddr: "not-an-address"}
+	if err := mc.startHTTPServer(); err == nil {
+		mc.stopHTTPServer()
+		t.Fatal("startHTTPServer() accepted an invalid address")
+	}
+}
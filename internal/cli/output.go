@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// Output writes the messages of the Print helpers. Colored output marks
+// messages with a colored glyph; plain output, used when colors are
+// disabled, marks them with a bracketed word instead. Warnings and
+// errors go to the error writer, keeping them out of results on stdout.
+// Quiet output writes errors only.
+type Output struct {
+	mu sync.Mutex
+
+	// w receives success and info messages, errW warnings and errors;
+	// nil writes to os.Stdout and os.Stderr respectively at the time of
+	// writing
+	w     io.Writer
+	errW  io.Writer
+	plain bool
+	quiet bool
+	theme Theme
+}
+
+// output is the sink of the Print helpers. Until the persistent pre-run
+// configures it, it honors NO_COLOR but draws glyphs uncolored.
+var output = newOutput(&CommandConfig{})
+
+// newOutput creates an output following the color settings of config
+func newOutput(config *CommandConfig) *Output {
+	return &Output{
+		plain: colorDisabled(config),
+		quiet: config.Quiet,
+		theme: resolveTheme(config),
+	}
+}
+
+// setupOutput applies the configured color and quiet settings to the
+// Print helpers
+func setupOutput(config *CommandConfig) {
+	configured := newOutput(config)
+
+	output.mu.Lock()
+	defer output.mu.Unlock()
+	output.plain = configured.plain
+	output.quiet = configured.quiet
+	output.theme = configured.theme
+}
+
+// SetOutputWriters redirects the Print helpers: success and info
+// messages to w, warnings and errors to errW. A nil writer restores
+// os.Stdout or os.Stderr.
+func SetOutputWriters(w, errW io.Writer) {
+	output.mu.Lock()
+	defer output.mu.Unlock()
+	output.w = w
+	output.errW = errW
+}
+
+// messageLevel orders the kinds of Print helper messages
+type messageLevel int
+
+const (
+	levelInfo messageLevel = iota
+	levelWarning
+	levelError
+)
+
+// Success writes a success message
+func (o *Output) Success(message string) {
+	o.print(levelInfo, o.theme.Healthy, "✓", "[OK]", message)
+}
+
+// Info writes an informational message
+func (o *Output) Info(message string) {
+	o.print(levelInfo, o.theme.Info, "ℹ", "[INFO]", message)
+}
+
+// Warning writes a warning to the error writer
+func (o *Output) Warning(message string) {
+	o.print(levelWarning, o.theme.Warning, "⚠", "[WARN]", message)
+}
+
+// Table writes rows under header as an aligned table to the writer of
+// info messages. Like them, it is not written in quiet mode.
+func (o *Output) Table(header []string, rows [][]string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.quiet {
+		return
+	}
+
+	table := tablewriter.NewWriter(o.writer(levelInfo))
+	table.SetHeader(header)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	if colors := sgrColors(o.theme.Header); !o.plain && len(colors) > 0 {
+		headerColors := make([]tablewriter.Colors, len(header))
+		for i := range headerColors {
+			headerColors[i] = colors
+		}
+		table.SetHeaderColor(headerColors...)
+	}
+	table.AppendBulk(rows)
+	table.Render()
+}
+
+// sgrColors converts a theme color code such as "1;97" to the
+// attributes tablewriter paints with
+func sgrColors(code string) tablewriter.Colors {
+	var colors tablewriter.Colors
+	for _, part := range strings.Split(code, ";") {
+		if n, err := strconv.Atoi(part); err == nil {
+			colors = append(colors, n)
+		}
+	}
+	return colors
+}
+
+// Error writes an error to the error writer
+func (o *Output) Error(err error) {
+	o.print(levelError, o.theme.Error, "Error:", "Error:", err.Error())
+}
+
+// print writes message after its glyph, painted with code, or after the
+// plain prefix when colors are disabled. Only errors are written in
+// quiet mode.
+func (o *Output) print(level messageLevel, code, glyph, plain, message string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.quiet && level < levelError {
+		return
+	}
+
+	prefix := o.theme.Paint(code, glyph)
+	if o.plain {
+		prefix = plain
+	}
+
+	fmt.Fprintf(o.writer(level), "%s %s\n", prefix, message)
+}
+
+// writer returns the writer for messages of level
+func (o *Output) writer(level messageLevel) io.Writer {
+	if level >= levelWarning {
+		if o.errW != nil {
+			return o.errW
+		}
+		return os.Stderr
+	}
+	if o.w != nil {
+		return o.w
+	}
+	return os.Stdout
+}
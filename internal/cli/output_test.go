@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// preserveTheme restores the theme and Print helper settings, which
+// executing a command applies globally, when the test ends
+func preserveTheme(t *testing.T) {
+	t.Helper()
+
+	theme := activeTheme
+	plain, quiet, outputTheme := output.plain, output.quiet, output.theme
+	t.Cleanup(func() {
+		activeTheme = theme
+		output.plain, output.quiet, output.theme = plain, quiet, outputTheme
+	})
+}
+
+func TestOutputModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *CommandConfig
+		envColor string
+		expected string
+	}{
+		{
+			"Colored",
+			&CommandConfig{Theme: "default"},
+			"",
+			"\033[32m✓\033[0m saved\n\033[36mℹ\033[0m watching\n\033[33m⚠\033[0m slow\n\033[31mError:\033[0m failed\n",
+		},
+		{
+			"None theme keeps glyphs",
+			&CommandConfig{Theme: "none"},
+			"",
+			"✓ saved\nℹ watching\n⚠ slow\nError: failed\n",
+		},
+		{
+			"No color flag",
+			&CommandConfig{Theme: "default", NoColor: true},
+			"",
+			"[OK] saved\n[INFO] watching\n[WARN] slow\nError: failed\n",
+		},
+		{
+			"NO_COLOR env",
+			&CommandConfig{Theme: "dark"},
+			"1",
+			"[OK] saved\n[INFO] watching\n[WARN] slow\nError: failed\n",
+		},
+	}
+
+	pretendTerminal(t, true)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.envColor)
+
+			var buf bytes.Buffer
+			out := newOutput(tt.config)
+			out.w, out.errW = &buf, &buf
+
+			out.Success("saved")
+			out.Info("watching")
+			out.Warning("slow")
+			out.Error(errors.New("failed"))
+
+			if buf.String() != tt.expected {
+				t.Errorf("output = %q, expected %q", buf.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestSetupOutput(t *testing.T) {
+	preserveTheme(t)
+	pretendTerminal(t, true)
+	t.Setenv("NO_COLOR", "")
+
+	setupOutput(&CommandConfig{Theme: "default", NoColor: true})
+	got := captureStdout(t, func() {
+		PrintSuccess("done")
+	})
+	if got != "[OK] done\n" {
+		t.Errorf("PrintSuccess() with --no-color = %q, expected plain prefix", got)
+	}
+
+	setupOutput(&CommandConfig{Theme: "default"})
+	got = captureStdout(t, func() {
+		PrintInfo("careful")
+	})
+	if got != "\033[36mℹ\033[0m careful\n" {
+		t.Errorf("PrintInfo() with colors = %q, expected colored glyph", got)
+	}
+}
+
+func TestOutputQuiet(t *testing.T) {
+	preserveTheme(t)
+	t.Setenv("NO_COLOR", "1")
+
+	setupOutput(&CommandConfig{Quiet: true})
+
+	var stderr bytes.Buffer
+	output.errW = &stderr
+	defer func() { output.errW = nil }()
+
+	got := captureStdout(t, func() {
+		PrintInfo("watching")
+		PrintSuccess("saved")
+		PrintWarning("slow")
+		PrintError(errors.New("failed"))
+	})
+	if got != "" {
+		t.Errorf("quiet output = %q, expected nothing", got)
+	}
+	if stderr.String() != "Error: failed\n" {
+		t.Errorf("quiet errors = %q, expected the error", stderr.String())
+	}
+
+	setupOutput(&CommandConfig{})
+	got = captureStdout(t, func() {
+		PrintInfo("watching")
+	})
+	if got != "[INFO] watching\n" {
+		t.Errorf("PrintInfo() without --quiet = %q, expected the message", got)
+	}
+}
+
+func TestOutputStreams(t *testing.T) {
+	preserveTheme(t)
+	t.Setenv("NO_COLOR", "1")
+	setupOutput(&CommandConfig{})
+
+	var stdout, stderr bytes.Buffer
+	SetOutputWriters(&stdout, &stderr)
+	defer SetOutputWriters(nil, nil)
+
+	PrintInfo("watching")
+	PrintSuccess("saved")
+	PrintWarning("slow")
+	PrintError(errors.New("failed"))
+
+	if expected := "[INFO] watching\n[OK] saved\n"; stdout.String() != expected {
+		t.Errorf("stdout = %q, expected %q", stdout.String(), expected)
+	}
+	if expected := "[WARN] slow\nError: failed\n"; stderr.String() != expected {
+		t.Errorf("stderr = %q, expected %q", stderr.String(), expected)
+	}
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// openOutputFile creates or truncates --output-file, which then receives
+// the structured results of commands in place of stdout
+func (c *CommandConfig) openOutputFile() error {
+	if c.OutputFile == "" {
+		return nil
+	}
+
+	if err := c.closeOutputFile(); err != nil {
+		return err
+	}
+
+	file, err := os.Create(c.OutputFile)
+	if err != nil {
+		return fmt.Errorf("failed to open output file: %w", err)
+	}
+	c.outputFile = file
+	return nil
+}
+
+// closeOutputFile closes --output-file, if open
+func (c *CommandConfig) closeOutputFile() error {
+	if c.outputFile == nil {
+		return nil
+	}
+
+	err := c.outputFile.Close()
+	c.outputFile = nil
+	if err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	return nil
+}
+
+// Stdout returns where commands write their structured results: the
+// --output-file when one is open, os.Stdout otherwise. Progress and
+// human-readable messages do not go through it.
+func (c *CommandConfig) Stdout() io.Writer {
+	if c.outputFile != nil {
+		return c.outputFile
+	}
+	return os.Stdout
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runWithOutputFile runs the CLI in dir like a user would, closing the
+// output file when it finishes
+func runWithOutputFile(t *testing.T, dir string, args ...string) (string, error) {
+	t.Helper()
+	preserveTheme(t)
+
+	cli := NewCLI()
+	cli.RootCmd.SetArgs(append([]string{"--workspace", dir, "--no-color"}, args...))
+
+	var err error
+	stdout := captureStdout(t, func() {
+		err = cli.Execute()
+	})
+	return stdout, err
+}
+
+func TestOutputFile(t *testing.T) {
+	dir := indexWorkspace(t, []string{"a.md", "b.md", "src/main.go"})
+	outPath := filepath.Join(dir, "stats.json")
+
+	// Stale content must be replaced, not appended to
+	if err := ioutil.WriteFile(outPath, []byte("stale content that is longer than nothing"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	stdout, err := runWithOutputFile(t, dir, "stats", "-o", "json", "--output-file", outPath)
+	if err != nil {
+		t.Fatalf("stats error = %v", err)
+	}
+	if strings.Contains(stdout, "total_files") {
+		t.Errorf("stats wrote its result to stdout with --output-file: %q", stdout)
+	}
+
+	data, err := ioutil.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	var stats CorpusStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		t.Fatalf("Output file is not valid JSON: %v\n%s", err, data)
+	}
+	if stats.TotalFiles != 3 {
+		t.Errorf("total_files = %d, expected 3", stats.TotalFiles)
+	}
+}
+
+func TestOutputFileErrors(t *testing.T) {
+	dir := indexWorkspace(t, []string{"a.md"})
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"Table output", []string{"stats", "--output-file", filepath.Join(dir, "out.txt")}, "needs a structured --output"},
+		{"Missing directory", []string{"stats", "-o", "json", "--output-file", filepath.Join(dir, "missing", "out.json")}, "failed to open output file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runWithOutputFile(t, dir, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, expected it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
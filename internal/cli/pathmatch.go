@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// PathMatcher decides which paths below a root a command handles, so
+// every command filters with the same semantics. Include and Exclude are
+// --pattern and --exclude globs as matchGlob matches them, with "**"
+// spanning directories; ExcludeDirs are --exclude-dir globs, matched
+// against directories. IgnoreCase folds case for all three. Ignore, when
+// set, adds the gitignore rules collected for the root. Paths are
+// relative to the root; the zero value matches every path.
+type PathMatcher struct {
+	Include     []string
+	Exclude     []string
+	ExcludeDirs []string
+	IgnoreCase  bool
+	Ignore      *IgnoreMatcher
+}
+
+// Matches reports whether the file at relPath is included and neither
+// excluded nor ignored
+func (m *PathMatcher) Matches(relPath string) bool {
+	return m.Included(relPath) && !m.Excluded(relPath) && !m.Ignored(relPath, false)
+}
+
+// Included reports whether relPath matches an include glob. No globs, or
+// the lone "*", include everything.
+func (m *PathMatcher) Included(relPath string) bool {
+	if len(m.Include) == 0 || (len(m.Include) == 1 && m.Include[0] == "*") {
+		return true
+	}
+	return m.matchAny(m.Include, relPath)
+}
+
+// Excluded reports whether relPath matches an exclude glob. The empty
+// path, i.e. the root itself, is never excluded.
+func (m *PathMatcher) Excluded(relPath string) bool {
+	return relPath != "" && m.matchAny(m.Exclude, relPath)
+}
+
+// ExcludesDir reports whether the directory at relPath matches an
+// exclude-dir glob, by its name or its path
+func (m *PathMatcher) ExcludesDir(relPath string) bool {
+	return m.matchAny(m.ExcludeDirs, relPath)
+}
+
+// ExcludesTree reports whether the directory at relPath and everything
+// below it are ruled out, by an exclude-dir glob or by an exclude glob
+// such as "build/**" that matches every path below it, so a walk need
+// not descend into it
+func (m *PathMatcher) ExcludesTree(relPath string) bool {
+	if m.ExcludesDir(relPath) {
+		return true
+	}
+	for _, pattern := range m.Exclude {
+		if !strings.HasSuffix(pattern, "/**") {
+			continue
+		}
+
+		// The directory part keeps the anchoring of the whole glob
+		dir := strings.TrimSuffix(pattern, "/**")
+		if !strings.Contains(dir, "/") {
+			dir = "/" + dir
+		}
+		if m.matchAny([]string{dir}, relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// Ignored reports whether the gitignore rules, if any, ignore relPath
+func (m *PathMatcher) Ignored(relPath string, isDir bool) bool {
+	return m.Ignore != nil && m.Ignore.Match(relPath, isDir)
+}
+
+// matchAny reports whether relPath matches one of patterns, folding case
+// with IgnoreCase
+func (m *PathMatcher) matchAny(patterns []string, relPath string) bool {
+	if m.IgnoreCase {
+		relPath = strings.ToLower(relPath)
+	}
+	for _, pattern := range patterns {
+		if m.IgnoreCase {
+			pattern = strings.ToLower(pattern)
+		}
+		if matchGlob(pattern, relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// readPatternFile reads the patterns of an --include-from or
+// --exclude-from file, one per line; blank lines and lines starting
+// with # are skipped
+func readPatternFile(flag, path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("--%s file not found: %s", flag, path)
+		}
+		return nil, fmt.Errorf("failed to open --%s file: %w", flag, err)
+	}
+	defer f.Close()
+
+	lines, err := readFileList(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read --%s file %s: %w", flag, path, err)
+	}
+
+	var patterns []string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, nil
+}
+
+// mergePatternFiles returns include and exclude with the patterns read
+// from includeFrom and excludeFrom, when given, appended; relative file
+// names are resolved against the workspace. Patterns read for inclusion
+// replace the lone default "*", which would otherwise include everything
+// anyway.
+func mergePatternFiles(config *CommandConfig, include, exclude []string, includeFrom, excludeFrom string) ([]string, []string, error) {
+	if includeFrom != "" {
+		patterns, err := readPatternFile("include-from", config.resolvePath(includeFrom))
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(patterns) > 0 {
+			if len(include) == 1 && include[0] == "*" {
+				include = nil
+			}
+			include = append(append([]string(nil), include...), patterns...)
+		}
+	}
+
+	if excludeFrom != "" {
+		patterns, err := readPatternFile("exclude-from", config.resolvePath(excludeFrom))
+		if err != nil {
+			return nil, nil, err
+		}
+		exclude = append(append([]string(nil), exclude...), patterns...)
+	}
+
+	return include, exclude, nil
+}
@@ -0,0 +1,385 @@
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPathMatcherIncluded(t *testing.T) {
+	tests := []struct {
+		name       string
+		include    []string
+		ignoreCase bool
+		path       string
+		expected   bool
+	}{
+		{"No globs include everything", nil, false, "any/file.bin", true},
+		{"Lone star includes everything", []string{"*"}, false, "any/file.bin", true},
+		{"Name glob at the root", []string{"*.md"}, false, "README.md", true},
+		{"Name glob at any depth", []string{"*.md"}, false, "docs/guide/intro.md", true},
+		{"Name glob no match", []string{"*.md"}, false, "main.go", false},
+		{"Any of several globs", []string{"*.md", "*.go"}, false, "cmd/main.go", true},
+		{"Case sensitive", []string{"*.md"}, false, "NOTES.MD", false},
+		{"Ignore case", []string{"*.md"}, true, "NOTES.MD", true},
+		{"Ignore case in the glob", []string{"*.MD"}, true, "docs/notes.md", true},
+		{"Slash glob matches the whole path", []string{"docs/*.md"}, false, "docs/a.md", true},
+		{"Slash glob does not span directories", []string{"docs/*.md"}, false, "docs/sub/a.md", false},
+		{"Leading slash is anchored", []string{"/docs/*.md"}, false, "docs/a.md", true},
+		{"Leading doublestar", []string{"**/test/*.go"}, false, "a/b/test/x.go", true},
+		{"Leading doublestar matches no directory", []string{"**/test/*.go"}, false, "test/x.go", true},
+		{"Middle doublestar", []string{"src/**/*.go"}, false, "src/a/b/c.go", true},
+		{"Middle doublestar matches no directory", []string{"src/**/*.go"}, false, "src/c.go", true},
+		{"Middle doublestar other root", []string{"src/**/*.go"}, false, "lib/a/c.go", false},
+		{"Trailing doublestar", []string{"vendor/**"}, false, "vendor/x/y.go", true},
+		{"Trailing doublestar needs something inside", []string{"vendor/**"}, false, "vendor", false},
+		{"Ignore case with doublestar", []string{"Src/**/*.GO"}, true, "src/a/main.go", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &PathMatcher{Include: tt.include, IgnoreCase: tt.ignoreCase}
+			if got := m.Included(tt.path); got != tt.expected {
+				t.Errorf("Included(%q) with %q = %v, expected %v", tt.path, tt.include, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPathMatcherExcluded(t *testing.T) {
+	tests := []struct {
+		name       string
+		exclude    []string
+		ignoreCase bool
+		path       string
+		expected   bool
+	}{
+		{"No globs exclude nothing", nil, false, "a.tmp", false},
+		{"Name glob", []string{"*.tmp"}, false, "cache/a.tmp", true},
+		{"Dotfiles", []string{".*"}, false, ".env", true},
+		{"Root is never excluded", []string{"*"}, false, "", false},
+		{"Doublestar below a directory", []string{".git/**"}, false, ".git/objects/ab", true},
+		{"Doublestar leaves siblings", []string{".git/**"}, false, "src/.gitkeep", false},
+		{"Case sensitive", []string{"*.LOG"}, false, "app.log", false},
+		{"Ignore case", []string{"*.LOG"}, true, "app.log", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &PathMatcher{Exclude: tt.exclude, IgnoreCase: tt.ignoreCase}
+			if got := m.Excluded(tt.path); got != tt.expected {
+				t.Errorf("Excluded(%q) with %q = %v, expected %v", tt.path, tt.exclude, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPathMatcherExcludesDir(t *testing.T) {
+	m := &PathMatcher{ExcludeDirs: []string{"node_modules", "build/out"}}
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"node_modules", true},
+		{"web/node_modules", true},
+		{"build/out", true},
+		{"build", false},
+		{"other/build/out", false},
+		{"Node_Modules", false},
+	}
+	for _, tt := range tests {
+		if got := m.ExcludesDir(tt.path); got != tt.expected {
+			t.Errorf("ExcludesDir(%q) = %v, expected %v", tt.path, got, tt.expected)
+		}
+	}
+
+	m.IgnoreCase = true
+	if !m.ExcludesDir("Node_Modules") {
+		t.Error("ExcludesDir(Node_Modules) = false with IgnoreCase, expected true")
+	}
+
+	// Directory globs do not exclude files
+	if m.Excluded("node_modules") {
+		t.Error("Excluded(node_modules) = true, expected exclude-dir globs to apply to directories only")
+	}
+}
+
+func TestPathMatcherExcludesTree(t *testing.T) {
+	m := &PathMatcher{
+		Exclude:     []string{".git/**", "**/node_modules/**", "*.tmp", "docs/*.md"},
+		ExcludeDirs: []string{"vendor"},
+	}
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{".git", true},
+		{"sub/.git", false},
+		{"node_modules", true},
+		{"web/node_modules", true},
+		{"vendor", true},
+		{"cache.tmp", false},
+		{"docs", false},
+		{"src", false},
+	}
+	for _, tt := range tests {
+		if got := m.ExcludesTree(tt.path); got != tt.expected {
+			t.Errorf("ExcludesTree(%q) = %v, expected %v", tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestPathMatcherIgnored(t *testing.T) {
+	var m PathMatcher
+	if m.Ignored("app.log", false) {
+		t.Error("Ignored() without rules = true, expected false")
+	}
+
+	m.Ignore = NewIgnoreMatcher()
+	m.Ignore.AddPatterns("", []string{"*.log", "!keep.log", "build/"})
+
+	tests := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"app.log", false, true},
+		{"keep.log", false, false},
+		{"build", true, true},
+		{"build/out.bin", false, true},
+		{".git", true, true},
+		{"main.go", false, false},
+	}
+	for _, tt := range tests {
+		if got := m.Ignored(tt.path, tt.isDir); got != tt.expected {
+			t.Errorf("Ignored(%q, %v) = %v, expected %v", tt.path, tt.isDir, got, tt.expected)
+		}
+	}
+}
+
+func TestPathMatcherMatches(t *testing.T) {
+	ignore := NewIgnoreMatcher()
+	ignore.AddPatterns("", []string{"generated/"})
+
+	m := &PathMatcher{
+		Include: []string{"*.go", "*.md"},
+		Exclude: []string{"*_test.go"},
+		Ignore:  ignore,
+	}
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"main.go", true},
+		{"docs/README.md", true},
+		{"main_test.go", false},
+		{"script.py", false},
+		{"generated/api.go", false},
+	}
+	for _, tt := range tests {
+		if got := m.Matches(tt.path); got != tt.expected {
+			t.Errorf("Matches(%q) = %v, expected %v", tt.path, got, tt.expected)
+		}
+	}
+
+	var zero PathMatcher
+	if !zero.Matches("any/file") {
+		t.Error("zero PathMatcher does not match, expected it to match every path")
+	}
+}
+
+func TestCommandsShareMatcherSemantics(t *testing.T) {
+	patterns := []string{"*.md", "src/**/*.go"}
+	exclude := []string{"*_test.go", "drafts/**"}
+
+	ic := &IndexCommand{patterns: patterns, excludePaths: exclude}
+	mc := &MonitorCommand{patterns: patterns, exclude: exclude}
+
+	for _, path := range []string{
+		"README.md", "drafts/idea.md", "src/a/main.go", "src/a/main_test.go", "lib/x.go", "notes.txt",
+	} {
+		index := ic.pathMatcher(nil).Matches(path)
+		if monitor := mc.pathMatcher().Matches(path); index != monitor {
+			t.Errorf("%s: index matches = %v, monitor matches = %v", path, index, monitor)
+		}
+	}
+}
+
+func TestReadPatternFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-patterns")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "patterns.txt")
+	content := "# documentation\n*.md\n\n  docs/**/*.txt  \r\n   # indented comment\n*.go\n"
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write pattern file: %v", err)
+	}
+
+	patterns, err := readPatternFile("include-from", path)
+	if err != nil {
+		t.Fatalf("readPatternFile() error = %v", err)
+	}
+	if expected := []string{"*.md", "docs/**/*.txt", "*.go"}; !reflect.DeepEqual(patterns, expected) {
+		t.Errorf("readPatternFile() = %q, expected %q", patterns, expected)
+	}
+
+	missing := filepath.Join(dir, "missing.txt")
+	if _, err := readPatternFile("exclude-from", missing); err == nil || err.Error() != "--exclude-from file not found: "+missing {
+		t.Errorf("readPatternFile() of a missing file error = %v, expected a not found error naming the flag", err)
+	}
+}
+
+func TestMergePatternFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-patterns")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "include.txt"), []byte("*.md\n"), 0644); err != nil {
+		t.Fatalf("Failed to write pattern file: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "exclude.txt"), []byte("*.tmp\n"), 0644); err != nil {
+		t.Fatalf("Failed to write pattern file: %v", err)
+	}
+
+	// Relative names resolve against the workspace
+	config := &CommandConfig{Workspace: dir}
+
+	include, exclude, err := mergePatternFiles(config, []string{"*"}, []string{"*.log"}, "include.txt", "exclude.txt")
+	if err != nil {
+		t.Fatalf("mergePatternFiles() error = %v", err)
+	}
+	if expected := []string{"*.md"}; !reflect.DeepEqual(include, expected) {
+		t.Errorf("include = %q, expected the file's patterns to replace the default", include)
+	}
+	if expected := []string{"*.log", "*.tmp"}; !reflect.DeepEqual(exclude, expected) {
+		t.Errorf("exclude = %q, expected %q", exclude, expected)
+	}
+
+	include, _, err = mergePatternFiles(config, []string{"*.go"}, nil, "include.txt", "")
+	if err != nil {
+		t.Fatalf("mergePatternFiles() error = %v", err)
+	}
+	if expected := []string{"*.go", "*.md"}; !reflect.DeepEqual(include, expected) {
+		t.Errorf("include = %q, expected the file's patterns after --pattern", include)
+	}
+
+	if _, _, err := mergePatternFiles(config, nil, nil, "", "nope.txt"); err == nil || !strings.Contains(err.Error(), "--exclude-from file not found") {
+		t.Errorf("mergePatternFiles() error = %v, expected a not found error", err)
+	}
+}
+
+func TestPatternFilesFilter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-patterns")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	root := filepath.Join(dir, "root")
+	for _, name := range []string{"README.md", "notes.txt", "main.go", "drafts/idea.md", "docs/guide.md"} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	includeFrom := filepath.Join(dir, "include.txt")
+	excludeFrom := filepath.Join(dir, "exclude.txt")
+	if err := ioutil.WriteFile(includeFrom, []byte("# docs and code\n*.md\n*.go\n"), 0644); err != nil {
+		t.Fatalf("Failed to write pattern file: %v", err)
+	}
+	if err := ioutil.WriteFile(excludeFrom, []byte("drafts/**\n"), 0644); err != nil {
+		t.Fatalf("Failed to write pattern file: %v", err)
+	}
+	expected := []string{"README.md", "docs/guide.md", "main.go"}
+
+	t.Run("index", func(t *testing.T) {
+		var mu sync.Mutex
+		var indexed []string
+		ic := &IndexCommand{
+			config:      &CommandConfig{},
+			recursive:   true,
+			maxDepth:    -1,
+			patterns:    []string{"*"},
+			includeFrom: includeFrom,
+			excludeFrom: excludeFrom,
+			maxWorkers:  2,
+			batchSize:   10,
+			indexType:   "full",
+			processFn: func(ctx context.Context, filePath string, stats *IndexStats) error {
+				mu.Lock()
+				defer mu.Unlock()
+				indexed = append(indexed, filepath.ToSlash(relativePath(root, filePath)))
+				return nil
+			},
+			indexManifestPath: filepath.Join(dir, "manifest.json"),
+		}
+
+		captureStdout(t, func() {
+			if err := ic.runIndex(nil, []string{root}); err != nil {
+				t.Fatalf("runIndex() error = %v", err)
+			}
+		})
+
+		sort.Strings(indexed)
+		if !reflect.DeepEqual(indexed, expected) {
+			t.Errorf("indexed %q, expected %q", indexed, expected)
+		}
+	})
+
+	t.Run("monitor", func(t *testing.T) {
+		mc := &MonitorCommand{
+			config:      &CommandConfig{},
+			paths:       []string{root},
+			recursive:   true,
+			maxDepth:    -1,
+			patterns:    []string{"*"},
+			includeFrom: includeFrom,
+			excludeFrom: excludeFrom,
+		}
+		if err := mc.loadPatternFiles(); err != nil {
+			t.Fatalf("loadPatternFiles() error = %v", err)
+		}
+
+		var monitored []string
+		for path := range mc.takeSnapshot() {
+			monitored = append(monitored, filepath.ToSlash(relativePath(root, path)))
+		}
+		sort.Strings(monitored)
+		if !reflect.DeepEqual(monitored, expected) {
+			t.Errorf("monitored %q, expected %q", monitored, expected)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		ic := &IndexCommand{
+			config:      &CommandConfig{},
+			patterns:    []string{"*"},
+			includeFrom: filepath.Join(dir, "missing.txt"),
+			maxWorkers:  1,
+			batchSize:   1,
+			indexType:   "full",
+		}
+		err := ic.runIndex(nil, []string{root})
+		if err == nil || !strings.Contains(err.Error(), "--include-from file not found") {
+			t.Errorf("runIndex() error = %v, expected a not found error", err)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// writePIDFile records the current process ID in path, creating its
+// directory when needed
+func writePIDFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create PID file directory: %w", err)
+	}
+
+	pid := strconv.Itoa(os.Getpid()) + "\n"
+	if err := ioutil.WriteFile(path, []byte(pid), 0644); err != nil {
+		return fmt.Errorf("failed to write PID file: %w", err)
+	}
+	return nil
+}
+
+// readPIDFile returns the process ID recorded in path
+func readPIDFile(path string) (int, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read PID file: %w", err)
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("invalid PID file %s: %q", path, strings.TrimSpace(string(data)))
+	}
+	return pid, nil
+}
+
+// removePIDFile removes path if it still records the current process, so
+// an instance never removes the PID file of one started after it
+func removePIDFile(path string) error {
+	pid, err := readPIDFile(path)
+	if err != nil || pid != os.Getpid() {
+		return nil
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove PID file: %w", err)
+	}
+	return nil
+}
+
+// stopProcess sends SIGTERM to the process recorded in the PID file at
+// path and returns its ID
+func stopProcess(path string) (int, error) {
+	pid, err := readPIDFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return pid, fmt.Errorf("failed to find process %d: %w", pid, err)
+	}
+	if err := process.Signal(syscall.SIGTERM); err != nil {
+		return pid, fmt.Errorf("failed to stop process %d: %w", pid, err)
+	}
+	return pid, nil
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestPIDFileLifecycle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-pidfile")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "run", "monitor.pid")
+	mc := &MonitorCommand{
+		config:  &CommandConfig{},
+		pidFile: path,
+	}
+
+	if err := writePIDFile(path); err != nil {
+		t.Fatalf("writePIDFile() error = %v", err)
+	}
+	pid, err := readPIDFile(path)
+	if err != nil {
+		t.Fatalf("readPIDFile() error = %v", err)
+	}
+	if pid != os.Getpid() {
+		t.Errorf("readPIDFile() = %d, expected %d", pid, os.Getpid())
+	}
+
+	captureStdout(t, func() {
+		if err := mc.gracefulShutdown(context.Background()); err != nil {
+			t.Errorf("gracefulShutdown() error = %v", err)
+		}
+	})
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected gracefulShutdown() to remove %s, stat error = %v", path, err)
+	}
+}
+
+func TestRemovePIDFileKeepsOtherProcess(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-pidfile-other")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "monitor.pid")
+	other := strconv.Itoa(os.Getpid() + 1)
+	if err := ioutil.WriteFile(path, []byte(other), 0644); err != nil {
+		t.Fatalf("Failed to write PID file: %v", err)
+	}
+
+	if err := removePIDFile(path); err != nil {
+		t.Fatalf("removePIDFile() error = %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected the PID file of another process to be kept: %v", err)
+	}
+}
+
+func TestReadPIDFileInvalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-pidfile-invalid")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"Empty", ""},
+		{"Not a number", "monitor"},
+		{"Negative", "-4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "monitor.pid")
+			if err := ioutil.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write PID file: %v", err)
+			}
+			if _, err := readPIDFile(path); err == nil {
+				t.Errorf("readPIDFile(%q) expected an error", tt.content)
+			}
+		})
+	}
+
+	if _, err := readPIDFile(filepath.Join(dir, "missing.pid")); err == nil {
+		t.Error("readPIDFile() expected an error for a missing file")
+	}
+}
+
+func TestStopProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("SIGTERM is not supported on Windows")
+	}
+
+	dir, err := ioutil.TempDir("", "stroidex-pidfile-stop")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("Cannot start a child process: %v", err)
+	}
+	defer cmd.Process.Kill()
+
+	path := filepath.Join(dir, "monitor.pid")
+	if err := ioutil.WriteFile(path, []byte(strconv.Itoa(cmd.Process.Pid)+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write PID file: %v", err)
+	}
+
+	pid, err := stopProcess(path)
+	if err != nil {
+		t.Fatalf("stopProcess() error = %v", err)
+	}
+	if pid != cmd.Process.Pid {
+		t.Errorf("stopProcess() = %d, expected %d", pid, cmd.Process.Pid)
+	}
+	if err := cmd.Wait(); err == nil {
+		t.Error("Expected the process to be terminated by the signal")
+	}
+}
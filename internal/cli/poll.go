@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// fileState is what a polling snapshot records about a file
+type fileState struct {
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+}
+
+// sameFile reports whether two states could be the same file, e.g.
+// before and after it was moved
+func (s fileState) sameFile(other fileState) bool {
+	return s.Size == other.Size && s.ModTime.Equal(other.ModTime)
+}
+
+// snapshot maps the path of every monitored file to its state
+type snapshot map[string]fileState
+
+// takeSnapshot records the monitored files below mc.paths, applying the
+// same recursion, depth, pattern and exclude rules as watching
+func (mc *MonitorCommand) takeSnapshot() snapshot {
+	snap := make(snapshot)
+	matcher := mc.pathMatcher()
+
+	for _, root := range mc.paths {
+		_ = filepath.Walk(root, func(walkPath string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil // Skip errors
+			}
+
+			rel := relativePath(root, walkPath)
+			if info.IsDir() {
+				if walkPath != root && (!mc.recursive || info.Name() == stateDirName || !withinMaxDepth(rel, mc.maxDepth)) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if isStatePath(rel) || !matcher.Matches(rel) {
+				return nil
+			}
+
+			snap[walkPath] = fileState{ModTime: info.ModTime(), Size: info.Size()}
+			return nil
+		})
+	}
+
+	return snap
+}
+
+// absoluteSnapshot returns snap keyed by absolute path, as stored in a
+// MonitorBaseline
+func absoluteSnapshot(snap snapshot) snapshot {
+	abs := make(snapshot, len(snap))
+	for path, state := range snap {
+		abs[manifestKey(path)] = state
+	}
+	return abs
+}
+
+// diffSnapshots returns the events turning prev into next, sorted by
+// path. A file removed and one created with the same state are reported
+// as a single rename.
+func diffSnapshots(prev, next snapshot, now time.Time) []FileEvent {
+	var events []FileEvent
+	var removed, created []string
+
+	for path, state := range next {
+		old, ok := prev[path]
+		switch {
+		case !ok:
+			created = append(created, path)
+		case !old.sameFile(state):
+			events = append(events, FileEvent{Path: path, Op: "write", Time: now})
+		}
+	}
+
+	for path := range prev {
+		if _, ok := next[path]; !ok {
+			removed = append(removed, path)
+		}
+	}
+	events = append(events, pairRenames(prev, next, removed, created, now)...)
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Path < events[j].Path
+	})
+	return events
+}
+
+// poll reports changes by diffing a snapshot of the monitored files
+// taken every --interval against the previous one, until ctx is done.
+// It is slower than watching but works wherever file notifications are
+// unreliable, e.g. on network file systems.
+func (mc *MonitorCommand) poll(ctx context.Context) <-chan FileEvent {
+	events := make(chan FileEvent, eventBuffer)
+	prev := mc.takeSnapshot()
+
+	go func() {
+		defer close(events)
+
+		ticker := time.NewTicker(mc.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				next := mc.takeSnapshot()
+				for _, event := range diffSnapshots(prev, next, now) {
+					select {
+					case events <- event:
+					case <-ctx.Done():
+						return
+					}
+				}
+				prev = next
+			}
+		}
+	}()
+
+	return events
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	then := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := then.Add(time.Minute)
+	now := later.Add(time.Minute)
+
+	prev := snapshot{
+		"kept.md":    {ModTime: then, Size: 10},
+		"touched.md": {ModTime: then, Size: 10},
+		"resized.md": {ModTime: then, Size: 10},
+		"deleted.md": {ModTime: then, Size: 10},
+	}
+	next := snapshot{
+		"kept.md":    {ModTime: then, Size: 10},
+		"touched.md": {ModTime: later, Size: 10},
+		"resized.md": {ModTime: then, Size: 20},
+		"new.md":     {ModTime: later, Size: 5},
+	}
+
+	expected := []FileEvent{
+		{Path: "deleted.md", Op: "remove", Time: now},
+		{Path: "new.md", Op: "create", Time: now},
+		{Path: "resized.md", Op: "write", Time: now},
+		{Path: "touched.md", Op: "write", Time: now},
+	}
+	if got := diffSnapshots(prev, next, now); !reflect.DeepEqual(got, expected) {
+		t.Errorf("diffSnapshots() = %v, expected %v", got, expected)
+	}
+}
+
+func TestMonitorTakeSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-snapshot")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, rel := range []string{"a.md", "b.txt", "sub/c.md", ".stroidex/manifest.json", "build/d.md"} {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir for %s: %v", rel, err)
+		}
+		if err := ioutil.WriteFile(path, []byte("content"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", rel, err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		recursive bool
+		expected  []string
+	}{
+		{"Top level", false, []string{"a.md"}},
+		{"Recursive", true, []string{"a.md", "sub/c.md"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := &MonitorCommand{
+				config:    &CommandConfig{},
+				paths:     []string{dir},
+				recursive: tt.recursive,
+				maxDepth:  -1,
+				patterns:  []string{"*.md"},
+				exclude:   []string{"build/**"},
+			}
+
+			snap := mc.takeSnapshot()
+			if len(snap) != len(tt.expected) {
+				t.Errorf("takeSnapshot() = %v, expected %v", snap, tt.expected)
+			}
+			for _, rel := range tt.expected {
+				if _, ok := snap[filepath.Join(dir, filepath.FromSlash(rel))]; !ok {
+					t.Errorf("takeSnapshot() is missing %s", rel)
+				}
+			}
+		})
+	}
+}
+
+func TestMonitorPollDetectsDeletion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-poll")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Present before monitoring starts
+	path := filepath.Join(dir, "existing.md")
+	if err := ioutil.WriteFile(path, []byte("content"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	mc := &MonitorCommand{
+		config:   &CommandConfig{},
+		paths:    []string{dir},
+		patterns: []string{"*"},
+		interval: 20 * time.Millisecond,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := mc.poll(ctx)
+
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+
+	if event := waitForEvent(t, events, path); event.Op != "remove" {
+		t.Errorf("event for %s = %q, expected remove", path, event.Op)
+	}
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// profileKinds maps the --profile kinds to their default --profile-out
+var profileKinds = map[string]string{
+	"cpu": "stroidex-cpu.pprof",
+	"mem": "stroidex-mem.pprof",
+}
+
+// validateProfile checks --profile and --profile-out
+func validateProfile(config *CommandConfig) error {
+	if config.Profile == "" {
+		if config.ProfileOut != "" {
+			return fmt.Errorf("--profile-out needs --profile (cpu, mem)")
+		}
+		return nil
+	}
+	if _, ok := profileKinds[config.Profile]; !ok {
+		return fmt.Errorf("invalid profile: %s (valid: cpu, mem)", config.Profile)
+	}
+	return nil
+}
+
+// startProfile creates the --profile-out file and, for a CPU profile,
+// starts profiling. The file is created up front so a bad path fails the
+// command before it runs rather than after.
+func (c *CommandConfig) startProfile() error {
+	if c.Profile == "" {
+		return nil
+	}
+	if err := c.stopProfile(); err != nil {
+		return err
+	}
+
+	path := c.ProfileOut
+	if path == "" {
+		path = profileKinds[c.Profile]
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create profile: %w", err)
+	}
+
+	if c.Profile == "cpu" {
+		if err := pprof.StartCPUProfile(file); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+	}
+	c.profileFile = file
+	return nil
+}
+
+// stopProfile finishes the profile started by startProfile: it stops CPU
+// profiling, or writes a heap profile of the memory still in use after a
+// garbage collection. It does nothing when no profile is running.
+func (c *CommandConfig) stopProfile() error {
+	file := c.profileFile
+	if file == nil {
+		return nil
+	}
+	c.profileFile = nil
+
+	var err error
+	switch c.Profile {
+	case "cpu":
+		pprof.StopCPUProfile()
+	case "mem":
+		runtime.GC()
+		err = pprof.WriteHeapProfile(file)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+	return nil
+}
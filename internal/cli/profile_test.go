@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfileWritesFile(t *testing.T) {
+	for _, kind := range []string{"cpu", "mem"} {
+		t.Run(kind, func(t *testing.T) {
+			dir := indexWorkspace(t, []string{"a.md", "b.md"})
+			out := filepath.Join(dir, kind+".pprof")
+
+			if _, err := runWithOutputFile(t, dir, "stats", "--profile", kind, "--profile-out", out); err != nil {
+				t.Fatalf("stats --profile %s error = %v", kind, err)
+			}
+
+			info, err := os.Stat(out)
+			if err != nil {
+				t.Fatalf("profile not written: %v", err)
+			}
+			if info.Size() == 0 {
+				t.Errorf("profile %s is empty", out)
+			}
+		})
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  CommandConfig
+		wantErr bool
+	}{
+		{"None", CommandConfig{}, false},
+		{"CPU", CommandConfig{Profile: "cpu"}, false},
+		{"Memory with output", CommandConfig{Profile: "mem", ProfileOut: "mem.out"}, false},
+		{"Unknown kind", CommandConfig{Profile: "block"}, true},
+		{"Output without profile", CommandConfig{ProfileOut: "cpu.out"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateProfile(&tt.config); (err != nil) != tt.wantErr {
+				t.Errorf("validateProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,10 +1,18 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
 )
 
 // ProgressType represents different types of progress bars
@@ -17,13 +25,99 @@ const (
 	ProgressTypeBytes
 )
 
-// ProgressBarStyle defines the visual style of the progress bar
+// ProgressMode selects how progress bars report progress
+type ProgressMode int
+
+const (
+	// ProgressModeTerminal draws bars with ANSI escape codes
+	ProgressModeTerminal ProgressMode = iota
+
+	// ProgressModeJSON writes one ProgressEvent per line instead, for
+	// programs wrapping the CLI
+	ProgressModeJSON
+
+	// ProgressModeOff tracks progress without reporting it
+	ProgressModeOff
+)
+
+// jsonProgressInterval is the default minimum time between progress
+// events in JSON mode
+const jsonProgressInterval = 250 * time.Millisecond
+
+// ProgressEvent is a progress update written in JSON mode. Event is
+// "progress" while the bar runs, then "done" or "failed".
+type ProgressEvent struct {
+	Event       string    `json:"event"`
+	Description string    `json:"description"`
+	Processed   int64     `json:"processed"`
+	Total       int64     `json:"total"`
+	Rate        float64   `json:"rate"`
+	ETASeconds  *float64  `json:"eta_seconds"`
+	Message     string    `json:"message,omitempty"`
+	Time        time.Time `json:"time"`
+}
+
+// spinnerFrameInterval is how long each spinner frame is shown
+const spinnerFrameInterval = 100 * time.Millisecond
+
+// indeterminateBlock is the width of the block an indeterminate bar
+// bounces, in cells
+const indeterminateBlock = 3
+
+// minRenderInterval is the default minimum time between redraws caused
+// by progress updates
+const minRenderInterval = 50 * time.Millisecond
+
+const (
+	// speedWindow is how far back the displayed speed looks
+	speedWindow = 2 * time.Second
+
+	// speedSampleInterval is the minimum spacing of recorded speed samples
+	speedSampleInterval = 100 * time.Millisecond
+
+	// speedSamples is the ring buffer size; it spans more than speedWindow
+	// at speedSampleInterval
+	speedSamples = 32
+)
+
+// progressSample records the progress value at a point in time
+type progressSample struct {
+	at    time.Time
+	value int64
+}
+
+const (
+	// defaultTerminalWidth is assumed when the output width is unknown
+	defaultTerminalWidth = 80
+
+	// minAutoBarWidth is the narrowest bar drawn in auto-width mode
+	minAutoBarWidth = 10
+)
+
+// terminalWidth reports the column width of the terminal behind w, falling
+// back to $COLUMNS and then defaultTerminalWidth
+var terminalWidth = func(w io.Writer) int {
+	if f, ok := w.(*os.File); ok {
+		if width, _, err := term.GetSize(int(f.Fd())); err == nil && width > 0 {
+			return width
+		}
+	}
+
+	if cols, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && cols > 0 {
+		return cols
+	}
+
+	return defaultTerminalWidth
+}
+
+// ProgressBarStyle defines the visual style of the progress bar.
+// A Width of 0 sizes the bar to the terminal at render time.
 type ProgressBarStyle struct {
-	Width      int
-	BarChar    string
-	EmptyChar  string
-	LeftEnd    string
-	RightEnd   string
+	Width       int
+	BarChar     string
+	EmptyChar   string
+	LeftEnd     string
+	RightEnd    string
 	ShowPercent bool
 	ShowCount   bool
 	ShowTime    bool
@@ -33,33 +127,42 @@ type ProgressBarStyle struct {
 // Default styles for progress bars
 var (
 	DefaultBarStyle = ProgressBarStyle{
-		Width:      40,
-		BarChar:    "█",
-		EmptyChar:  "░",
-		LeftEnd:    "[",
-		RightEnd:   "]",
+		Width:       0, // auto
+		BarChar:     "█",
+		EmptyChar:   "░",
+		LeftEnd:     "[",
+		RightEnd:    "]",
 		ShowPercent: true,
 		ShowCount:   true,
 		ShowTime:    true,
 		ShowSpeed:   false,
 	}
 
+	// DefaultSpinnerStyle spins through SpinnerBraille unless the
+	// spinner is given other frames
 	DefaultSpinnerStyle = ProgressBarStyle{
-		Width:      20,
-		BarChar:    "⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏",
-		EmptyChar:  " ",
+		Width:       20,
+		BarChar:     "⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏",
+		EmptyChar:   " ",
 		ShowPercent: false,
 		ShowCount:   true,
 		ShowTime:    true,
 		ShowSpeed:   false,
 	}
 
+	DefaultPercentageStyle = ProgressBarStyle{
+		ShowPercent: true,
+		ShowCount:   true,
+		ShowTime:    false,
+		ShowSpeed:   false,
+	}
+
 	DefaultBytesStyle = ProgressBarStyle{
-		Width:      40,
-		BarChar:    "=",
-		EmptyChar:  "-",
-		LeftEnd:    "[",
-		RightEnd:   "]",
+		Width:       0, // auto
+		BarChar:     "=",
+		EmptyChar:   "-",
+		LeftEnd:     "[",
+		RightEnd:    "]",
 		ShowPercent: false,
 		ShowCount:   true,
 		ShowTime:    true,
@@ -67,6 +170,15 @@ var (
 	}
 )
 
+// Frame sets for spinners. The frames of a set have the same width so a
+// shorter frame never leaves part of a longer one on screen.
+var (
+	SpinnerBraille = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	SpinnerDots    = []string{".  ", ".. ", "...", " ..", "  .", "   "}
+	SpinnerLine    = []string{"-", "\\", "|", "/"}
+	SpinnerMoon    = []string{"🌑", "🌒", "🌓", "🌔", "🌕", "🌖", "🌗", "🌘"}
+)
+
 // ProgressBar represents a customizable progress bar
 type ProgressBar struct {
 	mu           sync.Mutex
@@ -77,8 +189,22 @@ type ProgressBar struct {
 	description  string
 	startTime    time.Time
 	lastUpdate   time.Time
+	lastRender   time.Time
+	interval     time.Duration
 	active       bool
 	spinnerIndex int
+	frames       []string
+	out          io.Writer
+	now          func() time.Time
+	descWidth    int
+	group        *ProgressGroup
+	theme        Theme
+	failed       bool
+	failMessage  string
+	mode         ProgressMode
+	samples      [speedSamples]progressSample
+	sampleNext   int
+	sampleCount  int
 }
 
 // NewProgressBar creates a new progress bar
@@ -96,8 +222,12 @@ func NewProgressBarWithStyle(description string, total int64, style ProgressBarS
 		description:  description,
 		startTime:    time.Now(),
 		lastUpdate:   time.Now(),
+		interval:     minRenderInterval,
 		active:       false,
 		spinnerIndex: 0,
+		out:          os.Stderr,
+		now:          time.Now,
+		theme:        activeTheme,
 	}
 }
 
@@ -106,19 +236,77 @@ func NewSpinner(description string) *ProgressBar {
 	return NewProgressBarWithStyle(description, 0, DefaultSpinnerStyle, ProgressTypeSpinner)
 }
 
+// NewSpinnerWithFrames creates a spinner that shows frames in turn, e.g.
+// one of the predefined sets such as SpinnerDots. A frame may be any
+// string, including several runes.
+func NewSpinnerWithFrames(description string, frames []string) *ProgressBar {
+	spinner := NewSpinner(description)
+	spinner.frames = append([]string(nil), frames...)
+	return spinner
+}
+
+// NewPercentageProgress creates a percentage-only progress indicator
+func NewPercentageProgress(description string, total int64) *ProgressBar {
+	return NewProgressBarWithStyle(description, total, DefaultPercentageStyle, ProgressTypePercentage)
+}
+
 // NewBytesProgress creates a progress bar for byte operations
 func NewBytesProgress(description string, totalBytes int64) *ProgressBar {
 	return NewProgressBarWithStyle(description, totalBytes, DefaultBytesStyle, ProgressTypeBytes)
 }
 
+// WithWriter sets the writer the progress bar renders to and returns the bar
+func (pb *ProgressBar) WithWriter(w io.Writer) *ProgressBar {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	pb.out = w
+	return pb
+}
+
+// SetFrames replaces the frames a spinner shows; nil or empty restores
+// the frames of its style
+func (pb *ProgressBar) SetFrames(frames []string) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	pb.frames = append([]string(nil), frames...)
+}
+
+// WithMode sets how the bar reports progress and returns the bar. JSON
+// mode defaults to one event per jsonProgressInterval.
+func (pb *ProgressBar) WithMode(mode ProgressMode) *ProgressBar {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	pb.mode = mode
+	if mode == ProgressModeJSON && pb.interval == minRenderInterval {
+		pb.interval = jsonProgressInterval
+	}
+	return pb
+}
+
+// WithRenderInterval sets the minimum time between redraws caused by
+// Add and UpdateTo and returns the bar. Zero redraws on every update.
+func (pb *ProgressBar) WithRenderInterval(d time.Duration) *ProgressBar {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	pb.interval = d
+	return pb
+}
+
 // Start starts the progress bar
 func (pb *ProgressBar) Start() {
 	pb.mu.Lock()
 	defer pb.mu.Unlock()
 
 	pb.active = true
-	pb.startTime = time.Now()
-	pb.lastUpdate = time.Now()
+	activeBars.add(pb)
+	pb.startTime = pb.now()
+	pb.lastUpdate = pb.now()
+	pb.sampleCount = 0
+	pb.recordSample()
 
 	// Initial render
 	pb.render()
@@ -142,10 +330,11 @@ func (pb *ProgressBar) UpdateTo(value int64) {
 	}
 
 	pb.current = value
-	pb.lastUpdate = time.Now()
+	pb.lastUpdate = pb.now()
+	pb.recordSample()
 
 	if pb.active {
-		pb.render()
+		pb.throttledRender()
 	}
 }
 
@@ -162,10 +351,11 @@ func (pb *ProgressBar) Add(delta int64) {
 		pb.current = pb.total
 	}
 
-	pb.lastUpdate = time.Now()
+	pb.lastUpdate = pb.now()
+	pb.recordSample()
 
 	if pb.active {
-		pb.render()
+		pb.throttledRender()
 	}
 }
 
@@ -186,7 +376,17 @@ func (pb *ProgressBar) SetTotal(total int64) {
 
 // IncrementTotal increments the total by the specified amount
 func (pb *ProgressBar) IncrementTotal(delta int64) {
-	pb.SetTotal(pb.total + delta)
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	pb.total += delta
+	if pb.current > pb.total {
+		pb.current = pb.total
+	}
+
+	if pb.active {
+		pb.render()
+	}
 }
 
 // Description updates the progress bar description
@@ -206,21 +406,59 @@ func (pb *ProgressBar) Stop() {
 	defer pb.mu.Unlock()
 
 	pb.active = false
+	activeBars.remove(pb)
 	pb.render()
-	fmt.Println() // Move to next line after stopping
+	if pb.group == nil && pb.mode == ProgressModeTerminal {
+		fmt.Fprintln(pb.out) // Move to next line after stopping
+	}
 }
 
-// Finish completes the progress bar (100%)
+// Finish completes the progress bar (100%). A failed bar keeps its
+// failure frame.
 func (pb *ProgressBar) Finish() {
 	pb.mu.Lock()
 	defer pb.mu.Unlock()
 
+	if pb.failed {
+		return
+	}
+
 	if pb.total > 0 {
 		pb.current = pb.total
 	}
 	pb.active = false
+	activeBars.remove(pb)
 	pb.render()
-	fmt.Println() // Move to next line
+	if pb.group == nil && pb.mode == ProgressModeTerminal {
+		fmt.Fprintln(pb.out) // Move to next line
+	}
+}
+
+// Fail marks the progress bar as failed, renders it in the error color
+// with an ✗ marker and msg, and moves to the next line
+func (pb *ProgressBar) Fail(msg string) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	if pb.failed {
+		return
+	}
+
+	pb.failed = true
+	pb.failMessage = msg
+	pb.active = false
+	activeBars.remove(pb)
+	pb.render()
+	if pb.group == nil && pb.mode == ProgressModeTerminal {
+		fmt.Fprintln(pb.out) // Move to next line
+	}
+}
+
+// IsFailed returns whether the progress bar has been marked as failed
+func (pb *ProgressBar) IsFailed() bool {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return pb.failed
 }
 
 // IsActive returns whether the progress bar is currently active
@@ -241,23 +479,113 @@ func (pb *ProgressBar) GetProgress() float64 {
 	return float64(pb.current) / float64(pb.total)
 }
 
-// render renders the progress bar
+// RenderString returns the current frame exactly as render would print it,
+// without the leading carriage return
+func (pb *ProgressBar) RenderString() string {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return pb.frame()
+}
+
+// render renders the progress bar. Bars in a group hand their frame to
+// the group, which redraws its whole block of lines.
 func (pb *ProgressBar) render() {
+	pb.lastRender = pb.now()
+
+	switch pb.mode {
+	case ProgressModeJSON:
+		pb.writeEvent()
+		return
+	case ProgressModeOff:
+		return
+	}
+
+	if pb.group != nil {
+		pb.group.update(pb, pb.frame())
+		return
+	}
+
 	// Move cursor to beginning of line
-	fmt.Print("\r")
+	fmt.Fprint(pb.out, "\r"+pb.frame())
+}
 
+// writeEvent writes the current progress as a JSON line. Bars in a group
+// write through the group so concurrent bars never interleave lines.
+func (pb *ProgressBar) writeEvent() {
+	event := ProgressEvent{
+		Event:       "progress",
+		Description: pb.description,
+		Processed:   pb.current,
+		Total:       pb.total,
+		Time:        pb.now(),
+	}
+	switch {
+	case pb.failed:
+		event.Event = "failed"
+		event.Message = pb.failMessage
+	case !pb.active:
+		event.Event = "done"
+	}
+
+	if rate, ok := pb.speed(); ok && rate > 0 {
+		event.Rate = rate
+	}
+	if pb.total > 0 {
+		percent := float64(pb.current) / float64(pb.total)
+		if remaining, ok := estimateRemaining(pb.now().Sub(pb.startTime), percent); ok {
+			seconds := remaining.Seconds()
+			event.ETASeconds = &seconds
+		}
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+
+	if pb.group != nil {
+		pb.group.write(data)
+		return
+	}
+	pb.out.Write(data)
+}
+
+// throttledRender renders unless the previous frame was drawn less than
+// the render interval ago. The final frame is always drawn by Finish or
+// Stop, so skipped updates are never lost.
+func (pb *ProgressBar) throttledRender() {
+	if pb.interval > 0 && pb.now().Sub(pb.lastRender) < pb.interval {
+		return
+	}
+	pb.render()
+}
+
+// paddedDescription returns the description followed by a space, padded
+// to descWidth so bars in a group line up
+func (pb *ProgressBar) paddedDescription() string {
+	if pb.description == "" && pb.descWidth == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%-*s ", pb.descWidth, pb.description)
+}
+
+// frame builds the current progress frame
+func (pb *ProgressBar) frame() string {
 	var output strings.Builder
 
 	// Add description
-	if pb.description != "" {
-		output.WriteString(pb.description)
-		output.WriteString(" ")
-	}
+	output.WriteString(pb.paddedDescription())
 
 	switch pb.progressType {
 	case ProgressTypeBar:
 		output.WriteString(pb.renderBar())
 	case ProgressTypeSpinner:
+		if pb.failed {
+			// The marker replaces the spinner glyph
+			output.WriteString(pb.failureMarker())
+			return output.String()
+		}
 		output.WriteString(pb.renderSpinner())
 	case ProgressTypePercentage:
 		output.WriteString(pb.renderPercentage())
@@ -267,85 +595,205 @@ func (pb *ProgressBar) render() {
 		output.WriteString(pb.renderBar())
 	}
 
-	fmt.Print(output.String())
+	if pb.failed {
+		output.WriteString(" " + pb.failureMarker())
+	}
+
+	return output.String()
+}
+
+// failureMarker returns the ✗ marker and message of a failed bar, or ""
+func (pb *ProgressBar) failureMarker() string {
+	if !pb.failed {
+		return ""
+	}
+
+	marker := "✗"
+	if pb.failMessage != "" {
+		marker += " " + pb.failMessage
+	}
+	return pb.theme.Paint(pb.theme.Error, marker)
+}
+
+// fillColor returns the color code for the filled portion of the bar
+func (pb *ProgressBar) fillColor() string {
+	if pb.failed {
+		return pb.theme.Error
+	}
+	return pb.theme.Fill
 }
 
 // renderBar renders a standard progress bar
 func (pb *ProgressBar) renderBar() string {
 	if pb.total <= 0 {
-		return fmt.Sprintf("%sProcessing...%s", pb.style.LeftEnd, pb.style.RightEnd)
+		return pb.renderIndeterminate()
 	}
 
 	percent := float64(pb.current) / float64(pb.total)
-	filled := int(percent * float64(pb.style.Width))
-	empty := pb.style.Width - filled
-
-	var bar strings.Builder
-
-	// Build progress bar
-	bar.WriteString(pb.style.LeftEnd)
-	for i := 0; i < filled; i++ {
-		bar.WriteString(pb.style.BarChar)
-	}
-	for i := 0; i < empty; i++ {
-		bar.WriteString(pb.style.EmptyChar)
-	}
-	bar.WriteString(pb.style.RightEnd)
 
 	// Add additional information
 	var info strings.Builder
 
 	if pb.style.ShowPercent {
-		info.WriteString(fmt.Sprintf(" %.1f%%", percent*100))
+		info.WriteString(" " + pb.theme.Paint(pb.theme.Percent, fmt.Sprintf("%.1f%%", percent*100)))
 	}
 
 	if pb.style.ShowCount {
-		info.WriteString(fmt.Sprintf(" (%d/%d)", pb.current, pb.total))
+		info.WriteString(fmt.Sprintf(" (%s/%s)", humanizeInt(pb.current), humanizeInt(pb.total)))
 	}
 
 	if pb.style.ShowTime {
-		elapsed := time.Since(pb.startTime)
-		remaining := time.Duration(float64(elapsed) / percent * (1 - percent))
-		info.WriteString(fmt.Sprintf(" ETA: %v", remaining.Round(time.Second)))
+		info.WriteString(" ETA: " + pb.theme.Paint(pb.theme.ETA, formatETA(pb.now().Sub(pb.startTime), percent)))
 	}
 
+	return pb.buildBar(percent, info.String()) + info.String()
+}
+
+// renderIndeterminate renders a bar without a known total as a block
+// bouncing between its ends, moving one cell per spinnerFrameInterval. A
+// stopped bar is drawn full; a failed one keeps its last position.
+func (pb *ProgressBar) renderIndeterminate() string {
+	var info strings.Builder
+	if pb.style.ShowCount && pb.current > 0 {
+		info.WriteString(fmt.Sprintf(" (%s)", humanizeInt(pb.current)))
+	}
+	if pb.style.ShowTime {
+		elapsed := pb.now().Sub(pb.startTime)
+		info.WriteString(" " + pb.theme.Paint(pb.theme.ETA, elapsed.Round(time.Second).String()))
+	}
+
+	if !pb.active && !pb.failed {
+		return pb.buildBar(1, info.String()) + info.String()
+	}
+
+	width := pb.style.Width
+	if width <= 0 {
+		width = pb.autoWidth(info.String())
+	}
+	block := indeterminateBlock
+	if block > width {
+		block = width
+	}
+
+	// Walk from 0 to span and back again
+	pos := 0
+	if span := width - block; span > 0 {
+		step := int(pb.now().Sub(pb.startTime) / spinnerFrameInterval)
+		if step < 0 {
+			step = 0
+		}
+		pos = step % (2 * span)
+		if pos > span {
+			pos = 2*span - pos
+		}
+	}
+
+	var bar strings.Builder
+	bar.WriteString(pb.style.LeftEnd)
+	bar.WriteString(strings.Repeat(pb.style.EmptyChar, pos))
+	bar.WriteString(pb.theme.Paint(pb.fillColor(), strings.Repeat(pb.style.BarChar, block)))
+	bar.WriteString(strings.Repeat(pb.style.EmptyChar, width-block-pos))
+	bar.WriteString(pb.style.RightEnd)
+
 	return bar.String() + info.String()
 }
 
+// buildBar draws the bar portion for the given completion fraction. When
+// the style width is 0 the bar fills the terminal width left over by the
+// description and suffix.
+func (pb *ProgressBar) buildBar(percent float64, suffix string) string {
+	width := pb.style.Width
+	if width <= 0 {
+		width = pb.autoWidth(suffix)
+	}
+
+	filled := int(percent * float64(width))
+	if filled > width {
+		filled = width
+	}
+	empty := width - filled
+
+	var bar strings.Builder
+
+	// Build progress bar
+	bar.WriteString(pb.style.LeftEnd)
+	bar.WriteString(pb.theme.Paint(pb.fillColor(), strings.Repeat(pb.style.BarChar, filled)))
+	for i := 0; i < empty; i++ {
+		bar.WriteString(pb.style.EmptyChar)
+	}
+	bar.WriteString(pb.style.RightEnd)
+
+	return bar.String()
+}
+
+// autoWidth computes the bar width that fits the terminal alongside the
+// description and suffix, leaving the last column free to avoid wrapping
+func (pb *ProgressBar) autoWidth(suffix string) int {
+	used := utf8.RuneCountInString(pb.paddedDescription()) +
+		utf8.RuneCountInString(pb.style.LeftEnd) +
+		utf8.RuneCountInString(pb.style.RightEnd) +
+		visibleWidth(suffix) + 1
+	if pb.failed {
+		used += 1 + visibleWidth(pb.failureMarker())
+	}
+
+	width := terminalWidth(pb.out) - used
+	if width < minAutoBarWidth {
+		width = minAutoBarWidth
+	}
+	return width
+}
+
 // renderSpinner renders a spinner
 func (pb *ProgressBar) renderSpinner() string {
 	if !pb.active {
 		return "✓ Done"
 	}
 
-	// Get current spinner character
-	spinnerChars := pb.style.BarChar
-	if len(spinnerChars) == 0 {
-		spinnerChars = "⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏"
-	}
-
-	charIndex := (int(time.Since(pb.startTime) / 100 * time.Millisecond) % len(spinnerChars))
-	if charIndex < 0 || charIndex >= len(spinnerChars) {
-		charIndex = 0
+	// Advance one frame per interval
+	frames := pb.spinnerFrames()
+	frameIndex := int(pb.now().Sub(pb.startTime)/spinnerFrameInterval) % len(frames)
+	if frameIndex < 0 {
+		frameIndex = 0
 	}
 
 	var output strings.Builder
-	output.WriteString(string(spinnerChars[charIndex]))
+	output.WriteString(pb.theme.Paint(pb.theme.Fill, frames[frameIndex]))
 
 	// Add count if total is specified
 	if pb.total > 0 && pb.style.ShowCount {
-		output.WriteString(fmt.Sprintf(" (%d/%d)", pb.current, pb.total))
+		output.WriteString(fmt.Sprintf(" (%s/%s)", humanizeInt(pb.current), humanizeInt(pb.total)))
 	}
 
 	// Add elapsed time
 	if pb.style.ShowTime {
-		elapsed := time.Since(pb.startTime)
-		output.WriteString(fmt.Sprintf(" %v", elapsed.Round(time.Second)))
+		elapsed := pb.now().Sub(pb.startTime)
+		output.WriteString(" " + pb.theme.Paint(pb.theme.ETA, elapsed.Round(time.Second).String()))
 	}
 
 	return output.String()
 }
 
+// spinnerFrames returns the frames the spinner cycles through: those set
+// with SetFrames, or else one per rune of the style's BarChar, so
+// multibyte glyphs are never split
+func (pb *ProgressBar) spinnerFrames() []string {
+	if len(pb.frames) > 0 {
+		return pb.frames
+	}
+
+	chars := pb.style.BarChar
+	if len(chars) == 0 {
+		return SpinnerBraille
+	}
+
+	frames := make([]string, 0, utf8.RuneCountInString(chars))
+	for _, r := range chars {
+		frames = append(frames, string(r))
+	}
+	return frames
+}
+
 // renderPercentage renders a simple percentage indicator
 func (pb *ProgressBar) renderPercentage() string {
 	if pb.total <= 0 {
@@ -353,7 +801,13 @@ func (pb *ProgressBar) renderPercentage() string {
 	}
 
 	percent := float64(pb.current) / float64(pb.total) * 100
-	return fmt.Sprintf("%.1f%% (%d/%d)", percent, pb.current, pb.total)
+	output := pb.theme.Paint(pb.theme.Percent, fmt.Sprintf("%.1f%%", percent))
+
+	if pb.style.ShowCount {
+		output += fmt.Sprintf(" (%s/%s)", humanizeInt(pb.current), humanizeInt(pb.total))
+	}
+
+	return output
 }
 
 // renderBytes renders a bytes progress indicator
@@ -363,20 +817,6 @@ func (pb *ProgressBar) renderBytes() string {
 	}
 
 	percent := float64(pb.current) / float64(pb.total)
-	filled := int(percent * float64(pb.style.Width))
-	empty := pb.style.Width - filled
-
-	var bar strings.Builder
-
-	// Build progress bar
-	bar.WriteString(pb.style.LeftEnd)
-	for i := 0; i < filled; i++ {
-		bar.WriteString(pb.style.BarChar)
-	}
-	for i := 0; i < empty; i++ {
-		bar.WriteString(pb.style.EmptyChar)
-	}
-	bar.WriteString(pb.style.RightEnd)
 
 	// Add additional information
 	var info strings.Builder
@@ -389,19 +829,125 @@ func (pb *ProgressBar) renderBytes() string {
 	}
 
 	if pb.style.ShowTime {
-		elapsed := time.Since(pb.startTime)
-		info.WriteString(fmt.Sprintf(" %v", elapsed.Round(time.Second)))
+		elapsed := pb.now().Sub(pb.startTime)
+		info.WriteString(" " + pb.theme.Paint(pb.theme.ETA, elapsed.Round(time.Second).String()))
 	}
 
 	if pb.style.ShowSpeed && pb.current > 0 {
-		elapsed := time.Since(pb.startTime).Seconds()
-		if elapsed > 0 {
-			speed := float64(pb.current) / elapsed
+		if speed, ok := pb.speed(); ok {
 			info.WriteString(fmt.Sprintf(" %s/s", formatBytes(int64(speed))))
 		}
 	}
 
-	return bar.String() + info.String()
+	return pb.buildBar(percent, info.String()) + info.String()
+}
+
+// recordSample adds the current progress to the speed ring buffer,
+// keeping samples at least speedSampleInterval apart
+func (pb *ProgressBar) recordSample() {
+	now := pb.now()
+
+	if pb.sampleCount > 0 {
+		last := pb.samples[(pb.sampleNext+speedSamples-1)%speedSamples]
+		if now.Sub(last.at) < speedSampleInterval {
+			return
+		}
+	}
+
+	pb.samples[pb.sampleNext] = progressSample{at: now, value: pb.current}
+	pb.sampleNext = (pb.sampleNext + 1) % speedSamples
+	if pb.sampleCount < speedSamples {
+		pb.sampleCount++
+	}
+}
+
+// speed returns the rate of progress per second over the last
+// speedWindow. The baseline is the newest sample at least speedWindow
+// old, or the oldest sample if none is that old, so a stall drives the
+// rate towards zero. Without samples it falls back to the average since
+// start.
+func (pb *ProgressBar) speed() (float64, bool) {
+	now := pb.now()
+
+	if pb.sampleCount == 0 {
+		elapsed := now.Sub(pb.startTime).Seconds()
+		if elapsed <= 0 {
+			return 0, false
+		}
+		return float64(pb.current) / elapsed, true
+	}
+
+	// Walk from newest to oldest
+	base := pb.samples[(pb.sampleNext+speedSamples-1)%speedSamples]
+	for i := 1; i <= pb.sampleCount; i++ {
+		base = pb.samples[(pb.sampleNext+speedSamples-i)%speedSamples]
+		if now.Sub(base.at) >= speedWindow {
+			break
+		}
+	}
+
+	elapsed := now.Sub(base.at).Seconds()
+	if elapsed <= 0 {
+		return 0, false
+	}
+	return float64(pb.current-base.value) / elapsed, true
+}
+
+// maxETA is the longest remaining time worth displaying; anything longer
+// is an artifact of very early or stalled progress
+const maxETA = 99 * time.Hour
+
+// estimateRemaining estimates the remaining time from the elapsed time
+// and the completed fraction, reporting false until an estimate is
+// meaningful
+func estimateRemaining(elapsed time.Duration, percent float64) (time.Duration, bool) {
+	if percent <= 0 || math.IsNaN(percent) || math.IsInf(percent, 0) {
+		return 0, false
+	}
+	if percent >= 1 {
+		return 0, true
+	}
+
+	remaining := float64(elapsed) / percent * (1 - percent)
+	if remaining < 0 {
+		remaining = 0
+	}
+	if remaining > float64(maxETA) {
+		return 0, false
+	}
+
+	return time.Duration(remaining), true
+}
+
+// formatETA formats the estimated remaining time, or "--" until an
+// estimate is meaningful
+func formatETA(elapsed time.Duration, percent float64) string {
+	remaining, ok := estimateRemaining(elapsed, percent)
+	if !ok {
+		return "--"
+	}
+	return remaining.Round(time.Second).String()
+}
+
+// humanizeInt formats n with a comma between groups of three digits,
+// e.g. 1,450,000, regardless of locale
+func humanizeInt(n int64) string {
+	digits := strconv.FormatInt(n, 10)
+
+	sign := ""
+	if digits[0] == '-' {
+		sign, digits = "-", digits[1:]
+	}
+
+	var out strings.Builder
+	out.WriteString(sign)
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			out.WriteByte(',')
+		}
+		out.WriteRune(d)
+	}
+	return out.String()
 }
 
 // formatBytes formats bytes into human readable string
@@ -410,33 +956,173 @@ func formatBytes(bytes int64) string {
 	if bytes < unit {
 		return fmt.Sprintf("%d B", bytes)
 	}
+	const units = "KMGTPE"
 	div, exp := int64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
+	for n := bytes / unit; n >= unit && exp < len(units)-1; n /= unit {
 		div *= unit
 		exp++
 	}
-	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), units[exp])
+}
+
+// byteUnits maps size suffixes accepted by parseBytes to multipliers.
+// KB, MB, ... are decimal; KiB, MiB, ... and the single letters K, M, ...
+// are binary, matching formatBytes.
+var byteUnits = map[string]float64{
+	"":    1,
+	"B":   1,
+	"KB":  1e3,
+	"MB":  1e6,
+	"GB":  1e9,
+	"TB":  1e12,
+	"PB":  1e15,
+	"EB":  1e18,
+	"K":   1 << 10,
+	"M":   1 << 20,
+	"G":   1 << 30,
+	"T":   1 << 40,
+	"P":   1 << 50,
+	"E":   1 << 60,
+	"KIB": 1 << 10,
+	"MIB": 1 << 20,
+	"GIB": 1 << 30,
+	"TIB": 1 << 40,
+	"PIB": 1 << 50,
+	"EIB": 1 << 60,
+}
+
+// parseBytes parses a human readable size such as "512", "10MB",
+// "1.5 GiB" or "64k" into bytes
+func parseBytes(s string) (int64, error) {
+	trimmed := strings.TrimSpace(s)
+
+	i := 0
+	for i < len(trimmed) && (trimmed[i] >= '0' && trimmed[i] <= '9' || trimmed[i] == '.') {
+		i++
+	}
+	if i == 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	value, err := strconv.ParseFloat(trimmed[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	unit, ok := byteUnits[strings.ToUpper(strings.TrimSpace(trimmed[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid size unit in %q", s)
+	}
+
+	bytes := value * unit
+	if bytes >= math.MaxInt64 {
+		return 0, fmt.Errorf("size %q is too large", s)
+	}
+
+	return int64(bytes), nil
 }
 
-// ProgressGroup manages multiple progress bars
+// ProgressGroup manages multiple progress bars, drawing each bar on its
+// own line. The group tracks how many lines it occupies and, on every
+// refresh, moves the cursor up to the first of them, redraws the block and
+// leaves the cursor on the line below it.
 type ProgressGroup struct {
+	mu     sync.Mutex
 	bars   []*ProgressBar
+	frames []string
+	lines  int
 	width  int
 	active bool
+	out    io.Writer
+	mode   ProgressMode
 }
 
 // NewProgressGroup creates a new progress group
 func NewProgressGroup() *ProgressGroup {
 	return &ProgressGroup{
 		bars:   make([]*ProgressBar, 0),
-		width:   80,
-		active:  false,
+		width:  80,
+		active: false,
+		out:    os.Stderr,
 	}
 }
 
-// AddBar adds a progress bar to the group
+// WithWriter sets the writer the group and its bars render to and returns
+// the group
+func (pg *ProgressGroup) WithWriter(w io.Writer) *ProgressGroup {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	pg.out = w
+	return pg
+}
+
+// WithMode sets how the group and the bars added to it report progress
+// and returns the group
+func (pg *ProgressGroup) WithMode(mode ProgressMode) *ProgressGroup {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	pg.mode = mode
+	return pg
+}
+
+// AddBar adds a progress bar to the group and pads all descriptions to
+// the same width so auto-sized bars line up
 func (pg *ProgressGroup) AddBar(bar *ProgressBar) {
+	pg.mu.Lock()
 	pg.bars = append(pg.bars, bar)
+	pg.frames = append(pg.frames, "")
+	bars := append([]*ProgressBar(nil), pg.bars...)
+	out := pg.out
+	mode := pg.mode
+	pg.mu.Unlock()
+
+	if mode != ProgressModeTerminal {
+		bar.WithMode(mode)
+	}
+
+	descWidth := 0
+	for _, b := range bars {
+		if n := utf8.RuneCountInString(b.description); n > descWidth {
+			descWidth = n
+		}
+	}
+
+	for _, b := range bars {
+		b.mu.Lock()
+		b.descWidth = descWidth
+		if b == bar {
+			b.out = out
+			b.group = pg
+		}
+		b.mu.Unlock()
+	}
+}
+
+// RemoveBar removes a progress bar from the group and redraws the
+// remaining bars, freeing the line it occupied. The bar stops, as it no
+// longer has a line to draw on.
+func (pg *ProgressGroup) RemoveBar(bar *ProgressBar) {
+	bar.mu.Lock()
+	if bar.group == pg {
+		bar.group = nil
+	}
+	bar.active = false
+	activeBars.remove(bar)
+	bar.mu.Unlock()
+
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	for i, b := range pg.bars {
+		if b == bar {
+			pg.bars = append(pg.bars[:i], pg.bars[i+1:]...)
+			pg.frames = append(pg.frames[:i], pg.frames[i+1:]...)
+			pg.redraw()
+			return
+		}
+	}
 }
 
 // NewBar creates and adds a new progress bar to the group
@@ -446,6 +1132,13 @@ func (pg *ProgressGroup) NewBar(description string, total int64) *ProgressBar {
 	return bar
 }
 
+// NewPercentage creates and adds a new percentage indicator to the group
+func (pg *ProgressGroup) NewPercentage(description string, total int64) *ProgressBar {
+	bar := NewPercentageProgress(description, total)
+	pg.AddBar(bar)
+	return bar
+}
+
 // NewSpinner creates and adds a new spinner to the group
 func (pg *ProgressGroup) NewSpinner(description string) *ProgressBar {
 	spinner := NewSpinner(description)
@@ -453,38 +1146,123 @@ func (pg *ProgressGroup) NewSpinner(description string) *ProgressBar {
 	return spinner
 }
 
+// update stores the latest frame of bar and redraws the group
+func (pg *ProgressGroup) update(bar *ProgressBar, frame string) {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	for i, b := range pg.bars {
+		if b == bar {
+			pg.frames[i] = frame
+			break
+		}
+	}
+	pg.redraw()
+}
+
+// write writes a JSON progress event of one of the group's bars
+func (pg *ProgressGroup) write(data []byte) {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	pg.out.Write(data)
+}
+
+// redraw moves the cursor to the top of the block the group occupies,
+// draws one line per bar and clears any lines left over from a taller
+// block. The cursor ends on the line below the block. Groups not drawing
+// to a terminal have nothing to redraw. Callers must hold pg.mu.
+func (pg *ProgressGroup) redraw() {
+	if pg.mode != ProgressModeTerminal {
+		return
+	}
+
+	var output strings.Builder
+
+	if pg.lines > 0 {
+		output.WriteString(fmt.Sprintf("\033[%dA", pg.lines))
+	}
+
+	for _, frame := range pg.frames {
+		output.WriteString("\r\033[K")
+		output.WriteString(frame)
+		output.WriteString("\n")
+	}
+
+	if len(pg.frames) < pg.lines {
+		output.WriteString("\r\033[J") // Clear lines of removed bars
+	}
+
+	pg.lines = len(pg.frames)
+	fmt.Fprint(pg.out, output.String())
+}
+
 // Start starts all progress bars in the group
 func (pg *ProgressGroup) Start() {
-	pg.active = true
-	for _, bar := range pg.bars {
+	pg.setActive(true)
+	for _, bar := range pg.snapshot() {
 		bar.Start()
 	}
 }
 
 // Stop stops all progress bars in the group
 func (pg *ProgressGroup) Stop() {
-	pg.active = false
-	for _, bar := range pg.bars {
+	for _, bar := range pg.snapshot() {
 		bar.Stop()
 	}
+	pg.release()
 }
 
 // Finish finishes all progress bars in the group
 func (pg *ProgressGroup) Finish() {
-	pg.active = false
-	for _, bar := range pg.bars {
+	for _, bar := range pg.snapshot() {
 		bar.Finish()
 	}
+	pg.release()
 }
 
-// Clear clears the progress bars from screen
+// Clear clears the progress bars from screen, leaving the cursor where
+// the group's first line was
 func (pg *ProgressGroup) Clear() {
-	for i := 0; i < len(pg.bars)+1; i++ {
-		fmt.Print("\r\033[K") // Clear current line
-		if i < len(pg.bars) {
-			fmt.Print("\033[A") // Move cursor up
-		}
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	if pg.mode != ProgressModeTerminal {
+		return
 	}
+
+	if pg.lines > 0 {
+		fmt.Fprintf(pg.out, "\033[%dA", pg.lines) // Move cursor up
+	}
+	fmt.Fprint(pg.out, "\r\033[J") // Clear to end of screen
+	pg.lines = 0
+}
+
+// snapshot returns a copy of the group's bars so they can be driven
+// without holding the group lock
+func (pg *ProgressGroup) snapshot() []*ProgressBar {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	return append([]*ProgressBar(nil), pg.bars...)
+}
+
+// setActive records whether the group is running
+func (pg *ProgressGroup) setActive(active bool) {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	pg.active = active
+}
+
+// release marks the group stopped and leaves its final frames on screen,
+// so later output starts below them instead of redrawing over them
+func (pg *ProgressGroup) release() {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	pg.active = false
+	pg.lines = 0
 }
 
 // SimpleProgress creates a simple one-line progress message
@@ -495,4 +1273,85 @@ func SimpleProgress(message string) {
 // ClearLine clears the current line
 func ClearLine() {
 	fmt.Print("\r\033[K")
-}
\ No newline at end of file
+}
+
+// ClearLine clears the line the progress bar renders on
+func (pb *ProgressBar) ClearLine() {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	fmt.Fprint(pb.out, "\r\033[K")
+}
+
+// barRegistry tracks the progress bars that are running, so an
+// interrupted command can take them off the screen
+type barRegistry struct {
+	mu   sync.Mutex
+	bars map[*ProgressBar]struct{}
+}
+
+// activeBars holds every started progress bar not yet stopped, finished
+// or failed
+var activeBars = &barRegistry{bars: make(map[*ProgressBar]struct{})}
+
+// add records pb as running
+func (r *barRegistry) add(pb *ProgressBar) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.bars[pb] = struct{}{}
+}
+
+// remove forgets pb
+func (r *barRegistry) remove(pb *ProgressBar) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.bars, pb)
+}
+
+// drain returns the running bars and empties the registry
+func (r *barRegistry) drain() []*ProgressBar {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bars := make([]*ProgressBar, 0, len(r.bars))
+	for pb := range r.bars {
+		bars = append(bars, pb)
+	}
+	r.bars = make(map[*ProgressBar]struct{})
+	return bars
+}
+
+// restoreProgress clears every running progress bar from the terminal
+// and stops it, leaving the cursor at the start of an empty line. It is
+// for commands cut short by a panic or signal, whose bars would
+// otherwise leave a half-drawn line behind.
+func restoreProgress() {
+	groups := make(map[*ProgressGroup]bool)
+	for _, pb := range activeBars.drain() {
+		pb.mu.Lock()
+		pb.active = false
+		group, mode := pb.group, pb.mode
+		if group == nil && mode == ProgressModeTerminal {
+			fmt.Fprint(pb.out, "\r\033[K")
+		}
+		pb.mu.Unlock()
+
+		if group != nil && !groups[group] {
+			groups[group] = true
+			group.Clear()
+			group.release()
+		}
+	}
+}
+
+// restoreProgressOnPanic is deferred by Execute: when the command panics
+// it restores the terminal from running progress bars and lets the
+// panic continue
+func restoreProgressOnPanic() {
+	if r := recover(); r != nil {
+		restoreProgress()
+		panic(r)
+	}
+}
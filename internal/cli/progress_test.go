@@ -0,0 +1,775 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestProgressBarWriter(t *testing.T) {
+	var buf bytes.Buffer
+	pb := NewProgressBar("Writing", 10).WithWriter(&buf).WithRenderInterval(0)
+
+	pb.Start()
+	pb.UpdateTo(5)
+	pb.ClearLine()
+	pb.Finish()
+
+	output := buf.String()
+
+	if !strings.Contains(output, "Writing") {
+		t.Errorf("Expected description in output, got %q", output)
+	}
+
+	if !strings.Contains(output, "(5/10)") {
+		t.Errorf("Expected intermediate frame in output, got %q", output)
+	}
+
+	if !strings.Contains(output, "\r\033[K") {
+		t.Errorf("Expected ClearLine escape in output, got %q", output)
+	}
+
+	if !strings.HasSuffix(output, "\n") {
+		t.Errorf("Expected Finish to end with a newline, got %q", output)
+	}
+}
+
+func TestProgressGroupWriter(t *testing.T) {
+	var buf bytes.Buffer
+	pg := NewProgressGroup().WithWriter(&buf)
+
+	bar := pg.NewBar("Bar", 4)
+	spinner := pg.NewSpinner("Spinner")
+
+	bar.Start()
+	bar.Finish()
+	spinner.Start()
+	spinner.Stop()
+
+	output := buf.String()
+	if !strings.Contains(output, "Bar") || !strings.Contains(output, "Spinner") {
+		t.Errorf("Expected both group bars to render to the group writer, got %q", output)
+	}
+}
+
+func TestProgressDefaultsToStderr(t *testing.T) {
+	// Stdout carries results, e.g. -o json, so progress stays off it
+	if out := NewProgressBar("Bar", 1).out; out != os.Stderr {
+		t.Errorf("NewProgressBar() writes to %v, expected os.Stderr", out)
+	}
+	if out := NewProgressGroup().out; out != os.Stderr {
+		t.Errorf("NewProgressGroup() writes to %v, expected os.Stderr", out)
+	}
+}
+
+func TestIncrementTotalConcurrent(t *testing.T) {
+	pb := NewProgressBar("Bar", 0).WithWriter(io.Discard)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				pb.IncrementTotal(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if pb.total != 1000 {
+		t.Errorf("total = %d after concurrent increments, expected 1000", pb.total)
+	}
+}
+
+func TestRestoreProgressOnPanic(t *testing.T) {
+	var buf bytes.Buffer
+	pb := NewProgressBar("Indexing", 10).WithWriter(&buf).WithRenderInterval(0)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("Expected the panic to continue, got %v", r)
+			}
+		}()
+		defer restoreProgressOnPanic()
+
+		pb.Start()
+		pb.UpdateTo(4)
+		panic("boom")
+	}()
+
+	output := buf.String()
+	if !strings.HasSuffix(output, "\r\033[K") {
+		t.Errorf("Expected the bar's line to be cleared, got %q", output)
+	}
+	if pb.IsActive() {
+		t.Error("Expected the bar to be stopped")
+	}
+	if bars := activeBars.drain(); len(bars) != 0 {
+		t.Errorf("Expected no bars left registered, got %d", len(bars))
+	}
+
+	// A late update must not redraw over the cleared line
+	buf.Reset()
+	pb.UpdateTo(8)
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output after restore, got %q", buf.String())
+	}
+}
+
+func TestRestoreProgressClearsGroup(t *testing.T) {
+	var buf bytes.Buffer
+	pg := NewProgressGroup().WithWriter(&buf)
+	pg.NewBar("First", 4)
+	pg.NewBar("Second", 4)
+	pg.Start()
+
+	buf.Reset()
+	restoreProgress()
+
+	if got, want := buf.String(), "\033[2A\r\033[J"; got != want {
+		t.Errorf("Expected the group block to be cleared with %q, got %q", want, got)
+	}
+	if bars := activeBars.drain(); len(bars) != 0 {
+		t.Errorf("Expected no bars left registered, got %d", len(bars))
+	}
+}
+
+func TestRenderBarETA(t *testing.T) {
+	tests := []struct {
+		name    string
+		current int64
+		wantETA string
+	}{
+		{"Zero progress", 0, "ETA: --"},
+		{"Half done", 50, "ETA: "},
+		{"Complete", 100, "ETA: 0s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pb := NewProgressBar("ETA", 100)
+			pb.startTime = time.Now().Add(-10 * time.Second)
+			pb.current = tt.current
+
+			frame := pb.renderBar()
+
+			if strings.Contains(frame, "Inf") || strings.Contains(frame, "NaN") {
+				t.Errorf("renderBar() at %d%% contains Inf/NaN: %q", tt.current, frame)
+			}
+			if !strings.Contains(frame, tt.wantETA) {
+				t.Errorf("renderBar() = %q, expected it to contain %q", frame, tt.wantETA)
+			}
+		})
+	}
+}
+
+func TestRenderIndeterminateBar(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := base
+
+	style := DefaultBarStyle
+	style.Width = 6
+	style.ShowTime = false
+	pb := NewProgressBarWithStyle("Scanning", 0, style, ProgressTypeBar)
+	pb.now = func() time.Time { return clock }
+	pb.startTime = base
+	pb.active = true
+
+	// The block moves one cell per tick and bounces off both ends
+	want := []string{
+		"[███░░░]",
+		"[░███░░]",
+		"[░░███░]",
+		"[░░░███]",
+		"[░░███░]",
+		"[░███░░]",
+		"[███░░░]",
+		"[░███░░]",
+	}
+	for i, frame := range want {
+		clock = base.Add(time.Duration(i) * spinnerFrameInterval)
+		if got := pb.renderBar(); got != frame {
+			t.Errorf("Tick %d = %q, expected %q", i, got, frame)
+		}
+	}
+
+	pb.current = 12
+	if got := pb.renderBar(); !strings.HasSuffix(got, " (12)") {
+		t.Errorf("Expected the count without a total, got %q", got)
+	}
+
+	pb.active = false
+	if got := pb.renderBar(); !strings.HasPrefix(got, "[██████]") {
+		t.Errorf("Expected a stopped bar to be drawn full, got %q", got)
+	}
+}
+
+func TestFormatETA(t *testing.T) {
+	tests := []struct {
+		name     string
+		elapsed  time.Duration
+		percent  float64
+		expected string
+	}{
+		{"No progress", time.Second, 0, "--"},
+		{"Negative progress", time.Second, -0.5, "--"},
+		{"Half done", 10 * time.Second, 0.5, "10s"},
+		{"Done", 10 * time.Second, 1, "0s"},
+		{"Absurdly far off", 24 * time.Hour, 0.0001, "--"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatETA(tt.elapsed, tt.percent); got != tt.expected {
+				t.Errorf("formatETA(%v, %v) = %s, expected %s", tt.elapsed, tt.percent, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderSpinnerFrames(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := base
+
+	pb := NewSpinner("Spinning")
+	pb.now = func() time.Time { return clock }
+	pb.startTime = base
+	pb.active = true
+
+	frames := []rune(DefaultSpinnerStyle.BarChar)
+	for i := 0; i < len(frames)*2; i++ {
+		clock = base.Add(time.Duration(i) * spinnerFrameInterval)
+
+		frame := []rune(pb.renderSpinner())
+		if frame[0] != frames[i%len(frames)] {
+			t.Errorf("Frame %d = %q, expected %q", i, frame[0], frames[i%len(frames)])
+		}
+	}
+}
+
+func TestSpinnerCustomFrames(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := base
+
+	frames := []string{"[=  ]", "[ = ]", "[  =]", "🌕🌑"}
+	pb := NewSpinnerWithFrames("Spinning", frames)
+	pb.now = func() time.Time { return clock }
+	pb.startTime = base
+	pb.active = true
+
+	for i := 0; i < len(frames)*2; i++ {
+		clock = base.Add(time.Duration(i) * spinnerFrameInterval)
+
+		if frame := pb.renderSpinner(); !strings.HasPrefix(frame, frames[i%len(frames)]+" ") {
+			t.Errorf("Frame %d = %q, expected it to start with %q", i, frame, frames[i%len(frames)])
+		}
+	}
+
+	pb.SetFrames(SpinnerLine)
+	for i, want := range SpinnerLine {
+		clock = base.Add(time.Duration(i) * spinnerFrameInterval)
+
+		if frame := pb.renderSpinner(); !strings.HasPrefix(frame, want+" ") {
+			t.Errorf("Frame %d after SetFrames = %q, expected it to start with %q", i, frame, want)
+		}
+	}
+
+	pb.SetFrames(nil)
+	clock = base
+	if frame := pb.renderSpinner(); !strings.HasPrefix(frame, SpinnerBraille[0]) {
+		t.Errorf("Expected SetFrames(nil) to restore the style frames, got %q", frame)
+	}
+}
+
+func TestRenderStringSnapshots(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	newFixed := func(pb *ProgressBar, current int64, elapsed time.Duration) *ProgressBar {
+		pb.startTime = base
+		pb.now = func() time.Time { return base.Add(elapsed) }
+		pb.current = current
+		pb.active = true
+		pb.style.Width = 40
+		return pb
+	}
+
+	tests := []struct {
+		name     string
+		pb       *ProgressBar
+		expected string
+	}{
+		{
+			name:     "Bar",
+			pb:       newFixed(NewProgressBar("Test", 10), 5, 10*time.Second),
+			expected: "Test [" + strings.Repeat("█", 20) + strings.Repeat("░", 20) + "] 50.0% (5/10) ETA: 10s",
+		},
+		{
+			name:     "Spinner",
+			pb:       newFixed(NewSpinner("Spin"), 0, 0),
+			expected: "Spin ⠋ 0s",
+		},
+		{
+			name:     "Percentage",
+			pb:       newFixed(NewProgressBarWithStyle("Pct", 4, DefaultBarStyle, ProgressTypePercentage), 1, time.Second),
+			expected: "Pct 25.0% (1/4)",
+		},
+		{
+			name:     "Bytes",
+			pb:       newFixed(NewBytesProgress("Copy", 2048), 1024, 2*time.Second),
+			expected: "Copy [" + strings.Repeat("=", 20) + strings.Repeat("-", 20) + "] 1.0 KiB/2.0 KiB 2s 512 B/s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.pb.RenderString(); got != tt.expected {
+				t.Errorf("RenderString() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderMatchesRenderString(t *testing.T) {
+	var buf bytes.Buffer
+	pb := NewProgressBar("Test", 10).WithWriter(&buf)
+	fixed := pb.startTime.Add(time.Second)
+	pb.now = func() time.Time { return fixed }
+	pb.current = 3
+
+	pb.mu.Lock()
+	pb.render()
+	pb.mu.Unlock()
+
+	if buf.String() != "\r"+pb.RenderString() {
+		t.Errorf("render() wrote %q, expected %q", buf.String(), "\r"+pb.RenderString())
+	}
+}
+
+func TestAutoWidthBar(t *testing.T) {
+	oldWidth := terminalWidth
+	defer func() { terminalWidth = oldWidth }()
+
+	tests := []struct {
+		name      string
+		termWidth int
+	}{
+		{"Wide terminal", 120},
+		{"Standard terminal", 80},
+		{"Narrow terminal", 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			terminalWidth = func(io.Writer) int { return tt.termWidth }
+
+			pb := NewProgressBar("Indexing files", 100)
+			pb.current = 42
+
+			frame := pb.RenderString()
+			if n := utf8.RuneCountInString(frame); n != tt.termWidth-1 {
+				t.Errorf("Frame width = %d, expected %d: %q", n, tt.termWidth-1, frame)
+			}
+		})
+	}
+
+	t.Run("Clamped to minimum", func(t *testing.T) {
+		terminalWidth = func(io.Writer) int { return 20 }
+
+		pb := NewProgressBar("Indexing files", 100)
+		frame := pb.RenderString()
+
+		bar := frame[strings.Index(frame, "[")+1 : strings.Index(frame, "]")]
+		if n := utf8.RuneCountInString(bar); n != minAutoBarWidth {
+			t.Errorf("Bar width = %d, expected minimum %d", n, minAutoBarWidth)
+		}
+	})
+}
+
+func TestProgressGroupAlignsAutoWidthBars(t *testing.T) {
+	oldWidth := terminalWidth
+	defer func() { terminalWidth = oldWidth }()
+	terminalWidth = func(io.Writer) int { return 80 }
+
+	pg := NewProgressGroup().WithWriter(&bytes.Buffer{})
+	short := pg.NewBar("A", 10)
+	long := pg.NewBar("A much longer description", 10)
+
+	shortFrame := short.RenderString()
+	longFrame := long.RenderString()
+
+	if strings.Index(shortFrame, "[") != strings.Index(longFrame, "[") {
+		t.Errorf("Bars do not line up:\n%q\n%q", shortFrame, longFrame)
+	}
+
+	if utf8.RuneCountInString(shortFrame) != utf8.RuneCountInString(longFrame) {
+		t.Errorf("Bars have different widths:\n%q\n%q", shortFrame, longFrame)
+	}
+}
+
+func TestProgressGroupStacksBars(t *testing.T) {
+	var buf bytes.Buffer
+	pg := NewProgressGroup().WithWriter(&buf)
+
+	bars := []*ProgressBar{
+		pg.NewBar("First", 10),
+		pg.NewBar("Second", 10),
+		pg.NewBar("Third", 10),
+	}
+
+	pg.Start()
+	for i := int64(1); i <= 10; i++ {
+		for _, bar := range bars {
+			bar.UpdateTo(i)
+		}
+	}
+	pg.Finish()
+
+	output := buf.String()
+
+	// Every cursor-up must climb exactly the lines drawn since the
+	// previous one, so each redraw starts at the top of the block
+	cursorUp := regexp.MustCompile(`\033\[(\d+)A`)
+	last := 0
+	ups := 0
+	for _, loc := range cursorUp.FindAllStringSubmatchIndex(output, -1) {
+		n, _ := strconv.Atoi(output[loc[2]:loc[3]])
+		if drawn := strings.Count(output[last:loc[0]], "\n"); drawn != n {
+			t.Errorf("Cursor moved up %d lines after drawing %d", n, drawn)
+		}
+		last = loc[1]
+		ups++
+	}
+
+	if ups == 0 {
+		t.Fatalf("Expected cursor movement between redraws, got %q", output)
+	}
+
+	// The final redraw leaves the cursor below all three bars
+	final := output[last:]
+	if drawn := strings.Count(final, "\n"); drawn != len(bars) {
+		t.Errorf("Final redraw drew %d lines, expected %d", drawn, len(bars))
+	}
+	for _, desc := range []string{"First", "Second", "Third"} {
+		if !strings.Contains(final, desc) {
+			t.Errorf("Final redraw is missing %q: %q", desc, final)
+		}
+	}
+}
+
+func TestProgressGroupRemoveBar(t *testing.T) {
+	var buf bytes.Buffer
+	pg := NewProgressGroup().WithWriter(&buf)
+
+	total := pg.NewBar("Total", 2)
+	batch := pg.NewBar("Batch", 2)
+	pg.Start()
+
+	buf.Reset()
+	pg.RemoveBar(batch)
+
+	output := buf.String()
+	if !strings.HasPrefix(output, "\033[2A") {
+		t.Errorf("Expected redraw to start at the top of the two-line block, got %q", output)
+	}
+	if strings.Count(output, "\n") != 1 || !strings.HasSuffix(output, "\r\033[J") {
+		t.Errorf("Expected one line and a clear of the freed line, got %q", output)
+	}
+
+	buf.Reset()
+	total.Finish()
+	if strings.Contains(buf.String(), "Batch") {
+		t.Errorf("Removed bar is still drawn: %q", buf.String())
+	}
+}
+
+func TestProgressGroupRemoveBarStopsBar(t *testing.T) {
+	var buf bytes.Buffer
+	pg := NewProgressGroup().WithWriter(&buf)
+
+	total := pg.NewBar("Total", 2)
+	defer total.Finish()
+	pg.Start()
+
+	// A batch bar is started on its own and only removed afterwards
+	batch := pg.NewBar("Batch", 2)
+	batch.Start()
+	pg.RemoveBar(batch)
+
+	activeBars.mu.Lock()
+	_, running := activeBars.bars[batch]
+	activeBars.mu.Unlock()
+	if running {
+		t.Error("Removed bar is still registered as running")
+	}
+
+	if batch.IsActive() {
+		t.Error("Removed bar is still active")
+	}
+}
+
+func TestBytesSpeedTracksStepChange(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := base
+
+	pb := NewBytesProgress("Copy", 1<<40).WithWriter(io.Discard)
+	pb.now = func() time.Time { return clock }
+	pb.Start()
+
+	advance := func(d time.Duration, rate int64) {
+		for step := time.Duration(0); step < d; step += speedSampleInterval {
+			clock = clock.Add(speedSampleInterval)
+			pb.Add(rate / int64(time.Second/speedSampleInterval))
+		}
+	}
+
+	within := func(got, want float64) bool {
+		return math.Abs(got-want) <= want*0.05
+	}
+
+	const slow, fast = 1 << 20, 10 << 20
+
+	advance(5*time.Second, slow)
+	if got, _ := pb.speed(); !within(got, slow) {
+		t.Errorf("speed() after steady run = %.0f, expected ~%d", got, slow)
+	}
+
+	advance(3*time.Second, fast)
+	if got, _ := pb.speed(); !within(got, fast) {
+		t.Errorf("speed() after step change = %.0f, expected ~%d", got, fast)
+	}
+
+	clock = clock.Add(3 * time.Second)
+	if got, _ := pb.speed(); got != 0 {
+		t.Errorf("speed() after stall = %.0f, expected 0", got)
+	}
+}
+
+func TestProgressBarFail(t *testing.T) {
+	tests := []struct {
+		name string
+		pb   *ProgressBar
+	}{
+		{"Bar", NewProgressBar("Indexing", 10)},
+		{"Spinner", NewSpinner("Scanning")},
+		{"Bytes", NewBytesProgress("Copy", 2048)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.pb.WithWriter(&buf)
+			tt.pb.theme = themes["default"]
+
+			tt.pb.Start()
+			tt.pb.Add(1)
+			tt.pb.Fail("disk full")
+
+			frame := tt.pb.RenderString()
+			if !strings.Contains(frame, "✗ disk full") {
+				t.Errorf("RenderString() = %q, expected failure marker and message", frame)
+			}
+			if !strings.Contains(frame, "\033["+themes["default"].Error+"m") {
+				t.Errorf("RenderString() = %q, expected error color", frame)
+			}
+			if !strings.HasSuffix(buf.String(), "\n") {
+				t.Errorf("Expected Fail to end with a newline, got %q", buf.String())
+			}
+
+			// Finishing a failed bar keeps the failure frame
+			buf.Reset()
+			tt.pb.Finish()
+			if buf.Len() != 0 || !tt.pb.IsFailed() {
+				t.Errorf("Finish() after Fail() redrew the bar: %q", buf.String())
+			}
+		})
+	}
+}
+
+func TestProgressBarThrottlesRendering(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := base
+
+	var buf bytes.Buffer
+	pb := NewProgressBar("Throttled", 100).WithWriter(&buf)
+	pb.now = func() time.Time { return clock }
+	pb.Start()
+
+	for i := 0; i < 10; i++ {
+		pb.Update()
+	}
+	if frames := strings.Count(buf.String(), "\r"); frames != 1 {
+		t.Errorf("Expected only the initial frame within the interval, got %d frames", frames)
+	}
+
+	clock = clock.Add(minRenderInterval)
+	pb.Update()
+	if !strings.Contains(buf.String(), "(11/100)") {
+		t.Errorf("Expected a redraw once the interval elapsed, got %q", buf.String())
+	}
+
+	pb.Update()
+	pb.Finish()
+	if !strings.Contains(buf.String(), "(100/100)") {
+		t.Errorf("Expected Finish to always render, got %q", buf.String())
+	}
+}
+
+func benchmarkProgressBarUpdate(b *testing.B, interval time.Duration) {
+	pb := NewProgressBar("Benchmark", int64(b.N)).WithWriter(io.Discard).WithRenderInterval(interval)
+	pb.Start()
+	defer pb.Finish()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pb.Update()
+	}
+}
+
+func BenchmarkProgressBarUpdateThrottled(b *testing.B) {
+	benchmarkProgressBarUpdate(b, minRenderInterval)
+}
+
+func BenchmarkProgressBarUpdateUnthrottled(b *testing.B) {
+	benchmarkProgressBarUpdate(b, 0)
+}
+
+// decodeProgressEvents decodes a stream of JSON progress events
+func decodeProgressEvents(t *testing.T, data []byte) []ProgressEvent {
+	t.Helper()
+
+	var events []ProgressEvent
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for decoder.More() {
+		var event ProgressEvent
+		if err := decoder.Decode(&event); err != nil {
+			t.Fatalf("Invalid progress event: %v\n%s", err, data)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestProgressBarJSONMode(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := base
+
+	var buf bytes.Buffer
+	pb := NewProgressBar("Indexing", 4).WithWriter(&buf).WithMode(ProgressModeJSON)
+	pb.now = func() time.Time { return clock }
+	pb.Start()
+
+	for i := 0; i < 3; i++ {
+		clock = clock.Add(time.Second)
+		pb.Update()
+	}
+	pb.Finish()
+
+	if bytes.Contains(buf.Bytes(), []byte("\033")) || bytes.Contains(buf.Bytes(), []byte("\r")) {
+		t.Errorf("JSON mode wrote terminal control codes: %q", buf.String())
+	}
+
+	events := decodeProgressEvents(t, buf.Bytes())
+	if len(events) != 5 {
+		t.Fatalf("got %d events, expected start, three updates and finish:\n%s", len(events), buf.String())
+	}
+
+	update := events[2]
+	if update.Event != "progress" || update.Processed != 2 || update.Total != 4 || update.Description != "Indexing" {
+		t.Errorf("update event = %+v, expected progress 2/4", update)
+	}
+	if math.Abs(update.Rate-1) > 0.01 {
+		t.Errorf("rate = %v, expected 1 file/s", update.Rate)
+	}
+	if update.ETASeconds == nil || math.Abs(*update.ETASeconds-2) > 0.01 {
+		t.Errorf("eta_seconds = %v, expected 2", update.ETASeconds)
+	}
+
+	if last := events[len(events)-1]; last.Event != "done" || last.Processed != 4 {
+		t.Errorf("last event = %+v, expected done at 4", last)
+	}
+}
+
+func TestProgressGroupJSONMode(t *testing.T) {
+	var buf bytes.Buffer
+	pg := NewProgressGroup().WithWriter(&buf).WithMode(ProgressModeJSON)
+
+	total := pg.NewBar("Total", 2)
+	batch := pg.NewBar("Batch", 2)
+	total.Start()
+	batch.Start()
+	batch.Fail("disk full")
+	pg.RemoveBar(batch)
+	pg.Finish()
+
+	if bytes.Contains(buf.Bytes(), []byte("\033")) {
+		t.Errorf("JSON mode group wrote terminal control codes: %q", buf.String())
+	}
+
+	events := decodeProgressEvents(t, buf.Bytes())
+	var failed bool
+	for _, event := range events {
+		if event.Event == "failed" && event.Description == "Batch" && event.Message == "disk full" {
+			failed = true
+		}
+	}
+	if !failed {
+		t.Errorf("events = %+v, expected the batch failure", events)
+	}
+	if last := events[len(events)-1]; last.Description != "Total" || last.Event != "done" {
+		t.Errorf("last event = %+v, expected Total done", last)
+	}
+}
+
+func TestHumanizeInt(t *testing.T) {
+	tests := []struct {
+		n        int64
+		expected string
+	}{
+		{0, "0"},
+		{7, "7"},
+		{999, "999"},
+		{1000, "1,000"},
+		{12345, "12,345"},
+		{999999, "999,999"},
+		{1450000, "1,450,000"},
+		{-1, "-1"},
+		{-1000, "-1,000"},
+		{-123456789, "-123,456,789"},
+		{math.MaxInt64, "9,223,372,036,854,775,807"},
+		{math.MinInt64, "-9,223,372,036,854,775,808"},
+	}
+
+	for _, tt := range tests {
+		if got := humanizeInt(tt.n); got != tt.expected {
+			t.Errorf("humanizeInt(%d) = %q, expected %q", tt.n, got, tt.expected)
+		}
+	}
+}
+
+func TestBarCountsGrouped(t *testing.T) {
+	pb := NewProgressBarWithStyle("", 1500000, DefaultBarStyle, ProgressTypeBar)
+	pb.theme = themes["none"]
+	pb.UpdateTo(1450000)
+
+	if frame := pb.RenderString(); !strings.Contains(frame, "(1,450,000/1,500,000)") {
+		t.Errorf("frame = %q, expected grouped counts", frame)
+	}
+
+	pb = NewPercentageProgress("", 2000)
+	pb.theme = themes["none"]
+	pb.UpdateTo(1000)
+	if frame := pb.RenderString(); frame != "50.0% (1,000/2,000)" {
+		t.Errorf("percentage frame = %q, expected grouped counts", frame)
+	}
+}
@@ -0,0 +1,290 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// metricPrefix namespaces every exposed metric
+const metricPrefix = "stroidex_"
+
+// metricsCollector is implemented by results that can be exposed as
+// Prometheus metrics
+type metricsCollector interface {
+	collectMetrics(m *metricSet)
+}
+
+// metricLabel is a label of a metric sample
+type metricLabel struct {
+	name, value string
+}
+
+// metricSample is one sample of a metric family
+type metricSample struct {
+	labels []metricLabel
+	value  float64
+}
+
+// metricFamily is a named gauge or counter with its samples
+type metricFamily struct {
+	name    string
+	help    string
+	kind    string
+	samples []metricSample
+}
+
+// metricSet accumulates metric families in the order they are first
+// added, as the exposition format requires a family's samples together
+type metricSet struct {
+	families []*metricFamily
+	byName   map[string]*metricFamily
+}
+
+// newMetricSet creates an empty metric set
+func newMetricSet() *metricSet {
+	return &metricSet{byName: make(map[string]*metricFamily)}
+}
+
+// gauge adds a sample of the gauge name, labelled with name/value pairs.
+// A sample whose labels were already added is ignored, so results that
+// share a part, e.g. the disk, can each collect it.
+func (m *metricSet) gauge(name, help string, value float64, labels ...string) {
+	m.add("gauge", name, help, value, labels)
+}
+
+// counter adds a sample of the counter name like gauge. Counter names
+// end in _total.
+func (m *metricSet) counter(name, help string, value float64, labels ...string) {
+	m.add("counter", name, help, value, labels)
+}
+
+// add adds a sample to the family name of the given kind
+func (m *metricSet) add(kind, name, help string, value float64, labels []string) {
+	name = metricPrefix + name
+	family, ok := m.byName[name]
+	if !ok {
+		family = &metricFamily{name: name, help: help, kind: kind}
+		m.byName[name] = family
+		m.families = append(m.families, family)
+	}
+
+	sample := metricSample{value: value}
+	for i := 0; i+1 < len(labels); i += 2 {
+		sample.labels = append(sample.labels, metricLabel{labels[i], labels[i+1]})
+	}
+
+	for _, existing := range family.samples {
+		if sameLabels(existing.labels, sample.labels) {
+			return
+		}
+	}
+	family.samples = append(family.samples, sample)
+}
+
+// sameLabels reports whether two label lists are equal
+func sameLabels(a, b []metricLabel) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// write writes the set in the Prometheus text exposition format
+func (m *metricSet) write(w io.Writer) error {
+	var b strings.Builder
+	for _, family := range m.families {
+		fmt.Fprintf(&b, "# HELP %s %s\n", family.name, escapeHelp(family.help))
+		fmt.Fprintf(&b, "# TYPE %s %s\n", family.name, family.kind)
+		for _, sample := range family.samples {
+			b.WriteString(family.name)
+			if len(sample.labels) > 0 {
+				b.WriteByte('{')
+				for i, label := range sample.labels {
+					if i > 0 {
+						b.WriteByte(',')
+					}
+					fmt.Fprintf(&b, "%s=\"%s\"", label.name, escapeLabelValue(label.value))
+				}
+				b.WriteByte('}')
+			}
+			fmt.Fprintf(&b, " %s\n", formatMetricValue(sample.value))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// labelEscaper escapes label values as the exposition format requires
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabelValue escapes backslashes, double quotes and newlines
+func escapeLabelValue(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+// helpEscaper escapes HELP text, where quotes are literal
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// escapeHelp escapes backslashes and newlines
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+// formatMetricValue formats a sample value, spelling infinities and NaN
+// the way Prometheus parses them
+func formatMetricValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+// boolMetric is 1 for true and 0 for false
+func boolMetric(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// prometheusRenderer writes results in the Prometheus text exposition
+// format, for scraping. Only results implementing metricsCollector can
+// be rendered.
+type prometheusRenderer struct {
+	w io.Writer
+}
+
+// Render writes the metrics of doc
+func (r *prometheusRenderer) Render(kind string, doc interface{}) error {
+	collector, ok := doc.(metricsCollector)
+	if !ok {
+		return fmt.Errorf("prometheus output is not supported for %s", kind)
+	}
+
+	m := newMetricSet()
+	collector.collectMetrics(m)
+	return m.write(r.w)
+}
+
+// Record is not supported since metrics describe a state, not a list
+func (r *prometheusRenderer) Record(kind string, item interface{}) error {
+	return fmt.Errorf("prometheus output is not supported for %s", kind)
+}
+
+// Flush is a no-op since Render writes the complete exposition
+func (r *prometheusRenderer) Flush() error {
+	return nil
+}
+
+// collectMetrics exposes the complete status report
+func (r *StatusReport) collectMetrics(m *metricSet) {
+	m.gauge("info", "Stroidex version information.", 1, "version", r.Version)
+	r.System.collectMetrics(m)
+	r.Index.collectMetrics(m)
+	r.Health.collectMetrics(m)
+}
+
+// collectMetrics exposes system information
+func (s SystemInfo) collectMetrics(m *metricSet) {
+	m.gauge("system_cpu_cores", "Number of logical CPUs.", float64(s.CPUCores))
+	m.gauge("process_uptime_seconds", "Time since the process started.", s.UptimeSeconds)
+	m.gauge("process_heap_alloc_bytes", "Bytes of allocated heap objects.", float64(s.HeapAlloc))
+	m.gauge("process_heap_inuse_bytes", "Bytes in in-use heap spans.", float64(s.HeapInuse))
+	m.gauge("process_sys_bytes", "Bytes of memory obtained from the OS.", float64(s.SysMemory))
+
+	periods := []string{"1m", "5m", "15m"}
+	for i, load := range s.LoadAverage {
+		if i < len(periods) {
+			m.gauge("system_load_average", "System load average.", load, "period", periods[i])
+		}
+	}
+}
+
+// collectMetrics exposes index information
+func (i IndexInfo) collectMetrics(m *metricSet) {
+	m.gauge("index_total_documents", "Documents known to the index.", float64(i.TotalDocuments))
+	m.gauge("index_indexed_documents", "Documents indexed.", float64(i.IndexedDocuments))
+	m.gauge("index_pending_documents", "Documents waiting to be indexed.", float64(i.PendingDocuments))
+	if !i.LastIndexed.IsZero() {
+		m.gauge("index_last_indexed_timestamp_seconds", "Unix time of the last indexing run.", float64(i.LastIndexed.Unix()))
+	}
+	if i.Disk != nil {
+		i.Disk.collectMetrics(m)
+	}
+}
+
+// collectMetrics exposes the health check. Every known status gets a
+// sample so alerts can match on the value rather than the label.
+func (h HealthStatus) collectMetrics(m *metricSet) {
+	statuses := make([]string, 0, len(healthSeverity))
+	for status := range healthSeverity {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(a, b int) bool {
+		return healthSeverity[statuses[a]] < healthSeverity[statuses[b]]
+	})
+	for _, status := range statuses {
+		m.gauge("health_status", "Overall health; 1 for the current status.", boolMetric(h.Status == status), "status", status)
+	}
+
+	for _, name := range sortedComponents(h.Components) {
+		status := h.Components[name]
+		m.gauge("health_component", "Component health; 1 when healthy or ok.", boolMetric(status == "healthy" || status == "ok"), "name", name)
+	}
+
+	m.gauge("health_issues", "Health issues found.", float64(len(h.Issues)))
+	m.gauge("health_warnings", "Health warnings found.", float64(len(h.Warnings)))
+	m.gauge("health_response_time_seconds", "Time taken by the health check.", h.ResponseTime.Seconds())
+	if h.Disk != nil {
+		h.Disk.collectMetrics(m)
+	}
+}
+
+// collectMetrics exposes disk usage, labelled by path
+func (d *DiskUsage) collectMetrics(m *metricSet) {
+	m.gauge("disk_total_bytes", "Size of the file system holding the workspace.", float64(d.Total), "path", d.Path)
+	m.gauge("disk_used_bytes", "Used space on the file system holding the workspace.", float64(d.Used), "path", d.Path)
+	m.gauge("disk_free_bytes", "Space available to this user on the file system holding the workspace.", float64(d.Free), "path", d.Path)
+}
+
+// collectMetrics exposes corpus statistics, broken down by extension and
+// MIME type
+func (c CorpusStats) collectMetrics(m *metricSet) {
+	m.gauge("corpus_files", "Files in the index.", float64(c.TotalFiles))
+	m.gauge("corpus_bytes", "Total size of the files in the index.", float64(c.TotalBytes))
+	for _, count := range c.Extensions {
+		m.gauge("corpus_extension_files", "Indexed files by extension.", float64(count.Files), "extension", count.Type)
+		m.gauge("corpus_extension_bytes", "Size of indexed files by extension.", float64(count.Bytes), "extension", count.Type)
+	}
+	for _, count := range c.MimeTypes {
+		m.gauge("corpus_mime_type_files", "Indexed files by MIME type.", float64(count.Files), "mime_type", count.Type)
+	}
+}
+
+// collectMetrics exposes the state of a running monitor
+func (s MonitorStatus) collectMetrics(m *metricSet) {
+	m.gauge("monitor_up", "1 while the monitor is running.", boolMetric(s.Running))
+	m.gauge("monitor_uptime_seconds", "Time since the monitor started.", s.UptimeSeconds)
+	m.counter("monitor_events_processed_total", "File events processed since the monitor started.", float64(s.EventsProcessed))
+	m.gauge("monitor_initial_indexed_files", "Files indexed by --initial-index on startup.", float64(s.InitialIndexed))
+	m.gauge("monitor_watched_paths", "Paths being monitored.", float64(len(s.Paths)))
+	for _, path := range s.Paths {
+		m.gauge("monitor_watched_path", "A monitored path.", 1, "path", path)
+	}
+}
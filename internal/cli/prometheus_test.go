@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// metricLine matches a sample line of the text exposition format
+var metricLine = regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*)(\{[a-zA-Z_][a-zA-Z0-9_]*="(?:[^"\\\n]|\\[\\"n])*"(?:,[a-zA-Z_][a-zA-Z0-9_]*="(?:[^"\\\n]|\\[\\"n])*")*\})? (?:[-+]?[0-9.eE+-]+|NaN|[+-]Inf)$`)
+
+// parseMetrics checks every line of output and returns the sample lines
+func parseMetrics(t *testing.T, output string) []string {
+	t.Helper()
+
+	var samples []string
+	typed := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "# HELP "):
+		case strings.HasPrefix(line, "# TYPE "):
+			fields := strings.Fields(line)
+			if len(fields) != 4 || fields[3] != "gauge" {
+				t.Errorf("invalid TYPE line %q", line)
+			}
+			typed[fields[2]] = true
+		default:
+			match := metricLine.FindStringSubmatch(line)
+			if match == nil {
+				t.Errorf("invalid metric line %q", line)
+				continue
+			}
+			if !typed[match[1]] {
+				t.Errorf("metric line %q precedes its TYPE line", line)
+			}
+			samples = append(samples, line)
+		}
+	}
+	return samples
+}
+
+func TestMetricSetLabelQuoting(t *testing.T) {
+	m := newMetricSet()
+	m.gauge("disk_used_bytes", "Used space.", 42, "path", "C:\\data \"docs\"\nnew")
+
+	var buf bytes.Buffer
+	if err := m.write(&buf); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+
+	samples := parseMetrics(t, buf.String())
+	expected := `stroidex_disk_used_bytes{path="C:\\data \"docs\"\nnew"} 42`
+	if len(samples) != 1 || samples[0] != expected {
+		t.Errorf("samples = %q, expected %q", samples, expected)
+	}
+}
+
+func TestMetricSetSkipsDuplicateSamples(t *testing.T) {
+	m := newMetricSet()
+	m.gauge("disk_free_bytes", "Free space.", 1, "path", ".")
+	m.gauge("index_total_documents", "Documents.", 2)
+	m.gauge("disk_free_bytes", "Free space.", 1, "path", ".")
+
+	var buf bytes.Buffer
+	if err := m.write(&buf); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+
+	if count := strings.Count(buf.String(), "# TYPE stroidex_disk_free_bytes"); count != 1 {
+		t.Errorf("disk_free_bytes TYPE lines = %d, expected 1", count)
+	}
+	if samples := parseMetrics(t, buf.String()); len(samples) != 2 {
+		t.Errorf("samples = %q, expected 2", samples)
+	}
+}
+
+func TestFormatMetricValue(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{0, "0"},
+		{1500, "1500"},
+		{0.25, "0.25"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := formatMetricValue(tt.value); got != tt.expected {
+				t.Errorf("formatMetricValue(%v) = %q, expected %q", tt.value, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStatusReportPrometheus(t *testing.T) {
+	sc := &StatusCommand{
+		config:          &CommandConfig{OutputFormat: "prometheus"},
+		workspace:       ".",
+		diskWarnPercent: defaultDiskWarnPercent,
+	}
+
+	output := captureStdout(t, func() {
+		if err := sc.showStatusReport(); err != nil {
+			t.Fatalf("showStatusReport() error = %v", err)
+		}
+	})
+
+	samples := parseMetrics(t, output)
+	for _, prefix := range []string{
+		"stroidex_index_total_documents ",
+		"stroidex_index_pending_documents ",
+		`stroidex_health_component{name="database"} 1`,
+		`stroidex_health_status{status="healthy"} `,
+	} {
+		found := false
+		for _, sample := range samples {
+			if strings.HasPrefix(sample, prefix) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("prometheus output is missing %s\n%s", prefix, output)
+		}
+	}
+}
+
+func TestPrometheusRendererUnsupported(t *testing.T) {
+	renderer := NewRenderer("prometheus", &bytes.Buffer{})
+	if err := renderer.Render("stats", map[string]int{"files": 1}); err == nil {
+		t.Error("Render() of a result without metrics should fail")
+	}
+	if err := renderer.Record("file", DryRunFile{Path: "a.txt"}); err == nil {
+		t.Error("Record() should fail")
+	}
+}
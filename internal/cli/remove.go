@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// RemoveCommand represents the remove command configuration
+type RemoveCommand struct {
+	config   *CommandConfig
+	patterns []string
+	dryRun   bool
+
+	// indexManifestPath overrides the default .stroidex/manifest.json
+	indexManifestPath string
+}
+
+// RemoveResult is the structured output of a removal
+type RemoveResult struct {
+	Removed int      `json:"removed" yaml:"removed"`
+	DryRun  bool     `json:"dry_run" yaml:"dry_run"`
+	Files   []string `json:"files" yaml:"files"`
+}
+
+// RemovedFile is a row of the csv removal list
+type RemovedFile struct {
+	Path string `json:"path" yaml:"path"`
+}
+
+// NewRemoveCommand creates the remove command
+func NewRemoveCommand(config *CommandConfig) *cobra.Command {
+	rc := &RemoveCommand{
+		config: config,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "remove [path...]",
+		Short: "Remove documents from the index",
+		Long: `Remove files from the index without reindexing.
+
+A path removes that file, or every indexed file below it when it is a
+directory. Paths need not exist any more. A path containing *, ? or [ is
+a glob matched against indexed paths relative to the workspace, as are
+--pattern globs; with both paths and patterns, only files below the paths
+that match a pattern are removed.
+
+Examples:
+  stroidex remove docs/old.md              # Remove one file
+  stroidex remove ./archive                # Remove everything below ./archive
+  stroidex remove "drafts/*.md"            # Remove glob matches
+  stroidex remove --pattern "*.tmp"        # Remove by pattern at any depth
+  stroidex remove ./archive --dry-run      # Show what would be removed`,
+		RunE: rc.runRemove,
+	}
+
+	cmd.Flags().StringSliceVarP(&rc.patterns, "pattern", "p", []string{}, "Remove files matching these glob patterns (comma-separated)")
+	cmd.Flags().BoolVar(&rc.dryRun, "dry-run", false, "Show what would be removed without changing the index")
+
+	return cmd
+}
+
+// runRemove executes the remove command
+func (rc *RemoveCommand) runRemove(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 && len(rc.patterns) == 0 {
+		return fmt.Errorf("specify paths or --pattern to remove")
+	}
+	// Reject the format before the index changes, not when rendering
+	if rc.config.OutputFormat == "prometheus" {
+		return fmt.Errorf("prometheus output is not supported for remove")
+	}
+
+	path := rc.indexManifestPath
+	if path == "" {
+		path = rc.config.resolvePath(defaultIndexManifestPath())
+	}
+	index, err := LoadIndexManifest(path)
+	if err != nil {
+		return err
+	}
+
+	base, _ := filepath.Abs(rc.config.resolvePath("."))
+	keys := rc.matchingFiles(index, base, args)
+
+	files := make([]string, len(keys))
+	for i, key := range keys {
+		files[i] = displayPath(base, key)
+	}
+
+	if !rc.dryRun && len(keys) > 0 {
+		index.Remove(keys...)
+		if err := index.Save(); err != nil {
+			return err
+		}
+	}
+
+	result := RemoveResult{Removed: len(keys), DryRun: rc.dryRun, Files: files}
+	return displayRemoveResult(rc.config, result, "No indexed files matched")
+}
+
+// matchingFiles returns the sorted manifest keys selected by the path
+// and glob arguments and the --pattern globs
+func (rc *RemoveCommand) matchingFiles(index *IndexManifest, base string, args []string) []string {
+	var scopes, globs []string
+	for _, arg := range args {
+		if strings.ContainsAny(arg, "*?[") {
+			globs = append(globs, arg)
+		} else {
+			scopes = append(scopes, manifestKey(rc.config.resolvePath(arg)))
+		}
+	}
+
+	keys := make([]string, 0)
+	for key := range index.Files {
+		rel := displayPath(base, key)
+
+		selected := len(args) == 0 ||
+			(len(scopes) > 0 && inScope(key, scopes)) ||
+			(len(globs) > 0 && matchesAnyGlob(globs, rel))
+		if !selected || !matchesAnyGlob(rc.patterns, rel) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// matchesAnyGlob reports whether rel matches any of patterns, or whether
+// there are no patterns
+func matchesAnyGlob(patterns []string, rel string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if matchGlob(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// displayRemoveResult shows the removed files in the output format, or a
+// summary that reads none when nothing was removed
+func displayRemoveResult(config *CommandConfig, result RemoveResult, none string) error {
+	renderer := NewRenderer(config.OutputFormat, config.Stdout())
+
+	// A CSV holds a single table: the removed files
+	if config.OutputFormat == "csv" {
+		for _, file := range result.Files {
+			if err := renderer.Record("file", RemovedFile{Path: file}); err != nil {
+				return err
+			}
+		}
+		return renderer.Flush()
+	}
+
+	if renderer != nil {
+		if err := renderer.Render("remove", result); err != nil {
+			return err
+		}
+		return renderer.Flush()
+	}
+
+	if result.DryRun {
+		for _, file := range result.Files {
+			fmt.Println(file)
+		}
+		PrintInfo(fmt.Sprintf("Would remove %d entries from the index", result.Removed))
+		return nil
+	}
+
+	if result.Removed == 0 {
+		PrintInfo(none)
+		return nil
+	}
+	PrintSuccess(fmt.Sprintf("Removed %d entries from the index", result.Removed))
+	return nil
+}
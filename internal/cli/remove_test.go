@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// indexWorkspace writes files to a temp workspace and indexes it,
+// returning the workspace
+func indexWorkspace(t *testing.T, files []string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "stroidex-remove")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	for _, name := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	if _, err := runWorkspaceCommand(t, dir, "index", "."); err != nil {
+		t.Fatalf("index error = %v", err)
+	}
+	return dir
+}
+
+// runWorkspaceCommand executes the root command in a workspace, returning
+// its stdout
+func runWorkspaceCommand(t *testing.T, dir string, args ...string) (string, error) {
+	t.Helper()
+
+	cli := NewCLI()
+	cli.RootCmd.SetArgs(append([]string{"--workspace", dir, "--no-color"}, args...))
+
+	var err error
+	output := captureStdout(t, func() {
+		err = cli.RootCmd.Execute()
+	})
+	return output, err
+}
+
+// indexedFiles returns the workspace-relative paths in the index manifest
+func indexedFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	index, err := LoadIndexManifest(filepath.Join(dir, defaultIndexManifestPath()))
+	if err != nil {
+		t.Fatalf("LoadIndexManifest() error = %v", err)
+	}
+
+	base, _ := filepath.Abs(dir)
+	files := make([]string, 0, len(index.Files))
+	for key := range index.Files {
+		files = append(files, filepath.ToSlash(displayPath(base, key)))
+	}
+	sort.Strings(files)
+	return files
+}
+
+func TestRemove(t *testing.T) {
+	preserveTheme(t)
+	t.Setenv("NO_COLOR", "1")
+
+	corpus := []string{"archive/a.md", "archive/old/b.md", "docs/c.md", "docs/d.txt", "e.tmp"}
+
+	tests := []struct {
+		name      string
+		args      []string
+		remaining []string
+		message   string
+	}{
+		{"File", []string{"docs/c.md"}, []string{"archive/a.md", "archive/old/b.md", "docs/d.txt", "e.tmp"}, "Removed 1 entries"},
+		{"Directory", []string{"archive"}, []string{"docs/c.md", "docs/d.txt", "e.tmp"}, "Removed 2 entries"},
+		{"Glob argument", []string{"docs/*"}, []string{"archive/a.md", "archive/old/b.md", "e.tmp"}, "Removed 2 entries"},
+		{"Pattern", []string{"--pattern", "*.md"}, []string{"docs/d.txt", "e.tmp"}, "Removed 3 entries"},
+		{"Pattern within path", []string{"docs", "--pattern", "*.txt"}, []string{"archive/a.md", "archive/old/b.md", "docs/c.md", "e.tmp"}, "Removed 1 entries"},
+		{"Dry run", []string{"archive", "--dry-run"}, corpus, "Would remove 2 entries"},
+		{"No match", []string{"missing.md"}, corpus, "No indexed files matched"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := indexWorkspace(t, corpus)
+			if got := indexedFiles(t, dir); !reflect.DeepEqual(got, corpus) {
+				t.Fatalf("indexed files = %v, expected %v", got, corpus)
+			}
+
+			output, err := runWorkspaceCommand(t, dir, append([]string{"remove"}, tt.args...)...)
+			if err != nil {
+				t.Fatalf("remove error = %v", err)
+			}
+			if !strings.Contains(output, tt.message) {
+				t.Errorf("output = %q, expected %q", output, tt.message)
+			}
+
+			if got := indexedFiles(t, dir); !reflect.DeepEqual(got, tt.remaining) {
+				t.Errorf("indexed files after remove = %v, expected %v", got, tt.remaining)
+			}
+		})
+	}
+}
+
+func TestRemoveRequiresTarget(t *testing.T) {
+	preserveTheme(t)
+
+	dir := indexWorkspace(t, []string{"a.md"})
+	if _, err := runWorkspaceCommand(t, dir, "remove"); err == nil {
+		t.Error("remove without paths or --pattern succeeded, expected an error")
+	}
+}
+
+func TestRemoveRejectsPrometheusOutput(t *testing.T) {
+	preserveTheme(t)
+
+	corpus := []string{"a.md", "b.md"}
+	dir := indexWorkspace(t, corpus)
+	if _, err := runWorkspaceCommand(t, dir, "remove", "a.md", "-o", "prometheus"); err == nil {
+		t.Error("remove -o prometheus succeeded, expected an error")
+	}
+	if got := indexedFiles(t, dir); !reflect.DeepEqual(got, corpus) {
+		t.Errorf("indexed files after a rejected remove = %v, expected %v", got, corpus)
+	}
+}
@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"sort"
+	"time"
+)
+
+// defaultRenameWindow is how long a removed file waits for a matching
+// create before it is reported as removed
+const defaultRenameWindow = 100 * time.Millisecond
+
+// heldRemove is a removal waiting for the create of a file it was moved to
+type heldRemove struct {
+	event    FileEvent
+	state    fileState
+	deadline time.Time
+}
+
+// renamer pairs the removal of a file with the creation, within window,
+// of a file with the same size and modification time, which a move
+// preserves, into a single rename event. It tracks the state of the
+// monitored files to know what a removed file looked like.
+type renamer struct {
+	window time.Duration
+	known  snapshot
+	held   []heldRemove
+}
+
+// newRenamer creates a renamer for the files in known
+func newRenamer(known snapshot, window time.Duration) *renamer {
+	return &renamer{window: window, known: known}
+}
+
+// Add takes an event from the watcher and returns the events to deliver
+// now. A removal of a known file is held back for the window; the create
+// it is paired with is returned as a rename instead.
+func (r *renamer) Add(event FileEvent, now time.Time) []FileEvent {
+	switch event.Op {
+	case "create", "write":
+		info, err := os.Stat(event.Path)
+		if err != nil || info.IsDir() {
+			return []FileEvent{event}
+		}
+		state := fileState{ModTime: info.ModTime(), Size: info.Size()}
+		r.known[event.Path] = state
+
+		if event.Op == "create" {
+			for i, held := range r.held {
+				if held.state.sameFile(state) {
+					r.held = append(r.held[:i], r.held[i+1:]...)
+					return []FileEvent{{Path: event.Path, OldPath: held.event.Path, Op: "rename", Time: event.Time}}
+				}
+			}
+		}
+		return []FileEvent{event}
+
+	case "remove", "rename":
+		// fsnotify reports a file moved away as renamed at its old path
+		event.Op = "remove"
+		state, ok := r.known[event.Path]
+		if !ok {
+			return []FileEvent{event}
+		}
+		delete(r.known, event.Path)
+		r.held = append(r.held, heldRemove{event: event, state: state, deadline: now.Add(r.window)})
+		return nil
+	}
+
+	return []FileEvent{event}
+}
+
+// Expire returns the held removals whose window ended by now, unpaired
+func (r *renamer) Expire(now time.Time) []FileEvent {
+	var expired []FileEvent
+	kept := r.held[:0]
+	for _, held := range r.held {
+		if held.deadline.After(now) {
+			kept = append(kept, held)
+		} else {
+			expired = append(expired, held.event)
+		}
+	}
+	r.held = kept
+	return expired
+}
+
+// nextDeadline returns when the first held removal expires, or false
+// when none is held
+func (r *renamer) nextDeadline() (time.Time, bool) {
+	if len(r.held) == 0 {
+		return time.Time{}, false
+	}
+	next := r.held[0].deadline
+	for _, held := range r.held[1:] {
+		if held.deadline.Before(next) {
+			next = held.deadline
+		}
+	}
+	return next, true
+}
+
+// detectRenames turns the removes and creates from in that belong to one
+// move into rename events, with the --rename-window. Removals still held
+// when in closes are delivered before the output closes.
+func (mc *MonitorCommand) detectRenames(ctx context.Context, in <-chan FileEvent) <-chan FileEvent {
+	out := make(chan FileEvent, eventBuffer)
+	r := newRenamer(mc.takeSnapshot(), mc.renameWindow)
+
+	send := func(events []FileEvent) bool {
+		for _, event := range events {
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return false
+			}
+		}
+		return true
+	}
+
+	go func() {
+		defer close(out)
+
+		for {
+			var timer *time.Timer
+			var expire <-chan time.Time
+			if deadline, ok := r.nextDeadline(); ok {
+				timer = time.NewTimer(time.Until(deadline))
+				expire = timer.C
+			}
+
+			open := true
+			select {
+			case <-ctx.Done():
+				open = false
+			case event, ok := <-in:
+				if !ok {
+					send(r.Expire(time.Now().Add(r.window)))
+					open = false
+				} else {
+					open = send(r.Add(event, time.Now()))
+				}
+			case now := <-expire:
+				open = send(r.Expire(now))
+			}
+
+			if timer != nil {
+				timer.Stop()
+			}
+			if !open {
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// pairRenames turns each removed file whose state matches that of a
+// created file into a rename, comparing snapshots prev and next. The
+// others are returned as removes and creates.
+func pairRenames(prev, next snapshot, removed, created []string, now time.Time) []FileEvent {
+	sort.Strings(removed)
+	sort.Strings(created)
+
+	var events []FileEvent
+	paired := make(map[string]bool)
+	for _, oldPath := range removed {
+		renamed := false
+		for _, newPath := range created {
+			if !paired[newPath] && prev[oldPath].sameFile(next[newPath]) {
+				paired[newPath] = true
+				renamed = true
+				events = append(events, FileEvent{Path: newPath, OldPath: oldPath, Op: "rename", Time: now})
+				break
+			}
+		}
+		if !renamed {
+			events = append(events, FileEvent{Path: oldPath, Op: "remove", Time: now})
+		}
+	}
+
+	for _, newPath := range created {
+		if !paired[newPath] {
+			events = append(events, FileEvent{Path: newPath, Op: "create", Time: now})
+		}
+	}
+	return events
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMonitorDetectsRename(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-rename")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	oldPath := filepath.Join(dir, "draft.md")
+	newPath := filepath.Join(dir, "final.md")
+	if err := ioutil.WriteFile(oldPath, []byte("contents"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	mc := &MonitorCommand{
+		config:       &CommandConfig{},
+		paths:        []string{dir},
+		maxDepth:     -1,
+		patterns:     []string{"*"},
+		renameWindow: time.Second,
+	}
+	if err := mc.startWatching(); err != nil {
+		t.Fatalf("startWatching() error = %v", err)
+	}
+	defer mc.watcher.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := mc.detectRenames(ctx, mc.watch(ctx))
+
+	if err := os.Rename(oldPath, newPath); err != nil {
+		t.Fatalf("Failed to rename file: %v", err)
+	}
+
+	got := collectEvents(events, 2*time.Second)
+	if len(got) != 1 {
+		t.Fatalf("rename delivered %v, expected a single event", got)
+	}
+	if got[0].Op != "rename" || got[0].OldPath != oldPath || got[0].Path != newPath {
+		t.Errorf("event = %+v, expected a rename from %s to %s", got[0], oldPath, newPath)
+	}
+}
+
+func TestRenamerFallsBackToRemove(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-rename")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	created := filepath.Join(dir, "other.md")
+	if err := ioutil.WriteFile(created, []byte("different size"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	now := time.Now()
+	r := newRenamer(snapshot{"gone.md": {ModTime: now, Size: 3}}, time.Second)
+
+	if events := r.Add(FileEvent{Path: "gone.md", Op: "rename"}, now); len(events) != 0 {
+		t.Errorf("Add(remove) = %v, expected the removal to be held", events)
+	}
+	if events := r.Add(FileEvent{Path: created, Op: "create"}, now); len(events) != 1 || events[0].Op != "create" {
+		t.Errorf("Add(create) = %v, expected an unpaired create", events)
+	}
+	if events := r.Expire(now); len(events) != 0 {
+		t.Errorf("Expire() before the window = %v, expected nothing", events)
+	}
+
+	expected := []FileEvent{{Path: "gone.md", Op: "remove"}}
+	if events := r.Expire(now.Add(time.Second)); !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expire() after the window = %v, expected %v", events, expected)
+	}
+}
+
+func TestDiffSnapshotsRename(t *testing.T) {
+	then := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := then.Add(time.Minute)
+
+	prev := snapshot{
+		"a.md": {ModTime: then, Size: 10},
+		"b.md": {ModTime: then, Size: 20},
+	}
+	next := snapshot{
+		"moved/a.md": {ModTime: then, Size: 10},
+		"c.md":       {ModTime: now, Size: 20},
+	}
+
+	expected := []FileEvent{
+		{Path: "b.md", Op: "remove", Time: now},
+		{Path: "c.md", Op: "create", Time: now},
+		{Path: "moved/a.md", OldPath: "a.md", Op: "rename", Time: now},
+	}
+	if got := diffSnapshots(prev, next, now); !reflect.DeepEqual(got, expected) {
+		t.Errorf("diffSnapshots() = %v, expected %v", got, expected)
+	}
+}
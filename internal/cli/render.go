@@ -0,0 +1,416 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Renderer writes command results in a structured output format.
+//
+// The formats share one contract:
+//   - json emits a single document. Records are buffered and written as
+//     one array on Flush.
+//   - ndjson streams one compact object per line. Every line, whether it
+//     comes from Render or Record, carries a "type" discriminator naming
+//     the kind of result so consumers can dispatch without lookahead.
+//   - yaml behaves like json: one document, with records buffered into a
+//     single sequence on Flush.
+//   - csv writes a document as key,value rows, nested fields flattened
+//     into dotted keys. Records are written as one table, with a header
+//     taken from the fields of the first record.
+//   - prometheus writes a document in the text exposition format, for
+//     results that expose metrics. Records are not supported.
+type Renderer interface {
+	// Render writes a complete result document of the given kind
+	Render(kind string, doc interface{}) error
+
+	// Record writes one element of a list-like result of the given kind
+	Record(kind string, item interface{}) error
+
+	// Flush completes the output, writing any buffered records
+	Flush() error
+}
+
+// NewRenderer returns the renderer for a structured output format, or nil
+// when the format is rendered by the command itself (e.g. table)
+func NewRenderer(format string, w io.Writer) Renderer {
+	switch format {
+	case "json":
+		return &jsonRenderer{w: w}
+	case "ndjson":
+		return &ndjsonRenderer{w: w}
+	case "yaml":
+		return &yamlRenderer{w: w}
+	case "csv":
+		return newCSVRenderer(w)
+	case "prometheus":
+		return &prometheusRenderer{w: w}
+	default:
+		return nil
+	}
+}
+
+// NewStreamRenderer returns a renderer for an unbounded stream of
+// records, such as monitor events, or nil for formats rendered by the
+// command itself. A stream never completes a document, so json is
+// written as ndjson and yaml as one tagged document per record.
+func NewStreamRenderer(format string, w io.Writer) Renderer {
+	switch format {
+	case "json", "ndjson":
+		return &ndjsonRenderer{w: w}
+	case "yaml":
+		return &yamlStreamRenderer{w: w}
+	case "csv":
+		return newCSVRenderer(w)
+	default:
+		return nil
+	}
+}
+
+// isStructuredFormat reports whether format is rendered by a Renderer, in
+// which case commands must keep human-oriented messages off stdout
+func isStructuredFormat(format string) bool {
+	return NewRenderer(format, nil) != nil
+}
+
+// jsonRenderer writes indented single-document JSON
+type jsonRenderer struct {
+	w       io.Writer
+	records []json.RawMessage
+}
+
+// Render writes doc as an indented JSON document
+func (r *jsonRenderer) Render(kind string, doc interface{}) error {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	_, err = fmt.Fprintln(r.w, string(data))
+	return err
+}
+
+// Record buffers a tagged item until Flush
+func (r *jsonRenderer) Record(kind string, item interface{}) error {
+	data, err := tagRecord(kind, item)
+	if err != nil {
+		return err
+	}
+
+	r.records = append(r.records, data)
+	return nil
+}
+
+// Flush writes buffered records as a single JSON array
+func (r *jsonRenderer) Flush() error {
+	if len(r.records) == 0 {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(r.records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	r.records = nil
+
+	_, err = fmt.Fprintln(r.w, string(data))
+	return err
+}
+
+// ndjsonRenderer writes newline-delimited JSON, one tagged object per line
+type ndjsonRenderer struct {
+	w io.Writer
+}
+
+// Render writes doc as a single tagged line
+func (r *ndjsonRenderer) Render(kind string, doc interface{}) error {
+	return r.Record(kind, doc)
+}
+
+// Record writes item as a single tagged line
+func (r *ndjsonRenderer) Record(kind string, item interface{}) error {
+	data, err := tagRecord(kind, item)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(r.w, string(data))
+	return err
+}
+
+// Flush is a no-op since every line is written immediately
+func (r *ndjsonRenderer) Flush() error {
+	return nil
+}
+
+// yamlRenderer writes single-document YAML
+type yamlRenderer struct {
+	w       io.Writer
+	records []*yaml.Node
+}
+
+// Render writes doc as a YAML document
+func (r *yamlRenderer) Render(kind string, doc interface{}) error {
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+
+	_, err = r.w.Write(data)
+	return err
+}
+
+// Record buffers a tagged item until Flush
+func (r *yamlRenderer) Record(kind string, item interface{}) error {
+	node, err := tagYAMLNode(kind, item)
+	if err != nil {
+		return err
+	}
+
+	r.records = append(r.records, node)
+	return nil
+}
+
+// Flush writes buffered records as a single YAML sequence
+func (r *yamlRenderer) Flush() error {
+	if len(r.records) == 0 {
+		return nil
+	}
+
+	data, err := yaml.Marshal(&yaml.Node{Kind: yaml.SequenceNode, Content: r.records})
+	if err != nil {
+		return fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	r.records = nil
+
+	_, err = r.w.Write(data)
+	return err
+}
+
+// yamlStreamRenderer writes each record as its own tagged YAML document
+type yamlStreamRenderer struct {
+	w io.Writer
+}
+
+// Render writes doc as a single tagged document
+func (r *yamlStreamRenderer) Render(kind string, doc interface{}) error {
+	return r.Record(kind, doc)
+}
+
+// Record writes item as a single tagged document
+func (r *yamlStreamRenderer) Record(kind string, item interface{}) error {
+	node, err := tagYAMLNode(kind, item)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+
+	_, err = fmt.Fprintf(r.w, "---\n%s", data)
+	return err
+}
+
+// Flush is a no-op since every document is written immediately
+func (r *yamlStreamRenderer) Flush() error {
+	return nil
+}
+
+// csvRenderer writes CSV
+type csvRenderer struct {
+	w      *csv.Writer
+	header []string
+}
+
+// newCSVRenderer creates a CSV renderer writing to w
+func newCSVRenderer(w io.Writer) *csvRenderer {
+	return &csvRenderer{w: csv.NewWriter(w)}
+}
+
+// Render writes doc as key,value rows
+func (r *csvRenderer) Render(kind string, doc interface{}) error {
+	fields, err := flattenFields(doc)
+	if err != nil {
+		return err
+	}
+
+	if err := r.w.Write([]string{"key", "value"}); err != nil {
+		return err
+	}
+	for _, field := range fields {
+		if err := r.w.Write([]string{field.key, field.value}); err != nil {
+			return err
+		}
+	}
+	return r.Flush()
+}
+
+// Record writes item as a row, preceded by the header for the first
+// record. Fields missing from the header's record are left empty and
+// fields it lacked are dropped.
+func (r *csvRenderer) Record(kind string, item interface{}) error {
+	fields, err := flattenFields(item)
+	if err != nil {
+		return err
+	}
+
+	if r.header == nil {
+		r.header = make([]string, len(fields))
+		for i, field := range fields {
+			r.header[i] = field.key
+		}
+		if err := r.w.Write(r.header); err != nil {
+			return err
+		}
+	}
+
+	values := make(map[string]string, len(fields))
+	for _, field := range fields {
+		values[field.key] = field.value
+	}
+	row := make([]string, len(r.header))
+	for i, key := range r.header {
+		row[i] = values[key]
+	}
+
+	if err := r.w.Write(row); err != nil {
+		return err
+	}
+	// Records may be streamed, e.g. monitor events
+	return r.Flush()
+}
+
+// Flush writes any buffered rows
+func (r *csvRenderer) Flush() error {
+	r.w.Flush()
+	return r.w.Error()
+}
+
+// csvField is a flattened scalar field
+type csvField struct {
+	key, value string
+}
+
+// flattenFields encodes v as JSON and flattens it into scalar fields in
+// encoding order. Nested objects and arrays become dotted keys, such as
+// "health.components.database" or "load_average.0". A value that is not
+// an object is a single field named "value".
+func flattenFields(v interface{}) ([]csvField, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal CSV: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var fields []csvField
+	if err := flattenValue(dec, "", &fields); err != nil {
+		return nil, fmt.Errorf("failed to marshal CSV: %w", err)
+	}
+	if len(fields) == 1 && fields[0].key == "" {
+		fields[0].key = "value"
+	}
+	return fields, nil
+}
+
+// flattenValue appends the fields of the next JSON value from dec
+func flattenValue(dec *json.Decoder, prefix string, fields *[]csvField) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch token := token.(type) {
+	case json.Delim:
+		for i := 0; dec.More(); i++ {
+			key := strconv.Itoa(i)
+			if token == '{' {
+				name, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				key = name.(string)
+			}
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			if err := flattenValue(dec, key, fields); err != nil {
+				return err
+			}
+		}
+		_, err := dec.Token() // closing delimiter
+		return err
+	case nil:
+		*fields = append(*fields, csvField{key: prefix})
+	default:
+		*fields = append(*fields, csvField{key: prefix, value: fmt.Sprint(token)})
+	}
+	return nil
+}
+
+// tagYAMLNode encodes item as a YAML mapping with a leading "type" key.
+// Values that are not mappings are wrapped as {type: .., value: ..}.
+func tagYAMLNode(kind string, item interface{}) (*yaml.Node, error) {
+	var node yaml.Node
+	if err := node.Encode(item); err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+
+	typeKey := &yaml.Node{Kind: yaml.ScalarNode, Value: "type"}
+	typeValue := &yaml.Node{Kind: yaml.ScalarNode, Value: kind}
+
+	if node.Kind != yaml.MappingNode {
+		value := node
+		node = yaml.Node{
+			Kind: yaml.MappingNode,
+			Content: []*yaml.Node{
+				typeKey, typeValue,
+				{Kind: yaml.ScalarNode, Value: "value"}, &value,
+			},
+		}
+	} else {
+		node.Content = append([]*yaml.Node{typeKey, typeValue}, node.Content...)
+	}
+
+	return &node, nil
+}
+
+// tagRecord marshals v as a compact JSON object with a leading "type"
+// field. Values that are not objects are wrapped as {"type":..,"value":..}.
+func tagRecord(kind string, v interface{}) (json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	typeField, err := json.Marshal(kind)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	if len(data) < 2 || data[0] != '{' {
+		return json.Marshal(map[string]json.RawMessage{
+			"type":  typeField,
+			"value": data,
+		})
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`{"type":`)
+	buf.Write(typeField)
+	if len(data) > 2 {
+		buf.WriteByte(',')
+	}
+	buf.Write(data[1:])
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,235 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestNewRenderer(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantNil bool
+	}{
+		{"json", false},
+		{"ndjson", false},
+		{"yaml", false},
+		{"csv", false},
+		{"prometheus", false},
+		{"table", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			renderer := NewRenderer(tt.format, &bytes.Buffer{})
+			if (renderer == nil) != tt.wantNil {
+				t.Errorf("NewRenderer(%s) = %v, wantNil %v", tt.format, renderer, tt.wantNil)
+			}
+		})
+	}
+}
+
+func TestNDJSONRendererStreamsTaggedLines(t *testing.T) {
+	var buf bytes.Buffer
+	renderer := NewRenderer("ndjson", &buf)
+
+	if err := renderer.Render("status", map[string]string{"status": "healthy"}); err != nil {
+		t.Fatalf("Render() returned error: %v", err)
+	}
+	for _, path := range []string{"a.md", "b.md"} {
+		if err := renderer.Record("file", struct {
+			Path string `json:"path"`
+		}{path}); err != nil {
+			t.Fatalf("Record() returned error: %v", err)
+		}
+	}
+	if err := renderer.Record("count", 2); err != nil {
+		t.Fatalf("Record() returned error: %v", err)
+	}
+	if err := renderer.Record("empty", struct{}{}); err != nil {
+		t.Fatalf("Record() returned error: %v", err)
+	}
+	if err := renderer.Flush(); err != nil {
+		t.Fatalf("Flush() returned error: %v", err)
+	}
+
+	expectedTypes := []string{"status", "file", "file", "count", "empty"}
+	scanner := bufio.NewScanner(&buf)
+	i := 0
+	for scanner.Scan() {
+		var obj map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &obj); err != nil {
+			t.Fatalf("Line %d is not valid JSON: %q", i, scanner.Text())
+		}
+		if i >= len(expectedTypes) {
+			t.Fatalf("Unexpected extra line: %q", scanner.Text())
+		}
+		if obj["type"] != expectedTypes[i] {
+			t.Errorf("Line %d type = %v, expected %s", i, obj["type"], expectedTypes[i])
+		}
+		i++
+	}
+
+	if i != len(expectedTypes) {
+		t.Errorf("Expected %d lines, got %d", len(expectedTypes), i)
+	}
+}
+
+func TestJSONRendererAggregatesRecords(t *testing.T) {
+	var buf bytes.Buffer
+	renderer := NewRenderer("json", &buf)
+
+	for _, path := range []string{"a.md", "b.md", "c.md"} {
+		if err := renderer.Record("file", map[string]string{"path": path}); err != nil {
+			t.Fatalf("Record() returned error: %v", err)
+		}
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("Expected json records to be buffered until Flush, got %q", buf.String())
+	}
+
+	if err := renderer.Flush(); err != nil {
+		t.Fatalf("Flush() returned error: %v", err)
+	}
+
+	var records []map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+		t.Fatalf("Output is not a single JSON document: %v", err)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+	if records[1]["type"] != "file" || records[1]["path"] != "b.md" {
+		t.Errorf("Unexpected record: %v", records[1])
+	}
+}
+
+func TestJSONRendererDocument(t *testing.T) {
+	var buf bytes.Buffer
+	renderer := NewRenderer("json", &buf)
+
+	if err := renderer.Render("status", map[string]int{"total": 3}); err != nil {
+		t.Fatalf("Render() returned error: %v", err)
+	}
+
+	if strings.Contains(buf.String(), `"type"`) {
+		t.Errorf("json documents should not carry a type discriminator: %s", buf.String())
+	}
+
+	var doc map[string]int
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if doc["total"] != 3 {
+		t.Errorf("Expected total 3, got %d", doc["total"])
+	}
+}
+
+func TestYAMLRendererAggregatesRecords(t *testing.T) {
+	var buf bytes.Buffer
+	renderer := NewRenderer("yaml", &buf)
+
+	if err := renderer.Record("file", map[string]string{"path": "a.md"}); err != nil {
+		t.Fatalf("Record() returned error: %v", err)
+	}
+	if err := renderer.Record("count", 2); err != nil {
+		t.Fatalf("Record() returned error: %v", err)
+	}
+	if err := renderer.Flush(); err != nil {
+		t.Fatalf("Flush() returned error: %v", err)
+	}
+
+	var records []map[string]interface{}
+	if err := yaml.Unmarshal(buf.Bytes(), &records); err != nil {
+		t.Fatalf("Output is not a single YAML document: %v\n%s", err, buf.String())
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if records[0]["type"] != "file" || records[0]["path"] != "a.md" {
+		t.Errorf("Unexpected record: %v", records[0])
+	}
+	if records[1]["type"] != "count" || records[1]["value"] != 2 {
+		t.Errorf("Unexpected record: %v", records[1])
+	}
+}
+
+func TestCSVRendererDocument(t *testing.T) {
+	var buf bytes.Buffer
+	renderer := NewRenderer("csv", &buf)
+
+	doc := struct {
+		Name   string            `json:"name"`
+		Nested map[string]int    `json:"nested"`
+		Loads  []float64         `json:"loads"`
+		Labels map[string]string `json:"labels"`
+		Empty  *string           `json:"empty"`
+		OK     bool              `json:"ok"`
+	}{
+		Name:   "a, \"quoted\" name",
+		Nested: map[string]int{"b": 2, "a": 1},
+		Loads:  []float64{0.5, 1.25},
+		OK:     true,
+	}
+
+	if err := renderer.Render("status", doc); err != nil {
+		t.Fatalf("Render() returned error: %v", err)
+	}
+	if err := renderer.Flush(); err != nil {
+		t.Fatalf("Flush() returned error: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v", err)
+	}
+
+	expected := [][]string{
+		{"key", "value"},
+		{"name", `a, "quoted" name`},
+		{"nested.a", "1"},
+		{"nested.b", "2"},
+		{"loads.0", "0.5"},
+		{"loads.1", "1.25"},
+		{"labels", ""},
+		{"empty", ""},
+		{"ok", "true"},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("csv rows = %q, expected %q", rows, expected)
+	}
+}
+
+func TestCSVRendererRecords(t *testing.T) {
+	var buf bytes.Buffer
+	renderer := NewRenderer("csv", &buf)
+
+	records := []FileTypeCount{{Type: ".md", Files: 2}, {Type: ".txt", Files: 1}}
+	for _, record := range records {
+		if err := renderer.Record("file_type", record); err != nil {
+			t.Fatalf("Record() returned error: %v", err)
+		}
+	}
+	if err := renderer.Flush(); err != nil {
+		t.Fatalf("Flush() returned error: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v", err)
+	}
+
+	expected := [][]string{{"type", "files"}, {".md", "2"}, {".txt", "1"}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("csv rows = %q, expected %q", rows, expected)
+	}
+}
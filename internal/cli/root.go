@@ -16,7 +16,7 @@ func NewRootCommand() *cobra.Command {
 monitoring file system changes, and managing the Stroidex engine.
 
 For more information, visit: https://github.com/stroidex/stroidex`,
-		Version: "1.0.0",
+		Version: versionString(),
 		Run: func(cmd *cobra.Command, args []string) {
 			if len(args) == 0 {
 				_ = cmd.Help()
@@ -61,10 +61,12 @@ func initGlobalFlags(cmd *cobra.Command) {
 	// Configuration options
 	cmd.PersistentFlags().StringP("config", "c", "", "Path to configuration file (default is $HOME/.stroidex.yaml)")
 	cmd.PersistentFlags().StringP("workspace", "w", ".", "Working directory path")
+	cmd.PersistentFlags().String("tmp-dir", "", "Directory for temporary files (default is .stroidex/tmp)")
 
 	// Output options
-	cmd.PersistentFlags().StringP("output", "o", "table", "Output format (table, json, yaml)")
-	cmd.PersistentFlags().BoolP("no-color", "", false, "Disable colored output")
+	cmd.PersistentFlags().StringP("output", "o", "table", "Output format (table, json, ndjson, yaml, csv, prometheus)")
+	cmd.PersistentFlags().String("color", colorAuto, "When to color output (auto, always, never)")
+	cmd.PersistentFlags().BoolP("no-color", "", false, "Disable colored output (same as --color=never)")
 	cmd.PersistentFlags().BoolP("quiet", "q", false, "Quiet mode (no output except errors)")
 	cmd.PersistentFlags().BoolP("verbose", "v", false, "Verbose output")
 
@@ -78,7 +80,16 @@ func initGlobalFlags(cmd *cobra.Command) {
 
 // addPersistentPreRun adds persistent pre-run functionality
 func addPersistentPreRun(cmd *cobra.Command, config *CommandConfig) {
-	cmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// Environment variables fill in flags not given on the command
+		// line, then the config file fills in the rest
+		if err := applyEnv(cmd); err != nil {
+			return err
+		}
+		if err := applyConfigFile(cmd, config); err != nil {
+			return fmt.Errorf("failed to load config file: %w", err)
+		}
+
 		// Handle quiet and verbose flags
 		if quiet, _ := cmd.Flags().GetBool("quiet"); quiet {
 			config.Quiet = true
@@ -100,32 +111,162 @@ func addPersistentPreRun(cmd *cobra.Command, config *CommandConfig) {
 			config.Theme = theme
 		}
 
+		if color, _ := cmd.Flags().GetString("color"); color != "" {
+			config.Color = color
+		}
+
+		if noColor, _ := cmd.Flags().GetBool("no-color"); noColor {
+			config.NoColor = true
+		}
+
 		// Handle config file
 		if configFile, _ := cmd.Flags().GetString("config"); configFile != "" {
 			config.ConfigFile = configFile
 		}
 
+		// Handle workspace
+		if workspace, _ := cmd.Flags().GetString("workspace"); workspace != "" {
+			config.Workspace = workspace
+		}
+
+		// Handle engine type and log level
+		if engineType, _ := cmd.Flags().GetString("engine-type"); engineType != "" {
+			config.EngineType = engineType
+		}
+
+		if logLevel, _ := cmd.Flags().GetString("log-level"); logLevel != "" {
+			config.LogLevel = logLevel
+		}
+
+		// Handle temp directory
+		if tmpDir, _ := cmd.Flags().GetString("tmp-dir"); tmpDir != "" {
+			config.TmpDir = tmpDir
+		}
+
+		// Handle timeout
+		if timeout, _ := cmd.Flags().GetDuration("timeout"); timeout != 0 {
+			config.Timeout = timeout
+		}
+
+		// Handle output file
+		if outputFile, _ := cmd.Flags().GetString("output-file"); outputFile != "" {
+			config.OutputFile = outputFile
+		}
+
+		// Handle profiling
+		if profile, _ := cmd.Flags().GetString("profile"); profile != "" {
+			config.Profile = profile
+		}
+		if profileOut, _ := cmd.Flags().GetString("profile-out"); profileOut != "" {
+			config.ProfileOut = profileOut
+		}
+
 		// Validate configuration
 		if err := validateConfig(config); err != nil {
-			PrintError(fmt.Errorf("configuration validation failed: %w", err))
+			return fmt.Errorf("configuration validation failed: %w", err)
+		}
+		if err := validateWorkspace(config.Workspace); err != nil {
+			return err
+		}
+
+		// The command line is valid; errors from here on are reported
+		// on their own line rather than with the usage text
+		cmd.SilenceUsage = true
+
+		// Apply color theme
+		setupTheme(config)
+		setupOutput(config)
+
+		// Apply log level
+		if err := setupLogging(config); err != nil {
+			return err
+		}
+
+		// Prepare temp file handling
+		setupTempFiles(config)
+
+		if err := config.openOutputFile(); err != nil {
+			return err
 		}
+
+		// Start the --timeout clock
+		config.startTimeout()
+
+		// Profile the command itself, not the setup above
+		return config.startProfile()
+	}
+
+	cmd.PersistentPostRunE = func(cmd *cobra.Command, args []string) error {
+		return config.stopProfile()
 	}
 }
 
 // validateConfig validates the command configuration
 func validateConfig(config *CommandConfig) error {
-	// Validate output format
+	if err := validateOutputFormat(config.OutputFormat); err != nil {
+		return err
+	}
+
+	if err := validateTheme(config.Theme); err != nil {
+		return err
+	}
+
+	if err := validateColor(config); err != nil {
+		return err
+	}
+
+	// Validate engine type; empty selects the default engine
+	validEngineTypes := map[string]bool{
+		"default":      true,
+		"experimental": true,
+		"legacy":       true,
+	}
+
+	if config.EngineType != "" && !validEngineTypes[config.EngineType] {
+		return fmt.Errorf("engine type must be one of default, experimental, legacy, got: %s", config.EngineType)
+	}
+
+	// Validate log level; empty selects info
+	if config.LogLevel != "" {
+		if _, err := parseLogLevel(config.LogLevel); err != nil {
+			return err
+		}
+	}
+
+	if config.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative, got: %v", config.Timeout)
+	}
+
+	if config.OutputFile != "" && config.OutputFormat == "table" {
+		return fmt.Errorf("--output-file needs a structured --output (json, ndjson, yaml, csv, prometheus)")
+	}
+
+	if err := validateProfile(config); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateOutputFormat validates an output format
+func validateOutputFormat(format string) error {
 	validFormats := map[string]bool{
-		"table": true,
-		"json":  true,
-		"yaml":  true,
+		"table":      true,
+		"json":       true,
+		"ndjson":     true,
+		"yaml":       true,
+		"csv":        true,
+		"prometheus": true,
 	}
 
-	if !validFormats[config.OutputFormat] {
-		return fmt.Errorf("invalid output format: %s (valid: table, json, yaml)", config.OutputFormat)
+	if !validFormats[format] {
+		return fmt.Errorf("output format must be one of table, json, ndjson, yaml, csv, prometheus, got: %s", format)
 	}
+	return nil
+}
 
-	// Validate theme
+// validateTheme validates a color theme name
+func validateTheme(theme string) error {
 	validThemes := map[string]bool{
 		"default": true,
 		"dark":    true,
@@ -133,9 +274,8 @@ func validateConfig(config *CommandConfig) error {
 		"none":    true,
 	}
 
-	if !validThemes[config.Theme] {
-		return fmt.Errorf("invalid theme: %s (valid: default, dark, light, none)", config.Theme)
+	if !validThemes[theme] {
+		return fmt.Errorf("theme must be one of default, dark, light, none, got: %s", theme)
 	}
-
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// IndexRunConfig captures the fully resolved settings of an index run
+type IndexRunConfig struct {
+	Version            string   `json:"version"`
+	Paths              []string `json:"paths"`
+	Recursive          bool     `json:"recursive"`
+	MaxDepth           int      `json:"max_depth"`
+	FollowLinks        bool     `json:"follow_symlinks"`
+	DryRun             bool     `json:"dry_run"`
+	Force              bool     `json:"force"`
+	Resume             bool     `json:"resume"`
+	Watch              bool     `json:"watch"`
+	Patterns           []string `json:"patterns"`
+	Exclude            []string `json:"exclude"`
+	ExcludeDirs        []string `json:"exclude_dirs"`
+	IncludeFrom        string   `json:"include_from"`
+	ExcludeFrom        string   `json:"exclude_from"`
+	Hash               string   `json:"hash"`
+	NoIgnore           bool     `json:"no_ignore"`
+	IgnoreCase         bool     `json:"ignore_case"`
+	Stdin              bool     `json:"stdin"`
+	IncludeBinary      bool     `json:"include_binary"`
+	DetectMime         bool     `json:"detect_mime"`
+	MaxSize            int64    `json:"max_size"`
+	MinSize            int64    `json:"min_size"`
+	ModifiedSince      string   `json:"modified_since"`
+	Workers            int      `json:"workers"`
+	BatchSize          int      `json:"batch_size"`
+	CheckpointInterval int      `json:"checkpoint_interval"`
+	AuditLog           string   `json:"audit_log"`
+	IndexType          string   `json:"index_type"`
+	OutputFormat       string   `json:"output_format"`
+	Progress           string   `json:"progress"`
+	SummaryOnly        bool     `json:"summary_only"`
+	Verbose            bool     `json:"verbose"`
+}
+
+// ManifestHeader is the first record of a run manifest and makes the
+// manifest self-describing
+type ManifestHeader struct {
+	Type      string         `json:"type"`
+	CreatedAt time.Time      `json:"created_at"`
+	Config    IndexRunConfig `json:"config"`
+}
+
+// ManifestEntry records the outcome for a single file of an index run
+type ManifestEntry struct {
+	Type    string    `json:"type"`
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	Status  string    `json:"status"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// RunManifest writes a JSONL record of an index run. It is written to a
+// temp file beside path and moved into place on Close so readers never
+// see a partial manifest.
+type RunManifest struct {
+	mu     sync.Mutex
+	path   string
+	file   *os.File
+	writer *bufio.Writer
+}
+
+// NewRunManifest creates a run manifest at path and writes its header
+func NewRunManifest(path string, config IndexRunConfig) (*RunManifest, error) {
+	file, err := tempFiles.CreateTempIn(filepath.Dir(path), "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return nil, err
+	}
+
+	rm := &RunManifest{
+		path:   path,
+		file:   file,
+		writer: bufio.NewWriter(file),
+	}
+
+	header := ManifestHeader{
+		Type:      "header",
+		CreatedAt: time.Now(),
+		Config:    config,
+	}
+
+	if err := rm.writeRecord(header); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return rm, nil
+}
+
+// Record appends a file entry to the manifest
+func (rm *RunManifest) Record(filePath string, procErr error) error {
+	entry := ManifestEntry{
+		Type:   "file",
+		Path:   filePath,
+		Status: "processed",
+	}
+
+	if info, err := os.Stat(filePath); err == nil {
+		entry.Size = info.Size()
+		entry.ModTime = info.ModTime()
+	}
+
+	if procErr != nil {
+		entry.Status = "error"
+		entry.Error = procErr.Error()
+	}
+
+	return rm.writeRecord(entry)
+}
+
+// Close flushes the manifest and moves it to its final path
+func (rm *RunManifest) Close() error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if err := rm.writer.Flush(); err != nil {
+		rm.file.Close()
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := rm.file.Chmod(placedFileMode); err != nil {
+		rm.file.Close()
+		return fmt.Errorf("failed to set the manifest mode: %w", err)
+	}
+
+	if err := rm.file.Close(); err != nil {
+		return fmt.Errorf("failed to close manifest: %w", err)
+	}
+
+	if err := os.Rename(rm.file.Name(), rm.path); err != nil {
+		return fmt.Errorf("failed to move manifest to %s: %w", rm.path, err)
+	}
+	tempFiles.Release(rm.file.Name())
+
+	return nil
+}
+
+// writeRecord writes a single JSON line
+func (rm *RunManifest) writeRecord(record interface{}) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest record: %w", err)
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if _, err := rm.writer.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write manifest record: %w", err)
+	}
+
+	return nil
+}
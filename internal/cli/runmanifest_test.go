@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestRunManifestWritesBesideTarget(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-runmanifest")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	oldDir := tempFiles.Dir()
+	tempFiles.SetDir(filepath.Join(dir, "workspace", stateDirName, tempDirName))
+	defer tempFiles.SetDir(oldDir)
+
+	// The target may be on another filesystem than the temp directory
+	path := filepath.Join(dir, "out", "run.jsonl")
+	rm, err := NewRunManifest(path, IndexRunConfig{Paths: []string{"."}})
+	if err != nil {
+		t.Fatalf("NewRunManifest() error = %v", err)
+	}
+
+	if got := filepath.Dir(rm.file.Name()); got != filepath.Dir(path) {
+		t.Errorf("manifest temp file in %s, expected it beside %s", got, path)
+	}
+	if err := rm.Record(filepath.Join(dir, "a.md"), nil); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := rm.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	entries, err := ioutil.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", filepath.Dir(path), err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "run.jsonl" {
+		t.Errorf("Expected only run.jsonl beside the manifest, found %d file(s)", len(entries))
+	} else if runtime.GOOS != "windows" && entries[0].Mode().Perm() != placedFileMode {
+		t.Errorf("manifest mode = %v, expected %v", entries[0].Mode().Perm(), os.FileMode(placedFileMode))
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open manifest: %v", err)
+	}
+	defer file.Close()
+
+	var records int
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("Invalid manifest line %q: %v", scanner.Text(), err)
+		}
+		records++
+	}
+	if records != 2 {
+		t.Errorf("manifest has %d record(s), expected a header and one entry", records)
+	}
+}
+
+func TestIndexRunConfigRecordsFlags(t *testing.T) {
+	preserveTheme(t)
+
+	dir, err := ioutil.TempDir("", "stroidex-runconfig")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"include.txt", "exclude.txt"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("*.md\n"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	output, err := runWorkspaceCommand(t, dir, "index", ".", "--print-config",
+		"--audit-log", "audit.jsonl", "--progress", "bytes", "--summary-only", "--watch",
+		"--include-from", "include.txt", "--exclude-from", "exclude.txt")
+	if err != nil {
+		t.Fatalf("index --print-config error = %v", err)
+	}
+
+	var resolved IndexRunConfig
+	if err := json.Unmarshal([]byte(output), &resolved); err != nil {
+		t.Fatalf("Invalid --print-config output: %v\n%s", err, output)
+	}
+
+	expected := IndexRunConfig{
+		AuditLog:    "audit.jsonl",
+		Progress:    "bytes",
+		SummaryOnly: true,
+		Watch:       true,
+		IncludeFrom: "include.txt",
+		ExcludeFrom: "exclude.txt",
+	}
+	got := IndexRunConfig{
+		AuditLog:    resolved.AuditLog,
+		Progress:    resolved.Progress,
+		SummaryOnly: resolved.SummaryOnly,
+		Watch:       resolved.Watch,
+		IncludeFrom: resolved.IncludeFrom,
+		ExcludeFrom: resolved.ExcludeFrom,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("run config = %+v, expected %+v", got, expected)
+	}
+}
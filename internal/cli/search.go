@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// pathMatchWeight is the score of a query term found in a file's path,
+	// relative to one occurrence in its content
+	pathMatchWeight = 3
+
+	// snippetLen is the maximum length of a result snippet, in runes
+	snippetLen = 80
+)
+
+// SearchCommand represents the search command configuration
+type SearchCommand struct {
+	config *CommandConfig
+	query  string
+	terms  []string
+	limit  int
+	paths  []string
+
+	// indexManifestPath overrides the default .stroidex/manifest.json
+	indexManifestPath string
+}
+
+// SearchResult is a file matching a search query
+type SearchResult struct {
+	Path    string `json:"path" yaml:"path"`
+	Score   int    `json:"score" yaml:"score"`
+	Line    int    `json:"line,omitempty" yaml:"line,omitempty"`
+	Snippet string `json:"snippet,omitempty" yaml:"snippet,omitempty"`
+}
+
+// SearchResults is the structured output of a search
+type SearchResults struct {
+	Query   string         `json:"query" yaml:"query"`
+	Total   int            `json:"total" yaml:"total"`
+	Results []SearchResult `json:"results" yaml:"results"`
+}
+
+// NewSearchCommand creates the search command
+func NewSearchCommand(config *CommandConfig) *cobra.Command {
+	sc := &SearchCommand{
+		config: config,
+		limit:  10, // default number of results
+	}
+
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search indexed documents",
+		Long: `Search the files recorded by 'stroidex index' for a query.
+
+A file matches when its content or path contains every term of the query,
+ignoring case. Results are ranked by how often the terms occur, with
+matches in the path counting extra, and show the first matching line.
+
+Examples:
+  stroidex search "release notes"          # Files containing both terms
+  stroidex search config --limit 5         # Top 5 results
+  stroidex search todo --path ./src        # Only files below ./src
+  stroidex search todo -o json             # Output as JSON`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: sc.runSearch,
+	}
+
+	cmd.Flags().IntVarP(&sc.limit, "limit", "n", 10, "Maximum number of results (0 = unlimited)")
+	cmd.Flags().StringSliceVar(&sc.paths, "path", []string{}, "Only search files below these paths (comma-separated)")
+
+	return cmd
+}
+
+// runSearch executes the search command
+func (sc *SearchCommand) runSearch(cmd *cobra.Command, args []string) error {
+	sc.query = strings.Join(args, " ")
+	sc.terms = strings.Fields(strings.ToLower(sc.query))
+	if len(sc.terms) == 0 {
+		return fmt.Errorf("search query is empty")
+	}
+	if sc.limit < 0 {
+		return fmt.Errorf("--limit must not be negative")
+	}
+
+	path := sc.indexManifestPath
+	if path == "" {
+		path = sc.config.resolvePath(defaultIndexManifestPath())
+	}
+	index, err := LoadIndexManifest(path)
+	if err != nil {
+		return err
+	}
+	if index.Len() == 0 {
+		return fmt.Errorf("the index is empty; run 'stroidex index' first")
+	}
+
+	results := sc.search(index)
+	total := len(results)
+	if sc.limit > 0 && len(results) > sc.limit {
+		results = results[:sc.limit]
+	}
+
+	return sc.displayResults(results, total)
+}
+
+// search returns the indexed files matching the query, best first
+func (sc *SearchCommand) search(index *IndexManifest) []SearchResult {
+	base, _ := filepath.Abs(sc.config.resolvePath("."))
+	scopes := make([]string, len(sc.paths))
+	for i, p := range sc.paths {
+		scopes[i] = manifestKey(sc.config.resolvePath(p))
+	}
+
+	files := make([]string, 0, len(index.Files))
+	for file := range index.Files {
+		if inScope(file, scopes) {
+			files = append(files, file)
+		}
+	}
+
+	results := make([]SearchResult, 0)
+	for _, file := range files {
+		display := displayPath(base, file)
+		if result, ok := sc.match(file, display); ok {
+			results = append(results, result)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Path < results[j].Path
+	})
+	return results
+}
+
+// match scores a file against the query terms. Every term must occur in
+// the file's content or path. Unreadable and binary files only match by
+// path.
+func (sc *SearchCommand) match(file, display string) (SearchResult, bool) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil || isBinary(content[:min(len(content), sniffLen)]) {
+		content = nil
+	}
+	lower := bytes.ToLower(content)
+	lowerPath := strings.ToLower(display)
+
+	result := SearchResult{Path: display}
+	for _, term := range sc.terms {
+		inContent := bytes.Count(lower, []byte(term))
+		inPath := strings.Count(lowerPath, term)
+		if inContent == 0 && inPath == 0 {
+			return SearchResult{}, false
+		}
+		result.Score += inContent + pathMatchWeight*inPath
+	}
+
+	result.Line, result.Snippet = findSnippet(content, sc.terms)
+	return result, true
+}
+
+// findSnippet returns the number and trimmed text of the first line
+// containing any of terms, or 0 and "" when none does
+func findSnippet(content []byte, terms []string) (int, string) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		lower := strings.ToLower(text)
+		for _, term := range terms {
+			if strings.Contains(lower, term) {
+				return line, truncateSnippet(strings.TrimSpace(text))
+			}
+		}
+	}
+	return 0, ""
+}
+
+// truncateSnippet shortens s to snippetLen runes, marking the cut
+func truncateSnippet(s string) string {
+	if utf8.RuneCountInString(s) <= snippetLen {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:snippetLen-1]) + "…"
+}
+
+// inScope reports whether file is below any of scopes, or whether there
+// are no scopes
+func inScope(file string, scopes []string) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+	for _, scope := range scopes {
+		if file == scope || strings.HasPrefix(file, scope+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// displayPath shows file relative to base when it lies below it
+func displayPath(base, file string) string {
+	rel, err := filepath.Rel(base, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return file
+	}
+	return rel
+}
+
+// displayResults shows the results as a table or in the output format
+func (sc *SearchCommand) displayResults(results []SearchResult, total int) error {
+	renderer := NewRenderer(sc.config.OutputFormat, sc.config.Stdout())
+
+	// A CSV holds a single table: the results
+	if sc.config.OutputFormat == "csv" {
+		for _, result := range results {
+			if err := renderer.Record("result", result); err != nil {
+				return err
+			}
+		}
+		return renderer.Flush()
+	}
+
+	if renderer != nil {
+		if err := renderer.Render("search", SearchResults{Query: sc.query, Total: total, Results: results}); err != nil {
+			return err
+		}
+		return renderer.Flush()
+	}
+
+	if len(results) == 0 {
+		PrintInfo(fmt.Sprintf("No results for %q", sc.query))
+		return nil
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Score", "Path", "Line", "Snippet"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoWrapText(false)
+	for _, result := range results {
+		line := ""
+		if result.Line > 0 {
+			line = strconv.Itoa(result.Line)
+		}
+		table.Append([]string{strconv.Itoa(result.Score), result.Path, line, result.Snippet})
+	}
+	table.Render()
+
+	if total > len(results) {
+		PrintInfo(fmt.Sprintf("Showing %d of %d results (use --limit to see more)", len(results), total))
+	}
+	return nil
+}
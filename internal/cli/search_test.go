@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// setupSearchCorpus writes a small corpus to a temp dir and records it in
+// an index manifest saved in the dir
+func setupSearchCorpus(t *testing.T) (string, string) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "stroidex-search")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	files := map[string]string{
+		"docs/release.md":   "# Release notes\n\nThe release fixes the index.\nRelease date: soon\n",
+		"docs/install.md":   "Install with go install.\nSee the release notes.\n",
+		"src/main.go":       "package main\n\n// TODO: release\n",
+		"notes/meeting.txt": "Nothing to see here\n",
+	}
+
+	index := NewIndexManifest(filepath.Join(dir, "manifest.json"))
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		if err := index.Record(path); err != nil {
+			t.Fatalf("Record(%s) error = %v", name, err)
+		}
+	}
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		t.Fatalf("Failed to marshal manifest: %v", err)
+	}
+	if err := ioutil.WriteFile(index.path, data, 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	return dir, index.path
+}
+
+func TestSearchRanking(t *testing.T) {
+	dir, manifestPath := setupSearchCorpus(t)
+	index, err := LoadIndexManifest(manifestPath)
+	if err != nil {
+		t.Fatalf("LoadIndexManifest() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		paths    []string
+		expected []string
+	}{
+		{"Single term", "release", nil, []string{"docs/release.md", "docs/install.md", "src/main.go"}},
+		{"All terms must match", "release notes", nil, []string{"docs/release.md", "docs/install.md"}},
+		{"Path matches count", "notes", nil, []string{"notes/meeting.txt", "docs/install.md", "docs/release.md"}},
+		{"Case insensitive", "TODO", nil, []string{"src/main.go"}},
+		{"Scoped to path", "release", []string{"src"}, []string{"src/main.go"}},
+		{"No match", "kubernetes", nil, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := &SearchCommand{
+				config: &CommandConfig{Workspace: dir},
+				terms:  strings.Fields(strings.ToLower(tt.query)),
+				paths:  tt.paths,
+			}
+
+			paths := []string{}
+			for _, result := range sc.search(index) {
+				paths = append(paths, filepath.ToSlash(result.Path))
+			}
+			if !reflect.DeepEqual(paths, tt.expected) {
+				t.Errorf("search(%q) = %v, expected %v", tt.query, paths, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSearchSnippet(t *testing.T) {
+	content := []byte("first line\n  The Release is out  \nrelease again\n")
+
+	line, snippet := findSnippet(content, []string{"release"})
+	if line != 2 || snippet != "The Release is out" {
+		t.Errorf("findSnippet() = %d, %q, expected 2, \"The Release is out\"", line, snippet)
+	}
+
+	if line, snippet := findSnippet(content, []string{"missing"}); line != 0 || snippet != "" {
+		t.Errorf("findSnippet() without a match = %d, %q, expected 0, \"\"", line, snippet)
+	}
+
+	long := strings.Repeat("a", 200)
+	if got := truncateSnippet(long); len([]rune(got)) != snippetLen {
+		t.Errorf("truncateSnippet() length = %d, expected %d", len([]rune(got)), snippetLen)
+	}
+}
+
+func TestRunSearchJSON(t *testing.T) {
+	dir, manifestPath := setupSearchCorpus(t)
+
+	sc := &SearchCommand{
+		config:            &CommandConfig{OutputFormat: "json", Workspace: dir},
+		limit:             2,
+		indexManifestPath: manifestPath,
+	}
+
+	output := captureStdout(t, func() {
+		if err := sc.runSearch(nil, []string{"release"}); err != nil {
+			t.Fatalf("runSearch() error = %v", err)
+		}
+	})
+
+	var results SearchResults
+	if err := json.Unmarshal([]byte(output), &results); err != nil {
+		t.Fatalf("Invalid search output: %v\n%s", err, output)
+	}
+	if results.Query != "release" || results.Total != 3 || len(results.Results) != 2 {
+		t.Fatalf("results = %+v, expected 2 of 3 results for release", results)
+	}
+
+	top := results.Results[0]
+	if filepath.ToSlash(top.Path) != "docs/release.md" || top.Line != 1 || top.Snippet != "# Release notes" {
+		t.Errorf("top result = %+v, expected docs/release.md line 1", top)
+	}
+}
+
+func TestRunSearchEmptyIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-search")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	sc := &SearchCommand{
+		config:            &CommandConfig{},
+		indexManifestPath: filepath.Join(dir, "manifest.json"),
+	}
+	if err := sc.runSearch(nil, []string{"release"}); err == nil {
+		t.Error("runSearch() on an empty index should fail")
+	}
+}
@@ -0,0 +1,237 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// StatsCommand represents the stats command configuration
+type StatsCommand struct {
+	config *CommandConfig
+	top    int
+
+	// indexManifestPath overrides the default .stroidex/manifest.json
+	indexManifestPath string
+}
+
+// CorpusStats summarizes the files recorded in the index
+type CorpusStats struct {
+	TotalFiles  int               `json:"total_files" yaml:"total_files"`
+	TotalBytes  int64             `json:"total_bytes" yaml:"total_bytes"`
+	AverageSize int64             `json:"average_size" yaml:"average_size"`
+	Extensions  []CorpusTypeCount `json:"extensions" yaml:"extensions"`
+	MimeTypes   []CorpusTypeCount `json:"mime_types" yaml:"mime_types"`
+	Largest     []CorpusFile      `json:"largest" yaml:"largest"`
+}
+
+// CorpusTypeCount is the number and size of files of one type
+type CorpusTypeCount struct {
+	Type  string `json:"type" yaml:"type"`
+	Files int    `json:"files" yaml:"files"`
+	Bytes int64  `json:"bytes" yaml:"bytes"`
+}
+
+// CorpusFile is an indexed file and its size
+type CorpusFile struct {
+	Path string `json:"path" yaml:"path"`
+	Size int64  `json:"size" yaml:"size"`
+}
+
+// CorpusBreakdownRow is a row of the csv breakdown, by extension or by
+// MIME type
+type CorpusBreakdownRow struct {
+	Kind  string `json:"kind" yaml:"kind"`
+	Type  string `json:"type" yaml:"type"`
+	Files int    `json:"files" yaml:"files"`
+	Bytes int64  `json:"bytes" yaml:"bytes"`
+}
+
+// NewStatsCommand creates the stats command
+func NewStatsCommand(config *CommandConfig) *cobra.Command {
+	sc := &StatsCommand{
+		config: config,
+		top:    10, // default number of largest files
+	}
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Summarize the indexed corpus",
+		Long: `Stats reports what the index holds: the number and total size of
+indexed files, a breakdown by extension and by MIME type, the largest
+files and the average file size. Sizes are as of the last index run;
+MIME types are sniffed from the files on disk.
+
+Use 'stroidex status' for system and health information.
+
+Examples:
+  stroidex stats                           # Summarize the corpus
+  stroidex stats --top 20                  # Show the 20 largest files
+  stroidex stats -o json                   # Output as JSON`,
+		Args: cobra.NoArgs,
+		RunE: sc.runStats,
+	}
+
+	cmd.Flags().IntVar(&sc.top, "top", 10, "Number of largest files to show (0 = none)")
+
+	return cmd
+}
+
+// runStats executes the stats command
+func (sc *StatsCommand) runStats(cmd *cobra.Command, args []string) error {
+	if sc.top < 0 {
+		return fmt.Errorf("--top must not be negative")
+	}
+
+	path := sc.indexManifestPath
+	if path == "" {
+		path = sc.config.resolvePath(defaultIndexManifestPath())
+	}
+	index, err := LoadIndexManifest(path)
+	if err != nil {
+		return err
+	}
+
+	base, _ := filepath.Abs(sc.config.resolvePath("."))
+	return sc.displayStats(collectCorpusStats(index, base, sc.top))
+}
+
+// collectCorpusStats aggregates the manifest entries, keeping the top
+// largest files with paths shown relative to base
+func collectCorpusStats(index *IndexManifest, base string, top int) CorpusStats {
+	extensions := make(map[string]*CorpusTypeCount)
+	mimeTypes := make(map[string]*CorpusTypeCount)
+	files := make([]CorpusFile, 0, len(index.Files))
+
+	stats := CorpusStats{}
+	for key, entry := range index.Files {
+		stats.TotalFiles++
+		stats.TotalBytes += entry.Size
+		files = append(files, CorpusFile{Path: displayPath(base, key), Size: entry.Size})
+
+		addTypeCount(extensions, fileType(key), entry.Size)
+
+		mimeType := "unknown"
+		if head, err := readHead(key); err == nil {
+			mimeType = detectMimeType(head)
+		}
+		addTypeCount(mimeTypes, mimeType, entry.Size)
+	}
+
+	if stats.TotalFiles > 0 {
+		stats.AverageSize = stats.TotalBytes / int64(stats.TotalFiles)
+	}
+	stats.Extensions = sortedTypeCounts(extensions)
+	stats.MimeTypes = sortedTypeCounts(mimeTypes)
+
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Size != files[j].Size {
+			return files[i].Size > files[j].Size
+		}
+		return files[i].Path < files[j].Path
+	})
+	if len(files) > top {
+		files = files[:top]
+	}
+	stats.Largest = files
+
+	return stats
+}
+
+// addTypeCount counts a file of size bytes under typ
+func addTypeCount(counts map[string]*CorpusTypeCount, typ string, size int64) {
+	count, ok := counts[typ]
+	if !ok {
+		count = &CorpusTypeCount{Type: typ}
+		counts[typ] = count
+	}
+	count.Files++
+	count.Bytes += size
+}
+
+// sortedTypeCounts orders type counts by number of files, most first,
+// then by type
+func sortedTypeCounts(counts map[string]*CorpusTypeCount) []CorpusTypeCount {
+	sorted := make([]CorpusTypeCount, 0, len(counts))
+	for _, count := range counts {
+		sorted = append(sorted, *count)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Files != sorted[j].Files {
+			return sorted[i].Files > sorted[j].Files
+		}
+		return sorted[i].Type < sorted[j].Type
+	})
+	return sorted
+}
+
+// displayStats shows the corpus statistics as tables or in the output
+// format
+func (sc *StatsCommand) displayStats(stats CorpusStats) error {
+	renderer := NewRenderer(sc.config.OutputFormat, sc.config.Stdout())
+
+	// A CSV holds a single table: the breakdown by extension and MIME type
+	if sc.config.OutputFormat == "csv" {
+		for _, group := range []struct {
+			kind   string
+			counts []CorpusTypeCount
+		}{{"extension", stats.Extensions}, {"mime_type", stats.MimeTypes}} {
+			for _, count := range group.counts {
+				row := CorpusBreakdownRow{Kind: group.kind, Type: count.Type, Files: count.Files, Bytes: count.Bytes}
+				if err := renderer.Record("breakdown", row); err != nil {
+					return err
+				}
+			}
+		}
+		return renderer.Flush()
+	}
+
+	if renderer != nil {
+		if err := renderer.Render("stats", stats); err != nil {
+			return err
+		}
+		return renderer.Flush()
+	}
+
+	fmt.Printf("Total Files:   %s\n", humanizeInt(int64(stats.TotalFiles)))
+	fmt.Printf("Total Size:    %s\n", formatBytes(stats.TotalBytes))
+	fmt.Printf("Average Size:  %s\n", formatBytes(stats.AverageSize))
+	if stats.TotalFiles == 0 {
+		PrintInfo("The index is empty; run 'stroidex index' first")
+		return nil
+	}
+
+	fmt.Printf("\n%s\n", activeTheme.Paint(activeTheme.Info, "By extension:"))
+	renderTypeCounts("Extension", stats.Extensions)
+
+	fmt.Printf("\n%s\n", activeTheme.Paint(activeTheme.Info, "By MIME type:"))
+	renderTypeCounts("MIME Type", stats.MimeTypes)
+
+	if len(stats.Largest) > 0 {
+		fmt.Printf("\n%s\n", activeTheme.Paint(activeTheme.Info, "Largest files:"))
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Path", "Size"})
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		table.SetAutoWrapText(false)
+		for _, file := range stats.Largest {
+			table.Append([]string{file.Path, formatBytes(file.Size)})
+		}
+		table.Render()
+	}
+	return nil
+}
+
+// renderTypeCounts writes a table of type counts to stdout
+func renderTypeCounts(header string, counts []CorpusTypeCount) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{header, "Files", "Size"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	for _, count := range counts {
+		table.Append([]string{count.Type, humanizeInt(int64(count.Files)), formatBytes(count.Bytes)})
+	}
+	table.Render()
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCollectCorpusStats(t *testing.T) {
+	dir, manifestPath := setupSearchCorpus(t)
+	index, err := LoadIndexManifest(manifestPath)
+	if err != nil {
+		t.Fatalf("LoadIndexManifest() error = %v", err)
+	}
+
+	base, _ := filepath.Abs(dir)
+	stats := collectCorpusStats(index, base, 2)
+
+	// The corpus files are 65, 48, 31 and 20 bytes long
+	if stats.TotalFiles != 4 || stats.TotalBytes != 164 || stats.AverageSize != 41 {
+		t.Errorf("files/bytes/average = %d/%d/%d, expected 4/164/41", stats.TotalFiles, stats.TotalBytes, stats.AverageSize)
+	}
+
+	extensions := []CorpusTypeCount{
+		{Type: ".md", Files: 2, Bytes: 113},
+		{Type: ".go", Files: 1, Bytes: 31},
+		{Type: ".txt", Files: 1, Bytes: 20},
+	}
+	if !reflect.DeepEqual(stats.Extensions, extensions) {
+		t.Errorf("extensions = %+v, expected %+v", stats.Extensions, extensions)
+	}
+
+	mimeTypes := []CorpusTypeCount{{Type: "text/plain", Files: 4, Bytes: 164}}
+	if !reflect.DeepEqual(stats.MimeTypes, mimeTypes) {
+		t.Errorf("mime types = %+v, expected %+v", stats.MimeTypes, mimeTypes)
+	}
+
+	largest := []CorpusFile{
+		{Path: filepath.Join("docs", "release.md"), Size: 65},
+		{Path: filepath.Join("docs", "install.md"), Size: 48},
+	}
+	if !reflect.DeepEqual(stats.Largest, largest) {
+		t.Errorf("largest = %+v, expected %+v", stats.Largest, largest)
+	}
+}
+
+func TestStatsCommandJSON(t *testing.T) {
+	preserveTheme(t)
+	dir := indexWorkspace(t, []string{"a.md", "docs/b.md", "docs/big.txt"})
+
+	output, err := runWorkspaceCommand(t, dir, "--output", "json", "stats", "--top", "1")
+	if err != nil {
+		t.Fatalf("stats error = %v", err)
+	}
+
+	var stats CorpusStats
+	if err := json.Unmarshal([]byte(output), &stats); err != nil {
+		t.Fatalf("stats output is not valid JSON: %v\n%s", err, output)
+	}
+	// Each file holds its own name
+	if stats.TotalFiles != 3 || stats.TotalBytes != 25 {
+		t.Errorf("files/bytes = %d/%d, expected 3/25", stats.TotalFiles, stats.TotalBytes)
+	}
+	if len(stats.Largest) != 1 || !strings.HasSuffix(stats.Largest[0].Path, "big.txt") {
+		t.Errorf("largest = %+v, expected only big.txt", stats.Largest)
+	}
+}
+
+func TestStatsRejectsNegativeTop(t *testing.T) {
+	preserveTheme(t)
+	dir := indexWorkspace(t, []string{"a.md"})
+
+	if _, err := runWorkspaceCommand(t, dir, "stats", "--top", "-1"); err == nil {
+		t.Error("stats --top -1 succeeded, expected an error")
+	}
+}
@@ -1,71 +1,172 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
 	"runtime"
+	"sort"
+	"sync"
+	"syscall"
 	"time"
 
-	"github.com/spf13/cobra"
 	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
 )
 
 // StatusCommand represents the status command configuration
 type StatusCommand struct {
-	config         *CommandConfig
-	showVersion    bool
-	showIndex      bool
-	showSystem     bool
-	showHealth     bool
-	refresh        bool
-	watch          bool
-	checkInterval  time.Duration
+	config        *CommandConfig
+	showVersion   bool
+	showIndex     bool
+	showSystem    bool
+	showHealth    bool
+	refresh       time.Duration
+	watch         bool
+	checkInterval time.Duration
+
+	// workspace is the directory whose disk space is reported
+	workspace       string
+	diskWarnPercent float64
+	failOn          string
+
+	// indexManifestPath overrides the default .stroidex/manifest.json
+	indexManifestPath string
+
+	// healthTimeout bounds each component check, defaultHealthTimeout
+	// when zero; checks replaces the component checks when set, e.g. in
+	// tests
+	healthTimeout time.Duration
+	checks        []healthCheck
+}
+
+// defaultHealthTimeout bounds each component check of the health check
+const defaultHealthTimeout = 5 * time.Second
+
+// healthSeverity orders health statuses from best to worst
+var healthSeverity = map[string]int{
+	"healthy":   0,
+	"degraded":  1,
+	"unhealthy": 2,
 }
 
 // SystemInfo represents system information
 type SystemInfo struct {
-	OS              string    `json:"os"`
-	Architecture    string    `json:"architecture"`
-	Hostname        string    `json:"hostname"`
-	Uptime          string    `json:"uptime"`
-	MemoryUsed      string    `json:"memory_used"`
-	MemoryTotal     string    `json:"memory_total"`
-	CPUCores        int       `json:"cpu_cores"`
-	LoadAverage     []float64 `json:"load_average"`
-	Timestamp       time.Time `json:"timestamp"`
+	OS            string    `json:"os" yaml:"os"`
+	Architecture  string    `json:"architecture" yaml:"architecture"`
+	Hostname      string    `json:"hostname" yaml:"hostname"`
+	Uptime        string    `json:"uptime" yaml:"uptime"`
+	UptimeSeconds float64   `json:"uptime_seconds" yaml:"uptime_seconds"`
+	MemoryUsed    string    `json:"memory_used" yaml:"memory_used"`
+	MemoryTotal   string    `json:"memory_total" yaml:"memory_total"`
+	HeapAlloc     uint64    `json:"heap_alloc" yaml:"heap_alloc"`
+	HeapInuse     uint64    `json:"heap_inuse" yaml:"heap_inuse"`
+	SysMemory     uint64    `json:"sys_memory" yaml:"sys_memory"`
+	CPUCores      int       `json:"cpu_cores" yaml:"cpu_cores"`
+	LoadAverage   []float64 `json:"load_average" yaml:"load_average"`
+	Timestamp     time.Time `json:"timestamp" yaml:"timestamp"`
 }
 
 // IndexInfo represents index information
 type IndexInfo struct {
-	TotalDocuments    int       `json:"total_documents"`
-	IndexedDocuments  int       `json:"indexed_documents"`
-	PendingDocuments  int       `json:"pending_documents"`
-	IndexSize         string    `json:"index_size"`
-	LastIndexed       time.Time `json:"last_indexed"`
-	IndexStatus       string    `json:"index_status"`
-	IndexHealth       string    `json:"index_health"`
-	IndexType         string    `json:"index_type"`
-	Timestamp         time.Time `json:"timestamp"`
+	TotalDocuments   int        `json:"total_documents" yaml:"total_documents"`
+	IndexedDocuments int        `json:"indexed_documents" yaml:"indexed_documents"`
+	PendingDocuments int        `json:"pending_documents" yaml:"pending_documents"`
+	IndexSize        string     `json:"index_size" yaml:"index_size"`
+	LastIndexed      time.Time  `json:"last_indexed" yaml:"last_indexed"`
+	IndexStatus      string     `json:"index_status" yaml:"index_status"`
+	IndexHealth      string     `json:"index_health" yaml:"index_health"`
+	IndexType        string     `json:"index_type" yaml:"index_type"`
+	Disk             *DiskUsage `json:"disk,omitempty" yaml:"disk,omitempty"`
+	Timestamp        time.Time  `json:"timestamp" yaml:"timestamp"`
 }
 
 // HealthStatus represents overall health status
 type HealthStatus struct {
-	Status      string            `json:"status"`
-	Components  map[string]string `json:"components"`
-	Issues      []string          `json:"issues"`
-	Warnings    []string          `json:"warnings"`
-	LastCheck   time.Time         `json:"last_check"`
-	ResponseTime time.Duration    `json:"response_time"`
+	Status       string            `json:"status" yaml:"status"`
+	Components   map[string]string `json:"components" yaml:"components"`
+	Issues       []string          `json:"issues" yaml:"issues"`
+	Warnings     []string          `json:"warnings" yaml:"warnings"`
+	Disk         *DiskUsage        `json:"disk,omitempty" yaml:"disk,omitempty"`
+	LastCheck    time.Time         `json:"last_check" yaml:"last_check"`
+	ResponseTime time.Duration     `json:"response_time" yaml:"response_time"`
+}
+
+// healthStatusDoc is the encoded form of a HealthStatus, with the
+// response time as a duration string such as "15ms" and in milliseconds
+// for tooling
+type healthStatusDoc struct {
+	Status         string            `json:"status" yaml:"status"`
+	Components     map[string]string `json:"components" yaml:"components"`
+	Issues         []string          `json:"issues" yaml:"issues"`
+	Warnings       []string          `json:"warnings" yaml:"warnings"`
+	Disk           *DiskUsage        `json:"disk,omitempty" yaml:"disk,omitempty"`
+	LastCheck      time.Time         `json:"last_check" yaml:"last_check"`
+	ResponseTime   string            `json:"response_time" yaml:"response_time"`
+	ResponseTimeMS float64           `json:"response_time_ms" yaml:"response_time_ms"`
+}
+
+// doc returns the encoded form of the health status
+func (h HealthStatus) doc() healthStatusDoc {
+	return healthStatusDoc{
+		Status:         h.Status,
+		Components:     h.Components,
+		Issues:         h.Issues,
+		Warnings:       h.Warnings,
+		Disk:           h.Disk,
+		LastCheck:      h.LastCheck,
+		ResponseTime:   h.ResponseTime.String(),
+		ResponseTimeMS: float64(h.ResponseTime) / float64(time.Millisecond),
+	}
+}
+
+// MarshalJSON encodes the health status with a readable response time
+func (h HealthStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.doc())
+}
+
+// UnmarshalJSON decodes a health status encoded by MarshalJSON
+func (h *HealthStatus) UnmarshalJSON(data []byte) error {
+	var doc healthStatusDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	var responseTime time.Duration
+	if doc.ResponseTime != "" {
+		d, err := time.ParseDuration(doc.ResponseTime)
+		if err != nil {
+			return fmt.Errorf("invalid response_time: %w", err)
+		}
+		responseTime = d
+	}
+
+	*h = HealthStatus{
+		Status:       doc.Status,
+		Components:   doc.Components,
+		Issues:       doc.Issues,
+		Warnings:     doc.Warnings,
+		Disk:         doc.Disk,
+		LastCheck:    doc.LastCheck,
+		ResponseTime: responseTime,
+	}
+	return nil
+}
+
+// MarshalYAML encodes the health status with a readable response time
+func (h HealthStatus) MarshalYAML() (interface{}, error) {
+	return h.doc(), nil
 }
 
 // StatusReport represents a complete status report
 type StatusReport struct {
-	Version     string      `json:"version"`
-	System      SystemInfo  `json:"system"`
-	Index       IndexInfo   `json:"index"`
-	Health      HealthStatus `json:"health"`
-	Timestamp   time.Time   `json:"timestamp"`
+	Version   string       `json:"version" yaml:"version"`
+	System    SystemInfo   `json:"system" yaml:"system"`
+	Index     IndexInfo    `json:"index" yaml:"index"`
+	Health    HealthStatus `json:"health" yaml:"health"`
+	Timestamp time.Time    `json:"timestamp" yaml:"timestamp"`
 }
 
 // NewStatusCommand creates a new status command
@@ -73,6 +174,7 @@ func NewStatusCommand(config *CommandConfig) *cobra.Command {
 	sc := &StatusCommand{
 		config:        config,
 		checkInterval: time.Second * 30, // default check interval
+		workspace:     ".",
 	}
 
 	cmd := &cobra.Command{
@@ -81,12 +183,17 @@ func NewStatusCommand(config *CommandConfig) *cobra.Command {
 		Long: `Status displays comprehensive information about the Stroidex system,
 including system resources, index status, and health information.
 
+--watch redraws the report every --interval (30s by default). --refresh
+is a shorthand for --watch with the given interval.
+
 Examples:
   stroidex status                           # Show basic status
   stroidex status --verbose                 # Show detailed status
   stroidex status --output json            # Output in JSON format
+  stroidex status --output ndjson          # One JSON object per line
   stroidex status --system --index          # Show system and index info
   stroidex status --health                 # Show health check
+  stroidex status --health --fail-on degraded  # Exit 1 unless healthy (for CI)
   stroidex status --watch                  # Watch status in real-time
   stroidex status --refresh 10s            # Auto-refresh every 10 seconds`,
 		RunE: sc.runStatus,
@@ -97,15 +204,30 @@ Examples:
 	cmd.Flags().BoolVar(&sc.showIndex, "index", false, "Show index information only")
 	cmd.Flags().BoolVar(&sc.showSystem, "system", false, "Show system information only")
 	cmd.Flags().BoolVar(&sc.showHealth, "health", false, "Show health check only")
-	cmd.Flags().BoolVar(&sc.refresh, "refresh", false, "Refresh status information")
+	cmd.Flags().DurationVar(&sc.refresh, "refresh", 0, "Watch status, refreshing at this interval (shorthand for --watch --interval)")
 	cmd.Flags().BoolVar(&sc.watch, "watch", false, "Watch status in real-time")
 	cmd.Flags().DurationVar(&sc.checkInterval, "interval", time.Second*30, "Check interval for watch mode")
+	cmd.Flags().Float64Var(&sc.diskWarnPercent, "disk-warn", defaultDiskWarnPercent, "Disk usage percentage above which the health check warns")
+	cmd.Flags().DurationVar(&sc.healthTimeout, "health-timeout", defaultHealthTimeout, "Report a component check as unknown when it takes longer than this")
+	cmd.Flags().StringVar(&sc.failOn, "fail-on", "", "Exit with an error when health is at least this bad (degraded, unhealthy)")
 
 	return cmd
 }
 
 // runStatus executes the status command
 func (sc *StatusCommand) runStatus(cmd *cobra.Command, args []string) error {
+	if sc.failOn != "" {
+		if _, ok := healthSeverity[sc.failOn]; !ok || sc.failOn == "healthy" {
+			return fmt.Errorf("invalid --fail-on %q: must be degraded or unhealthy", sc.failOn)
+		}
+	}
+
+	if sc.healthTimeout < 0 {
+		return fmt.Errorf("--health-timeout must not be negative")
+	}
+
+	sc.workspace = sc.config.resolvePath(".")
+
 	// If specific flags are set, show only that information
 	if sc.showVersion {
 		return sc.showVersionInfo()
@@ -132,10 +254,16 @@ func (sc *StatusCommand) runStatus(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("failed to perform health check: %w", err)
 		}
-		return sc.displayHealthStatus(health)
+		if err := sc.displayHealthStatus(health); err != nil {
+			return err
+		}
+		return sc.checkFailOn(health)
 	}
 
 	// Show complete status report
+	if err := sc.applyRefresh(); err != nil {
+		return err
+	}
 	if sc.watch {
 		return sc.watchStatus()
 	}
@@ -146,7 +274,7 @@ func (sc *StatusCommand) runStatus(cmd *cobra.Command, args []string) error {
 // showStatusReport shows a complete status report
 func (sc *StatusCommand) showStatusReport() error {
 	report := &StatusReport{
-		Version:   "1.0.0",
+		Version:   buildInfo().Version,
 		Timestamp: time.Now(),
 	}
 
@@ -175,20 +303,59 @@ func (sc *StatusCommand) showStatusReport() error {
 	}
 
 	// Display based on output format
-	switch sc.config.OutputFormat {
-	case "json":
-		return sc.displayStatusJSON(report)
-	case "yaml":
-		return sc.displayStatusYAML(report)
-	default:
-		return sc.displayStatusTable(report)
+	if sc.config.OutputFormat == "table" {
+		err = sc.displayStatusTable(report)
+	} else {
+		err = sc.render("status", report)
+	}
+	if err != nil {
+		return err
+	}
+	return sc.checkFailOn(report.Health)
+}
+
+// checkFailOn returns an error when the health status is at least as
+// bad as --fail-on
+func (sc *StatusCommand) checkFailOn(health HealthStatus) error {
+	if sc.failOn == "" {
+		return nil
+	}
+
+	severity, ok := healthSeverity[health.Status]
+	if !ok || severity >= healthSeverity[sc.failOn] {
+		return fmt.Errorf("health status is %s (--fail-on %s)", health.Status, sc.failOn)
+	}
+	return nil
+}
+
+// render writes a status document through the structured renderer for the
+// configured output format, falling back to JSON
+func (sc *StatusCommand) render(kind string, doc interface{}) error {
+	renderer := NewRenderer(sc.config.OutputFormat, sc.config.Stdout())
+	if renderer == nil {
+		renderer = NewRenderer("json", sc.config.Stdout())
+	}
+
+	if err := renderer.Render(kind, doc); err != nil {
+		return err
 	}
+	return renderer.Flush()
+}
+
+// newProgressBar creates a progress bar for collecting status, drawn on
+// stderr when stdout carries a structured document
+func (sc *StatusCommand) newProgressBar(description string, total int64) *ProgressBar {
+	pb := NewProgressBar(description, total)
+	if isStructuredFormat(sc.config.OutputFormat) {
+		pb.WithWriter(os.Stderr)
+	}
+	return pb
 }
 
 // collectSystemInfo collects system information
 func (sc *StatusCommand) collectSystemInfo() (SystemInfo, error) {
 	// Show progress for system info collection
-	pb := NewProgressBar("Collecting system information", 3)
+	pb := sc.newProgressBar("Collecting system information", 3)
 	pb.Start()
 	defer pb.Finish()
 
@@ -196,110 +363,268 @@ func (sc *StatusCommand) collectSystemInfo() (SystemInfo, error) {
 	pb.UpdateTo(1)
 	hostname, _ := os.Hostname()
 
-	// Get memory info (placeholder implementation)
+	// Get memory info, host totals where the platform supports them
 	pb.UpdateTo(2)
-	memoryTotal := "16GB"
-	memoryUsed := "4GB"
+	memoryTotal, memoryUsed := "unknown", "unknown"
+	if total, used, err := hostMemory(); err == nil {
+		memoryTotal = formatBytes(int64(total))
+		memoryUsed = formatBytes(int64(used))
+	}
+	heapAlloc, heapInuse, sysMemory := processMemory()
+	uptime := processUptime()
 
-	// Get load average (placeholder for non-unix systems)
+	// Get load average, zeros where the platform has none
 	pb.UpdateTo(3)
-	var loadAverage []float64
-	if runtime.GOOS == "linux" || runtime.GOOS == "darwin" {
-		loadAverage = []float64{0.5, 0.8, 1.2}
-	} else {
-		loadAverage = []float64{0, 0, 0}
+	loads, err := loadAverage()
+	if err != nil {
+		loads = []float64{0, 0, 0}
 	}
 
 	info := SystemInfo{
-		OS:           fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
-		Architecture: runtime.GOARCH,
-		Hostname:     hostname,
-		Uptime:       "24h 30m", // placeholder
-		MemoryUsed:   memoryUsed,
-		MemoryTotal:  memoryTotal,
-		CPUCores:     runtime.NumCPU(),
-		LoadAverage:  loadAverage,
-		Timestamp:    time.Now(),
+		OS:            fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+		Architecture:  runtime.GOARCH,
+		Hostname:      hostname,
+		Uptime:        formatUptime(uptime),
+		UptimeSeconds: uptime.Seconds(),
+		MemoryUsed:    memoryUsed,
+		MemoryTotal:   memoryTotal,
+		HeapAlloc:     heapAlloc,
+		HeapInuse:     heapInuse,
+		SysMemory:     sysMemory,
+		CPUCores:      runtime.NumCPU(),
+		LoadAverage:   loads,
+		Timestamp:     time.Now(),
 	}
 
 	return info, nil
 }
 
-// collectIndexInfo collects index information
+// collectIndexInfo collects index information from the index manifest.
+// Without a manifest the counts are zero and the status is "no index".
 func (sc *StatusCommand) collectIndexInfo() (IndexInfo, error) {
 	// Show progress for index info collection
-	pb := NewProgressBar("Collecting index information", 3)
+	pb := sc.newProgressBar("Collecting index information", 3)
 	pb.Start()
 	defer pb.Finish()
 
-	// This is a placeholder implementation
-	// In a real implementation, this would connect to the index engine
-	// and get actual statistics
+	info := IndexInfo{
+		IndexStatus: "no index",
+		IndexHealth: "unknown",
+		IndexSize:   formatBytes(0),
+		IndexType:   "full-text",
+		Timestamp:   time.Now(),
+	}
+
+	path := sc.indexManifestPath
+	if path == "" {
+		path = sc.config.resolvePath(defaultIndexManifestPath())
+	}
 
 	pb.UpdateTo(1)
-	info := IndexInfo{
-		TotalDocuments:   1500,
-		IndexedDocuments: 1450,
-		PendingDocuments: 50,
-		IndexSize:        "245MB",
-		LastIndexed:      time.Now().Add(-time.Hour * 2),
-		IndexStatus:      "active",
-		IndexHealth:      "healthy",
-		IndexType:        "full-text",
-		Timestamp:        time.Now(),
+	if manifestInfo, err := os.Stat(path); err == nil {
+		index, err := LoadIndexManifest(path)
+		if err != nil {
+			return IndexInfo{}, err
+		}
+
+		var size int64
+		for key, entry := range index.Files {
+			size += entry.Size
+			if fileInfo, err := os.Stat(key); err == nil && index.Unchanged(key, fileInfo) {
+				info.IndexedDocuments++
+			} else {
+				info.PendingDocuments++
+			}
+		}
+
+		info.TotalDocuments = len(index.Files)
+		info.IndexSize = formatBytes(size)
+		info.LastIndexed = manifestInfo.ModTime()
+		info.IndexStatus = "active"
+		info.IndexHealth = "healthy"
+		if info.PendingDocuments > 0 {
+			info.IndexHealth = "degraded"
+		}
+	} else if !os.IsNotExist(err) {
+		return IndexInfo{}, fmt.Errorf("failed to read index manifest: %w", err)
+	}
+
+	pb.UpdateTo(2)
+	if disk, err := diskUsage(sc.workspace); err == nil {
+		info.Disk = disk
 	}
 
 	pb.UpdateTo(3)
 	return info, nil
 }
 
-// checkHealth performs health checks
+// completionRate formats the share of documents indexed, or n/a for an
+// empty index
+func (i IndexInfo) completionRate() string {
+	if i.TotalDocuments == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.1f%%", float64(i.IndexedDocuments)/float64(i.TotalDocuments)*100)
+}
+
+// formatLastIndexed formats the time of the last indexing run, or never
+func (i IndexInfo) formatLastIndexed() string {
+	if i.LastIndexed.IsZero() {
+		return "never"
+	}
+	return i.LastIndexed.Format(time.RFC3339)
+}
+
+// healthCheck probes one component, recording its status, and any
+// issues, warnings or disk usage, in the health status it is given
+type healthCheck struct {
+	name string
+	run  func(ctx context.Context, health *HealthStatus)
+}
+
+// staticCheck returns a check that reports a fixed status, a placeholder
+// for components without a real probe yet
+func staticCheck(name, status string) healthCheck {
+	return healthCheck{name: name, run: func(ctx context.Context, health *HealthStatus) {
+		health.Components[name] = status
+	}}
+}
+
+// healthChecks returns the component checks, or sc.checks when set
+func (sc *StatusCommand) healthChecks() []healthCheck {
+	if sc.checks != nil {
+		return sc.checks
+	}
+	return []healthCheck{
+		staticCheck("database", "healthy"),
+		staticCheck("index_engine", "healthy"),
+		staticCheck("file_system", "healthy"),
+		staticCheck("memory", "ok"),
+		{name: "disk_space", run: func(ctx context.Context, health *HealthStatus) { sc.checkDiskSpace(health) }},
+	}
+}
+
+// checkHealth runs the component checks concurrently, each bounded by
+// --health-timeout. A check that times out is reported as unknown with a
+// warning; it is left to finish in the background.
 func (sc *StatusCommand) checkHealth() (HealthStatus, error) {
+	checks := sc.healthChecks()
+
 	// Show progress for health check
-	pb := NewProgressBar("Performing health checks", 5)
+	pb := sc.newProgressBar("Performing health checks", int64(len(checks)))
 	pb.Start()
 	defer pb.Finish()
 
+	start := time.Now()
 	health := HealthStatus{
 		Components: make(map[string]string),
-		Issues:      make([]string, 0),
-		Warnings:    make([]string, 0),
-		LastCheck:   time.Now(),
-		ResponseTime: time.Millisecond * 15,
+		Issues:     make([]string, 0),
+		Warnings:   make([]string, 0),
+		LastCheck:  start,
 	}
 
-	// Check various components (placeholder implementation)
-	pb.UpdateTo(1)
-	health.Components["database"] = "healthy"
+	timeout := sc.healthTimeout
+	if timeout <= 0 {
+		timeout = defaultHealthTimeout
+	}
 
-	pb.UpdateTo(2)
-	health.Components["index_engine"] = "healthy"
+	// Results are merged as checks finish; warnings and issues are
+	// sorted afterwards so the report does not depend on timing
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		wg.Add(1)
+		go func(check healthCheck) {
+			defer wg.Done()
+			defer pb.Update()
+
+			result, ok := runHealthCheck(sc.config.Context(), check, timeout)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if !ok {
+				health.Components[check.name] = "unknown"
+				health.Warnings = append(health.Warnings, fmt.Sprintf("Health check %s timed out after %v", check.name, timeout))
+				return
+			}
+			for name, status := range result.Components {
+				health.Components[name] = status
+			}
+			health.Issues = append(health.Issues, result.Issues...)
+			health.Warnings = append(health.Warnings, result.Warnings...)
+			if result.Disk != nil {
+				health.Disk = result.Disk
+			}
+		}(check)
+	}
+	wg.Wait()
 
-	pb.UpdateTo(3)
-	health.Components["file_system"] = "healthy"
+	sort.Strings(health.Issues)
+	sort.Strings(health.Warnings)
+	health.ResponseTime = time.Since(start)
 
-	pb.UpdateTo(4)
-	health.Components["memory"] = "ok"
-	health.Components["disk_space"] = "warning"
+	// Determine overall status
+	health.Status = overallHealth(health)
 
-	pb.UpdateTo(5)
-	// Add warnings
-	health.Warnings = append(health.Warnings, "Disk usage above 80%")
+	return health, nil
+}
 
-	// Determine overall status
-	hasIssues := len(health.Issues) > 0
-	hasWarnings := len(health.Warnings) > 0
+// runHealthCheck runs check with a timeout, reporting false when it did
+// not finish in time
+func runHealthCheck(ctx context.Context, check healthCheck, timeout time.Duration) (HealthStatus, bool) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan HealthStatus, 1)
+	go func() {
+		result := HealthStatus{Components: make(map[string]string)}
+		check.run(ctx, &result)
+		done <- result
+	}()
+
+	select {
+	case result := <-done:
+		return result, true
+	case <-ctx.Done():
+		return HealthStatus{}, false
+	}
+}
 
+// overallHealth derives the overall status from the issues and warnings
+// found by the health checks
+func overallHealth(health HealthStatus) string {
 	switch {
-	case hasIssues:
-		health.Status = "unhealthy"
-	case hasWarnings:
-		health.Status = "degraded"
+	case len(health.Issues) > 0:
+		return "unhealthy"
+	case len(health.Warnings) > 0:
+		return "degraded"
 	default:
-		health.Status = "healthy"
+		return "healthy"
 	}
+}
 
-	return health, nil
+// checkDiskSpace checks the disk holding the workspace, warning when its
+// usage exceeds --disk-warn
+func (sc *StatusCommand) checkDiskSpace(health *HealthStatus) {
+	disk, err := diskUsage(sc.workspace)
+	if err != nil {
+		health.Components["disk_space"] = "unknown"
+		health.Warnings = append(health.Warnings, fmt.Sprintf("Failed to check disk space: %v", err))
+		return
+	}
+
+	health.Disk = disk
+	if disk.Percent > sc.diskWarnPercent {
+		health.Components["disk_space"] = "warning"
+		health.Warnings = append(health.Warnings, fmt.Sprintf("Disk usage at %.1f%%, above %.0f%%", disk.Percent, sc.diskWarnPercent))
+		return
+	}
+	health.Components["disk_space"] = "ok"
+}
+
+// formatDiskUsage formats disk usage as used / total (percent)
+func formatDiskUsage(disk *DiskUsage) string {
+	return fmt.Sprintf("%s / %s (%.1f%%)", formatBytes(int64(disk.Used)), formatBytes(int64(disk.Total)), disk.Percent)
 }
 
 // displayStatusTable displays status in table format
@@ -315,6 +640,9 @@ func (sc *StatusCommand) displayStatusTable(report *StatusReport) error {
 		fmt.Printf("Hostname:        %s\n", report.System.Hostname)
 		fmt.Printf("CPU Cores:        %d\n", report.System.CPUCores)
 		fmt.Printf("Memory:          %s / %s\n", report.System.MemoryUsed, report.System.MemoryTotal)
+		fmt.Printf("Heap:            %s allocated, %s in use\n",
+			formatBytes(int64(report.System.HeapAlloc)), formatBytes(int64(report.System.HeapInuse)))
+		fmt.Printf("Process Memory:  %s\n", formatBytes(int64(report.System.SysMemory)))
 		fmt.Printf("Uptime:          %s\n", report.System.Uptime)
 
 		if len(report.System.LoadAverage) > 0 {
@@ -326,41 +654,44 @@ func (sc *StatusCommand) displayStatusTable(report *StatusReport) error {
 	}
 
 	// Index information
-	if report.Index.TotalDocuments > 0 {
+	if report.Index.IndexStatus != "" {
 		PrintInfo("\n=== Index Information ===")
-		fmt.Printf("Total Documents: %d\n", report.Index.TotalDocuments)
-		fmt.Printf("Indexed:         %d\n", report.Index.IndexedDocuments)
-		fmt.Printf("Pending:         %d\n", report.Index.PendingDocuments)
+		fmt.Printf("Total Documents: %s\n", humanizeInt(int64(report.Index.TotalDocuments)))
+		fmt.Printf("Indexed:         %s\n", humanizeInt(int64(report.Index.IndexedDocuments)))
+		fmt.Printf("Pending:         %s\n", humanizeInt(int64(report.Index.PendingDocuments)))
 		fmt.Printf("Index Size:      %s\n", report.Index.IndexSize)
-		fmt.Printf("Last Indexed:    %s\n", report.Index.LastIndexed.Format(time.RFC3339))
+		fmt.Printf("Last Indexed:    %s\n", report.Index.formatLastIndexed())
 		fmt.Printf("Index Status:    %s\n", report.Index.IndexStatus)
 		fmt.Printf("Index Health:    %s\n", report.Index.IndexHealth)
 		fmt.Printf("Index Type:      %s\n", report.Index.IndexType)
+		if report.Index.Disk != nil {
+			fmt.Printf("Disk:            %s\n", formatDiskUsage(report.Index.Disk))
+		}
 	}
 
 	// Health status
 	if report.Health.Status != "" {
 		PrintInfo("\n=== Health Status ===")
-		fmt.Printf("Overall Status:  %s\n", report.Health.Status)
+		fmt.Printf("Overall Status:  %s\n", activeTheme.PaintStatus(report.Health.Status))
 		fmt.Printf("Response Time:   %v\n", report.Health.ResponseTime)
 		fmt.Printf("Last Check:      %s\n", report.Health.LastCheck.Format(time.RFC3339))
 
 		if len(report.Health.Components) > 0 {
 			PrintInfo("\nComponents:")
-			for component, status := range report.Health.Components {
-				fmt.Printf("  %-15s: %s\n", component, status)
+			for _, component := range sortedComponents(report.Health.Components) {
+				fmt.Printf("  %-15s: %s\n", component, activeTheme.PaintStatus(report.Health.Components[component]))
 			}
 		}
 
 		if len(report.Health.Warnings) > 0 {
-			PrintWarning("Warnings:")
+			fmt.Printf("\n%s\n", activeTheme.Paint(activeTheme.Warning, "Warnings:"))
 			for _, warning := range report.Health.Warnings {
 				fmt.Printf("  - %s\n", warning)
 			}
 		}
 
 		if len(report.Health.Issues) > 0 {
-			PrintError(fmt.Errorf("Issues detected"))
+			fmt.Printf("\n%s\n", activeTheme.Paint(activeTheme.Error, "Issues detected:"))
 			for _, issue := range report.Health.Issues {
 				fmt.Printf("  - %s\n", issue)
 			}
@@ -370,49 +701,29 @@ func (sc *StatusCommand) displayStatusTable(report *StatusReport) error {
 	return nil
 }
 
-// displayStatusJSON displays status in JSON format
-func (sc *StatusCommand) displayStatusJSON(report *StatusReport) error {
-	data, err := json.MarshalIndent(report, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal JSON: %w", err)
+// showVersionInfo shows version information only
+func (sc *StatusCommand) showVersionInfo() error {
+	info := buildInfo()
+	if sc.config.OutputFormat != "table" {
+		return sc.render("version", info)
 	}
 
-	fmt.Println(string(data))
-	return nil
-}
-
-// displayStatusYAML displays status in YAML format (placeholder)
-func (sc *StatusCommand) displayStatusYAML(report *StatusReport) error {
-	// Simple YAML implementation (placeholder)
-	fmt.Println("# Stroidex Status")
-	fmt.Printf("version: %s\n", report.Version)
-	fmt.Printf("timestamp: %s\n", report.Timestamp.Format(time.RFC3339))
-	fmt.Println("system:")
-	fmt.Printf("  os: %s\n", report.System.OS)
-	fmt.Printf("  hostname: %s\n", report.System.Hostname)
-	fmt.Printf("  cpu_cores: %d\n", report.System.CPUCores)
-	fmt.Printf("  memory_used: %s\n", report.System.MemoryUsed)
-	fmt.Printf("  memory_total: %s\n", report.System.MemoryTotal)
-	fmt.Println("index:")
-	fmt.Printf("  total_documents: %d\n", report.Index.TotalDocuments)
-	fmt.Printf("  indexed_documents: %d\n", report.Index.IndexedDocuments)
-	fmt.Printf("  index_status: %s\n", report.Index.IndexStatus)
-	fmt.Println("health:")
-	fmt.Printf("  status: %s\n", report.Health.Status)
+	PrintInfo("Stroidex CLI")
+	fmt.Printf("Version:  %s\n", info.Version)
+	fmt.Printf("Commit:   %s\n", valueOrUnknown(info.Commit))
+	fmt.Printf("Go:       %s\n", info.GoVersion)
+	fmt.Printf("OS/Arch:  %s\n", info.Platform)
+	fmt.Printf("Built:    %s\n", valueOrUnknown(info.BuildDate))
 
 	return nil
 }
 
-// showVersionInfo shows version information only
-func (sc *StatusCommand) showVersionInfo() error {
-	PrintInfo("Stroidex CLI")
-	fmt.Printf("Version: %s\n", "1.0.0")
-	fmt.Printf("Build:    %s\n", "development")
-	fmt.Printf("Go:       %s\n", runtime.Version())
-	fmt.Printf("OS/Arch:  %s/%s\n", runtime.GOOS, runtime.GOARCH)
-	fmt.Printf("Built:    %s\n", "2024-01-01T00:00:00Z")
-
-	return nil
+// valueOrUnknown returns s, or "unknown" when it is empty
+func valueOrUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
 }
 
 // displaySystemInfo displays detailed system information
@@ -430,6 +741,9 @@ func (sc *StatusCommand) displaySystemInfo(info SystemInfo) error {
 			{"CPU Cores", fmt.Sprintf("%d", info.CPUCores)},
 			{"Memory Used", info.MemoryUsed},
 			{"Memory Total", info.MemoryTotal},
+			{"Heap Allocated", formatBytes(int64(info.HeapAlloc))},
+			{"Heap In Use", formatBytes(int64(info.HeapInuse))},
+			{"Process Memory", formatBytes(int64(info.SysMemory))},
 			{"Timestamp", info.Timestamp.Format(time.RFC3339)},
 		}
 
@@ -441,13 +755,11 @@ func (sc *StatusCommand) displaySystemInfo(info SystemInfo) error {
 
 		table.AppendBulk(data)
 		table.Render()
-	} else {
-		// Use JSON format for other output types
-		data, _ := json.MarshalIndent(info, "", "  ")
-		fmt.Println(string(data))
+		return nil
 	}
 
-	return nil
+	// Use structured rendering for other output types
+	return sc.render("system", info)
 }
 
 // displayIndexInfo displays detailed index information
@@ -457,37 +769,39 @@ func (sc *StatusCommand) displayIndexInfo(info IndexInfo) error {
 		table.SetHeader([]string{"Property", "Value"})
 		table.SetAlignment(tablewriter.ALIGN_LEFT)
 
-		completionRate := float64(info.IndexedDocuments) / float64(info.TotalDocuments) * 100
-
 		data := [][]string{
-			{"Total Documents", fmt.Sprintf("%d", info.TotalDocuments)},
-			{"Indexed Documents", fmt.Sprintf("%d", info.IndexedDocuments)},
-			{"Pending Documents", fmt.Sprintf("%d", info.PendingDocuments)},
-			{"Completion Rate", fmt.Sprintf("%.1f%%", completionRate)},
+			{"Total Documents", humanizeInt(int64(info.TotalDocuments))},
+			{"Indexed Documents", humanizeInt(int64(info.IndexedDocuments))},
+			{"Pending Documents", humanizeInt(int64(info.PendingDocuments))},
+			{"Completion Rate", info.completionRate()},
 			{"Index Size", info.IndexSize},
-			{"Last Indexed", info.LastIndexed.Format(time.RFC3339)},
+			{"Last Indexed", info.formatLastIndexed()},
 			{"Index Status", info.IndexStatus},
 			{"Index Health", info.IndexHealth},
 			{"Index Type", info.IndexType},
 			{"Timestamp", info.Timestamp.Format(time.RFC3339)},
 		}
+		if info.Disk != nil {
+			data = append(data, []string{"Disk", formatDiskUsage(info.Disk)})
+		}
 
 		table.AppendBulk(data)
 		table.Render()
-	} else {
-		data, _ := json.MarshalIndent(info, "", "  ")
-		fmt.Println(string(data))
+		return nil
 	}
 
-	return nil
+	return sc.render("index", info)
 }
 
 // displayHealthStatus displays health status information
 func (sc *StatusCommand) displayHealthStatus(health HealthStatus) error {
 	if sc.config.OutputFormat == "table" {
-		fmt.Printf("Overall Status: %s\n", health.Status)
+		fmt.Printf("Overall Status: %s\n", activeTheme.PaintStatus(health.Status))
 		fmt.Printf("Response Time:  %v\n", health.ResponseTime)
 		fmt.Printf("Last Check:     %s\n", health.LastCheck.Format(time.RFC3339))
+		if health.Disk != nil {
+			fmt.Printf("Disk:           %s\n", formatDiskUsage(health.Disk))
+		}
 
 		if len(health.Components) > 0 {
 			PrintInfo("\nComponents:")
@@ -495,61 +809,115 @@ func (sc *StatusCommand) displayHealthStatus(health HealthStatus) error {
 			table.SetHeader([]string{"Component", "Status"})
 			table.SetAlignment(tablewriter.ALIGN_LEFT)
 
-			for component, status := range health.Components {
-				table.Append([]string{component, status})
+			for _, component := range sortedComponents(health.Components) {
+				table.Append([]string{component, activeTheme.PaintStatus(health.Components[component])})
 			}
 
 			table.Render()
 		}
 
 		if len(health.Warnings) > 0 {
-			PrintWarning("\nWarnings:")
+			fmt.Printf("\n%s\n", activeTheme.Paint(activeTheme.Warning, "Warnings:"))
 			for _, warning := range health.Warnings {
 				fmt.Printf("  - %s\n", warning)
 			}
 		}
 
 		if len(health.Issues) > 0 {
-			PrintError(fmt.Errorf("Issues detected"))
+			fmt.Printf("\n%s\n", activeTheme.Paint(activeTheme.Error, "Issues detected:"))
 			for _, issue := range health.Issues {
 				fmt.Printf("  - %s\n", issue)
 			}
 		}
-	} else {
-		data, _ := json.MarshalIndent(health, "", "  ")
-		fmt.Println(string(data))
+
+		return nil
 	}
 
+	return sc.render("health", health)
+}
+
+// sortedComponents returns the component names in alphabetical order
+func sortedComponents(components map[string]string) []string {
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyRefresh turns --refresh into watch mode at its interval
+func (sc *StatusCommand) applyRefresh() error {
+	if sc.refresh < 0 {
+		return fmt.Errorf("--refresh must be positive")
+	}
+	if sc.refresh > 0 {
+		sc.watch = true
+		sc.checkInterval = sc.refresh
+	}
 	return nil
 }
 
-// watchStatus watches status in real-time
+// watchStatus watches status in real-time until interrupted
 func (sc *StatusCommand) watchStatus() error {
+	if sc.checkInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	ctx, cancel := context.WithCancel(sc.config.Context())
+	defer cancel()
+
+	// Stop on SIGINT or SIGTERM, leaving the terminal on a fresh line
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	go func() {
+		select {
+		case <-sigChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
 	PrintInfo(fmt.Sprintf("Watching status (refresh every %v)...", sc.checkInterval))
 	PrintInfo("Press Ctrl+C to stop")
 
+	return sc.watchLoop(ctx)
+}
+
+// watchLoop redraws the status report every --interval, counting down to
+// the next update each second, until ctx is done
+func (sc *StatusCommand) watchLoop(ctx context.Context) error {
 	ticker := time.NewTicker(sc.checkInterval)
 	defer ticker.Stop()
 
-	// Clear screen initially
-	fmt.Print("\033[H\033[2J")
+	countdown := time.NewTicker(time.Second)
+	defer countdown.Stop()
 
+	next := sc.redrawStatus()
 	for {
 		select {
+		case <-ctx.Done():
+			fmt.Println()
+			return nil
 		case <-ticker.C:
-			// Clear screen and update status
-			fmt.Print("\033[H\033[2J")
-			fmt.Printf("Last update: %s\n\n", time.Now().Format(time.RFC3339))
-
-			if err := sc.showStatusReport(); err != nil {
-				PrintWarning(fmt.Sprintf("Error updating status: %v", err))
-			}
-
-			// Add countdown timer
-			for i := int(sc.checkInterval.Seconds()); i > 0; i-- {
-				fmt.Printf("\rNext update in %2ds...", i)
-				time.Sleep(time.Second)
+			next = sc.redrawStatus()
+		case <-countdown.C:
+			if remaining := time.Until(next).Round(time.Second); remaining > 0 {
+				fmt.Printf("\rNext update in %2ds...", int(remaining.Seconds()))
 			}
 		}
 	}
-}
\ No newline at end of file
+}
+
+// redrawStatus clears the screen and shows the status report, returning
+// when the next update is due
+func (sc *StatusCommand) redrawStatus() time.Time {
+	fmt.Print("\033[H\033[2J")
+	fmt.Printf("Last update: %s\n\n", time.Now().Format(time.RFC3339))
+
+	if err := sc.showStatusReport(); err != nil {
+		PrintWarning(fmt.Sprintf("Error updating status: %v", err))
+	}
+	return time.Now().Add(sc.checkInterval)
+}
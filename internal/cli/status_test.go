@@ -0,0 +1,469 @@
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestCollectSystemInfoMemory(t *testing.T) {
+	sc := &StatusCommand{config: &CommandConfig{OutputFormat: "table"}}
+
+	var info SystemInfo
+	captureStdout(t, func() {
+		var err error
+		info, err = sc.collectSystemInfo()
+		if err != nil {
+			t.Fatalf("collectSystemInfo() error = %v", err)
+		}
+	})
+
+	if info.HeapAlloc == 0 || info.HeapInuse == 0 || info.SysMemory == 0 {
+		t.Errorf("collectSystemInfo() heap alloc %d, heap in use %d, sys %d, expected all non-zero",
+			info.HeapAlloc, info.HeapInuse, info.SysMemory)
+	}
+
+	if runtime.GOOS == "linux" || runtime.GOOS == "darwin" {
+		if info.MemoryTotal == "unknown" || info.MemoryUsed == "unknown" {
+			t.Errorf("collectSystemInfo() memory = %s / %s, expected host totals on %s",
+				info.MemoryUsed, info.MemoryTotal, runtime.GOOS)
+		}
+	}
+}
+
+func TestHostMemory(t *testing.T) {
+	total, used, err := hostMemory()
+	if err == errUnsupportedPlatform {
+		t.Skipf("hostMemory() is not supported on %s", runtime.GOOS)
+	}
+	if err != nil {
+		t.Fatalf("hostMemory() error = %v", err)
+	}
+	if total == 0 || used > total {
+		t.Errorf("hostMemory() = %d used of %d, expected a non-zero total at least the used memory", used, total)
+	}
+}
+
+func TestCollectSystemInfoUptime(t *testing.T) {
+	sc := &StatusCommand{config: &CommandConfig{OutputFormat: "table"}}
+
+	var first, second SystemInfo
+	captureStdout(t, func() {
+		first, _ = sc.collectSystemInfo()
+		time.Sleep(20 * time.Millisecond)
+		second, _ = sc.collectSystemInfo()
+	})
+
+	if first.UptimeSeconds <= 0 {
+		t.Errorf("collectSystemInfo() uptime = %v seconds, expected positive", first.UptimeSeconds)
+	}
+	if second.UptimeSeconds <= first.UptimeSeconds {
+		t.Errorf("uptime went from %v to %v seconds, expected it to increase", first.UptimeSeconds, second.UptimeSeconds)
+	}
+	if second.Uptime == "" {
+		t.Error("collectSystemInfo() returned an empty uptime string")
+	}
+}
+
+func TestFormatUptime(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		expected string
+	}{
+		{0, "0s"},
+		{12 * time.Second, "12s"},
+		{5*time.Minute + 10*time.Second, "5m 10s"},
+		{24*time.Hour + 30*time.Minute, "1d 0h"},
+		{23*time.Hour + 30*time.Minute + 10*time.Second, "23h 30m"},
+		{76*time.Hour + 59*time.Minute, "3d 4h"},
+		{1500 * time.Millisecond, "2s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.duration.String(), func(t *testing.T) {
+			if got := formatUptime(tt.duration); got != tt.expected {
+				t.Errorf("formatUptime(%v) = %q, expected %q", tt.duration, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStatusReportYAML(t *testing.T) {
+	sc := &StatusCommand{
+		config:          &CommandConfig{OutputFormat: "yaml"},
+		workspace:       ".",
+		diskWarnPercent: defaultDiskWarnPercent,
+	}
+
+	output := captureStdout(t, func() {
+		if err := sc.showStatusReport(); err != nil {
+			t.Fatalf("showStatusReport() error = %v", err)
+		}
+	})
+
+	var report StatusReport
+	if err := yaml.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("status output is not valid YAML: %v\n%s", err, output)
+	}
+
+	if report.Version == "" || report.Timestamp.IsZero() {
+		t.Errorf("report version %q, timestamp %v, expected both set", report.Version, report.Timestamp)
+	}
+	if report.System.OS != runtime.GOOS+"/"+runtime.GOARCH {
+		t.Errorf("system.os = %q, expected %s/%s", report.System.OS, runtime.GOOS, runtime.GOARCH)
+	}
+	if len(report.System.LoadAverage) != 3 {
+		t.Errorf("system.load_average = %v, expected three values", report.System.LoadAverage)
+	}
+	if report.System.CPUCores != runtime.NumCPU() {
+		t.Errorf("system.cpu_cores = %d, expected %d", report.System.CPUCores, runtime.NumCPU())
+	}
+	if report.Index.IndexStatus == "" {
+		t.Error("index.index_status is missing")
+	}
+	if report.Health.Status == "" || len(report.Health.Components) == 0 {
+		t.Errorf("health = %+v, expected a status and components", report.Health)
+	}
+	if report.Health.Warnings == nil {
+		t.Error("health.warnings is missing")
+	}
+}
+
+func TestHealthStatusEncodesResponseTime(t *testing.T) {
+	health := HealthStatus{Status: "healthy", ResponseTime: 15 * time.Millisecond}
+
+	for name, doc := range map[string]interface{}{
+		"health": health,
+		"report": StatusReport{Health: health},
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(doc)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			for _, want := range []string{`"response_time":"15ms"`, `"response_time_ms":15`} {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("JSON %s does not contain %s", data, want)
+				}
+			}
+
+			out, err := yaml.Marshal(doc)
+			if err != nil {
+				t.Fatalf("yaml.Marshal() error = %v", err)
+			}
+			for _, want := range []string{"response_time: 15ms", "response_time_ms: 15"} {
+				if !strings.Contains(string(out), want) {
+					t.Errorf("YAML %s does not contain %s", out, want)
+				}
+			}
+		})
+	}
+
+	data, _ := json.Marshal(health)
+	var decoded HealthStatus
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if decoded.ResponseTime != health.ResponseTime || decoded.Status != health.Status {
+		t.Errorf("decoded %+v, expected %+v", decoded, health)
+	}
+}
+
+func TestCheckHealthTimesOutSlowCheck(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	sc := &StatusCommand{
+		config:        &CommandConfig{OutputFormat: "json"},
+		healthTimeout: 50 * time.Millisecond,
+		checks: []healthCheck{
+			staticCheck("database", "healthy"),
+			{name: "network", run: func(ctx context.Context, health *HealthStatus) {
+				// A hung probe that ignores its context
+				<-release
+				health.Components["network"] = "healthy"
+			}},
+		},
+	}
+
+	start := time.Now()
+	health, err := sc.checkHealth()
+	if err != nil {
+		t.Fatalf("checkHealth() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("checkHealth() took %v, expected the slow check to be abandoned", elapsed)
+	}
+
+	if health.Components["database"] != "healthy" {
+		t.Errorf("database = %q, expected healthy", health.Components["database"])
+	}
+	if health.Components["network"] != "unknown" {
+		t.Errorf("network = %q, expected unknown", health.Components["network"])
+	}
+	if len(health.Warnings) != 1 || !strings.Contains(health.Warnings[0], "network timed out") {
+		t.Errorf("warnings = %v, expected a timeout warning for network", health.Warnings)
+	}
+	if health.Status != "degraded" {
+		t.Errorf("status = %q, expected degraded", health.Status)
+	}
+}
+
+func TestCheckFailOn(t *testing.T) {
+	healthy := HealthStatus{Components: map[string]string{"database": "healthy"}}
+	degraded := HealthStatus{
+		Components: map[string]string{"disk_space": "warning"},
+		Warnings:   []string{"Disk usage at 91.0%, above 80%"},
+	}
+	unhealthy := HealthStatus{
+		Components: map[string]string{"database": "down"},
+		Issues:     []string{"Database unreachable"},
+	}
+
+	tests := []struct {
+		name    string
+		failOn  string
+		health  HealthStatus
+		wantErr bool
+	}{
+		{"Disabled unhealthy", "", unhealthy, false},
+		{"Degraded threshold healthy", "degraded", healthy, false},
+		{"Degraded threshold degraded", "degraded", degraded, true},
+		{"Degraded threshold unhealthy", "degraded", unhealthy, true},
+		{"Unhealthy threshold healthy", "unhealthy", healthy, false},
+		{"Unhealthy threshold degraded", "unhealthy", degraded, false},
+		{"Unhealthy threshold unhealthy", "unhealthy", unhealthy, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := &StatusCommand{failOn: tt.failOn}
+			health := tt.health
+			health.Status = overallHealth(health)
+
+			err := sc.checkFailOn(health)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkFailOn(%s) error = %v, wantErr %v", health.Status, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunStatusFailOn(t *testing.T) {
+	tests := []struct {
+		name      string
+		failOn    string
+		diskWarn  float64
+		wantError bool
+	}{
+		{"No threshold", "", -1, false},
+		{"Healthy", "degraded", 100, false},
+		{"Degraded", "degraded", -1, true},
+		{"Degraded below threshold", "unhealthy", -1, false},
+		{"Invalid threshold", "healthy", 100, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := &StatusCommand{
+				config:          &CommandConfig{OutputFormat: "json"},
+				showHealth:      true,
+				workspace:       os.TempDir(),
+				diskWarnPercent: tt.diskWarn,
+				failOn:          tt.failOn,
+			}
+
+			var err error
+			captureStdout(t, func() {
+				err = sc.runStatus(nil, nil)
+			})
+			if (err != nil) != tt.wantError {
+				t.Errorf("runStatus() error = %v, wantError %v", err, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestWatchLoopStopsOnCancel(t *testing.T) {
+	sc := &StatusCommand{
+		config:          &CommandConfig{OutputFormat: "json"},
+		checkInterval:   time.Hour,
+		workspace:       os.TempDir(),
+		diskWarnPercent: defaultDiskWarnPercent,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	captureStdout(t, func() {
+		go func() {
+			done <- sc.watchLoop(ctx)
+		}()
+
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("watchLoop() error = %v", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("watchLoop() did not return after the context was cancelled")
+		}
+	})
+}
+
+func TestStatusRefresh(t *testing.T) {
+	sc := &StatusCommand{
+		config:          &CommandConfig{OutputFormat: "json"},
+		checkInterval:   30 * time.Second,
+		refresh:         50 * time.Millisecond,
+		workspace:       os.TempDir(),
+		diskWarnPercent: defaultDiskWarnPercent,
+	}
+
+	if err := sc.applyRefresh(); err != nil {
+		t.Fatalf("applyRefresh() error = %v", err)
+	}
+	if !sc.watch || sc.checkInterval != sc.refresh {
+		t.Fatalf("applyRefresh() watch = %v, interval = %v, expected watch mode every %v",
+			sc.watch, sc.checkInterval, sc.refresh)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 400*time.Millisecond)
+	defer cancel()
+
+	output := captureStdout(t, func() {
+		if err := sc.watchLoop(ctx); err != nil {
+			t.Errorf("watchLoop() error = %v", err)
+		}
+	})
+	if updates := strings.Count(output, "Last update:"); updates < 2 {
+		t.Errorf("watchLoop() redrew %d time(s), expected repeated refreshes", updates)
+	}
+}
+
+func TestStatusRefreshInvalid(t *testing.T) {
+	sc := &StatusCommand{refresh: -time.Second}
+	if err := sc.applyRefresh(); err == nil {
+		t.Error("applyRefresh() expected an error for a negative interval")
+	}
+}
+
+func TestStatusReportCSV(t *testing.T) {
+	sc := &StatusCommand{
+		config:          &CommandConfig{OutputFormat: "csv"},
+		workspace:       ".",
+		diskWarnPercent: defaultDiskWarnPercent,
+	}
+
+	output := captureStdout(t, func() {
+		if err := sc.showStatusReport(); err != nil {
+			t.Fatalf("showStatusReport() error = %v", err)
+		}
+	})
+
+	rows, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+	if err != nil {
+		t.Fatalf("status output is not valid CSV: %v\n%s", err, output)
+	}
+	if len(rows) == 0 || rows[0][0] != "key" || rows[0][1] != "value" {
+		t.Fatalf("csv header = %v, expected key,value", rows)
+	}
+
+	values := make(map[string]string)
+	for _, row := range rows[1:] {
+		values[row[0]] = row[1]
+	}
+	if values["system.os"] != runtime.GOOS+"/"+runtime.GOARCH {
+		t.Errorf("system.os = %q, expected %s/%s", values["system.os"], runtime.GOOS, runtime.GOARCH)
+	}
+	for _, key := range []string{"version", "system.load_average.2", "index.total_documents", "health.status", "health.components.database"} {
+		if _, ok := values[key]; !ok {
+			t.Errorf("csv output is missing %s", key)
+		}
+	}
+}
+
+func TestCollectIndexInfo(t *testing.T) {
+	dir, manifestPath := setupSearchCorpus(t)
+
+	// One indexed file changes and one is deleted, leaving them pending
+	if err := ioutil.WriteFile(filepath.Join(dir, "src", "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatalf("Failed to modify file: %v", err)
+	}
+	if err := os.Remove(filepath.Join(dir, "notes", "meeting.txt")); err != nil {
+		t.Fatalf("Failed to delete file: %v", err)
+	}
+
+	index, err := LoadIndexManifest(manifestPath)
+	if err != nil {
+		t.Fatalf("LoadIndexManifest() error = %v", err)
+	}
+	var size int64
+	for _, entry := range index.Files {
+		size += entry.Size
+	}
+	manifestInfo, err := os.Stat(manifestPath)
+	if err != nil {
+		t.Fatalf("Failed to stat manifest: %v", err)
+	}
+
+	sc := &StatusCommand{
+		config:            &CommandConfig{OutputFormat: "json"},
+		workspace:         dir,
+		indexManifestPath: manifestPath,
+	}
+	info, err := sc.collectIndexInfo()
+	if err != nil {
+		t.Fatalf("collectIndexInfo() error = %v", err)
+	}
+
+	if info.TotalDocuments != 4 || info.IndexedDocuments != 2 || info.PendingDocuments != 2 {
+		t.Errorf("documents total/indexed/pending = %d/%d/%d, expected 4/2/2",
+			info.TotalDocuments, info.IndexedDocuments, info.PendingDocuments)
+	}
+	if info.IndexSize != formatBytes(size) {
+		t.Errorf("index_size = %s, expected %s", info.IndexSize, formatBytes(size))
+	}
+	if !info.LastIndexed.Equal(manifestInfo.ModTime()) {
+		t.Errorf("last_indexed = %v, expected the manifest mtime %v", info.LastIndexed, manifestInfo.ModTime())
+	}
+	if info.IndexStatus != "active" || info.IndexHealth != "degraded" {
+		t.Errorf("status/health = %s/%s, expected active/degraded", info.IndexStatus, info.IndexHealth)
+	}
+}
+
+func TestCollectIndexInfoNoIndex(t *testing.T) {
+	dir, _ := setupSearchCorpus(t)
+
+	sc := &StatusCommand{
+		config:            &CommandConfig{OutputFormat: "json"},
+		workspace:         dir,
+		indexManifestPath: filepath.Join(dir, stateDirName, indexManifestFile),
+	}
+	info, err := sc.collectIndexInfo()
+	if err != nil {
+		t.Fatalf("collectIndexInfo() error = %v", err)
+	}
+
+	if info.TotalDocuments != 0 || info.IndexedDocuments != 0 || info.PendingDocuments != 0 {
+		t.Errorf("documents = %d/%d/%d, expected zeros", info.TotalDocuments, info.IndexedDocuments, info.PendingDocuments)
+	}
+	if info.IndexStatus != "no index" || !info.LastIndexed.IsZero() {
+		t.Errorf("status = %s, last_indexed = %v, expected no index and never", info.IndexStatus, info.LastIndexed)
+	}
+	if info.completionRate() != "n/a" || info.formatLastIndexed() != "never" {
+		t.Errorf("completion rate %s, last indexed %s, expected n/a and never", info.completionRate(), info.formatLastIndexed())
+	}
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// processStart is when the process started, for the reported uptime
+var processStart = time.Now()
+
+// errUnsupportedPlatform is returned by host information helpers that
+// have no implementation for the current platform
+var errUnsupportedPlatform = errors.New("not supported on " + runtime.GOOS)
+
+// processMemory returns the memory held by this process: bytes allocated
+// and still live, heap spans in use, and the total obtained from the OS
+func processMemory() (alloc, heapInuse, sys uint64) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.Alloc, stats.HeapInuse, stats.Sys
+}
+
+// processUptime returns how long the process has been running
+func processUptime() time.Duration {
+	return time.Since(processStart)
+}
+
+// formatUptime formats d compactly with its two most significant units,
+// e.g. "3d 4h", "24h 30m", "5m 10s" or "12s"
+func formatUptime(d time.Duration) string {
+	d = d.Round(time.Second)
+	days := d / (24 * time.Hour)
+	hours := (d % (24 * time.Hour)) / time.Hour
+	minutes := (d % time.Hour) / time.Minute
+	seconds := (d % time.Minute) / time.Second
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm %ds", minutes, seconds)
+	default:
+		return fmt.Sprintf("%ds", seconds)
+	}
+}
@@ -0,0 +1,54 @@
+//go:build darwin
+// +build darwin
+
+package cli
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// hostMemory returns the total and used physical memory of the host
+func hostMemory() (total, used uint64, err error) {
+	total, err = unix.SysctlUint64("hw.memsize")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	freePages, err := unix.SysctlUint32("vm.page_free_count")
+	if err != nil {
+		return 0, 0, err
+	}
+	free := uint64(freePages) * uint64(os.Getpagesize())
+	if free > total {
+		free = total
+	}
+	return total, total - free, nil
+}
+
+// loadAverage returns the 1, 5 and 15 minute load averages
+func loadAverage() ([]float64, error) {
+	// struct loadavg { fixpt_t ldavg[3]; long fscale; }, with fscale
+	// aligned to 8 bytes
+	raw, err := unix.SysctlRaw("vm.loadavg")
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) < 24 {
+		return nil, fmt.Errorf("unexpected vm.loadavg size %d", len(raw))
+	}
+
+	scale := float64(binary.LittleEndian.Uint64(raw[16:24]))
+	if scale == 0 {
+		return nil, fmt.Errorf("invalid vm.loadavg scale")
+	}
+
+	loads := make([]float64, 3)
+	for i := range loads {
+		loads[i] = float64(binary.LittleEndian.Uint32(raw[i*4:])) / scale
+	}
+	return loads, nil
+}
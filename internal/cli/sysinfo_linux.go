@@ -0,0 +1,60 @@
+//go:build linux
+// +build linux
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// procLoadAvg is where the kernel reports the load average
+const procLoadAvg = "/proc/loadavg"
+
+// hostMemory returns the total and used physical memory of the host.
+// Buffers count as free since the kernel reclaims them on demand.
+func hostMemory() (total, used uint64, err error) {
+	var info syscall.Sysinfo_t
+	if err := syscall.Sysinfo(&info); err != nil {
+		return 0, 0, err
+	}
+
+	unit := uint64(info.Unit)
+	if unit == 0 {
+		unit = 1
+	}
+	total = uint64(info.Totalram) * unit
+	free := (uint64(info.Freeram) + uint64(info.Bufferram)) * unit
+	return total, total - free, nil
+}
+
+// loadAverage returns the 1, 5 and 15 minute load averages
+func loadAverage() ([]float64, error) {
+	data, err := ioutil.ReadFile(procLoadAvg)
+	if err != nil {
+		return nil, err
+	}
+	return parseLoadAvg(string(data))
+}
+
+// parseLoadAvg parses the load averages from the contents of
+// /proc/loadavg, e.g. "0.42 0.37 0.31 1/512 12345"
+func parseLoadAvg(s string) ([]float64, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("invalid %s: %q", procLoadAvg, s)
+	}
+
+	loads := make([]float64, 3)
+	for i := range loads {
+		load, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %q", procLoadAvg, s)
+		}
+		loads[i] = load
+	}
+	return loads, nil
+}
@@ -0,0 +1,51 @@
+//go:build linux
+// +build linux
+
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLoadAvg(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []float64
+		wantErr  bool
+	}{
+		{"Sample", "0.42 0.37 0.31 1/512 12345\n", []float64{0.42, 0.37, 0.31}, false},
+		{"Busy host", "12.05 8.50 4.00 9/1024 999", []float64{12.05, 8.5, 4}, false},
+		{"Too few fields", "0.42 0.37", nil, true},
+		{"Not a number", "high 0.37 0.31 1/512 12345", nil, true},
+		{"Empty", "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLoadAvg(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseLoadAvg(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("parseLoadAvg(%q) = %v, expected %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadAverage(t *testing.T) {
+	loads, err := loadAverage()
+	if err != nil {
+		t.Skipf("Cannot read %s: %v", procLoadAvg, err)
+	}
+	if len(loads) != 3 {
+		t.Fatalf("loadAverage() = %v, expected three values", loads)
+	}
+	for _, load := range loads {
+		if load < 0 {
+			t.Errorf("loadAverage() = %v, expected non-negative values", loads)
+		}
+	}
+}
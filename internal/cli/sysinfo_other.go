@@ -0,0 +1,14 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package cli
+
+// hostMemory is not implemented on this platform
+func hostMemory() (total, used uint64, err error) {
+	return 0, 0, errUnsupportedPlatform
+}
+
+// loadAverage is not implemented on this platform
+func loadAverage() ([]float64, error) {
+	return nil, errUnsupportedPlatform
+}
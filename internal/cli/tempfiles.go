@@ -0,0 +1,272 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// stateDirName is the per-workspace directory holding stroidex state
+	stateDirName = ".stroidex"
+
+	// tempDirName is the temp-file directory inside the state directory
+	tempDirName = "tmp"
+
+	// orphanTempAge is the age after which a temp file is considered
+	// left behind by a crashed run
+	orphanTempAge = 24 * time.Hour
+
+	// placedFileMode is the mode of a file moved into place from a temp
+	// file, which is created readable only by its owner
+	placedFileMode = 0644
+)
+
+// TempManager creates temporary files in a deterministic directory and
+// removes them when the process exits
+type TempManager struct {
+	mu           sync.Mutex
+	dir          string
+	files        map[string]struct{}
+	sweptFiles   int
+	reclaimed    int64
+	handlerSetup sync.Once
+
+	// shutdownHandlers counts commands that shut down on SIGINT and
+	// SIGTERM themselves and clean up their temp files on the way
+	shutdownHandlers int
+}
+
+// tempFiles is the process-wide temp manager, configured in the
+// persistent pre-run from --tmp-dir
+var tempFiles = NewTempManager(filepath.Join(stateDirName, tempDirName))
+
+// NewTempManager creates a temp manager rooted at dir
+func NewTempManager(dir string) *TempManager {
+	return &TempManager{
+		dir:   dir,
+		files: make(map[string]struct{}),
+	}
+}
+
+// SetDir changes the directory new temp files are created in
+func (tm *TempManager) SetDir(dir string) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.dir = dir
+}
+
+// Dir returns the directory temp files are created in
+func (tm *TempManager) Dir() string {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	return tm.dir
+}
+
+// CreateTemp creates a new temp file and registers it for cleanup
+func (tm *TempManager) CreateTemp(pattern string) (*os.File, error) {
+	return tm.CreateTempIn(tm.Dir(), pattern)
+}
+
+// CreateTempIn creates a new temp file in dir and registers it for
+// cleanup. A file that is renamed into place belongs next to its target,
+// so the rename stays on one filesystem whatever --tmp-dir is; such
+// files are removed on exit but not swept.
+func (tm *TempManager) CreateTempIn(dir, pattern string) (*os.File, error) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create temp directory %s: %w", dir, err)
+	}
+
+	file, err := ioutil.TempFile(dir, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	tm.files[file.Name()] = struct{}{}
+	return file, nil
+}
+
+// writeFileAtomic writes data to path through a temp file beside it, so
+// an interruption never leaves a truncated file behind
+func writeFileAtomic(path string, data []byte) error {
+	file, err := tempFiles.CreateTempIn(filepath.Dir(path), "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := file.Chmod(placedFileMode); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to set the mode of %s: %w", path, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", path, err)
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("failed to move %s into place: %w", path, err)
+	}
+	tempFiles.Release(file.Name())
+
+	return nil
+}
+
+// Release unregisters a temp file that has been renamed into place or
+// otherwise handed off, so cleanup will not remove it
+func (tm *TempManager) Release(path string) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	delete(tm.files, path)
+}
+
+// Cleanup removes all registered temp files
+func (tm *TempManager) Cleanup() error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	var firstErr error
+	for path := range tm.files {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) && firstErr == nil {
+			firstErr = fmt.Errorf("failed to remove temp file %s: %w", path, err)
+		}
+		delete(tm.files, path)
+	}
+
+	return firstErr
+}
+
+// Sweep removes files in the temp directory older than maxAge, which are
+// left over from runs that crashed before cleaning up. It returns the number
+// of files removed and the bytes reclaimed.
+func (tm *TempManager) Sweep(maxAge time.Duration) (int, int64, error) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	entries, err := ioutil.ReadDir(tm.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, 0, nil
+		}
+		return 0, 0, fmt.Errorf("failed to read temp directory %s: %w", tm.dir, err)
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+	reclaimed := int64(0)
+
+	for _, entry := range entries {
+		if entry.IsDir() || entry.ModTime().After(cutoff) {
+			continue
+		}
+
+		path := filepath.Join(tm.dir, entry.Name())
+		if _, owned := tm.files[path]; owned {
+			continue
+		}
+
+		if err := os.Remove(path); err != nil {
+			continue
+		}
+		removed++
+		reclaimed += entry.Size()
+	}
+
+	tm.sweptFiles += removed
+	tm.reclaimed += reclaimed
+
+	return removed, reclaimed, nil
+}
+
+// Reclaimed returns the number of orphaned files and bytes removed by sweeps
+func (tm *TempManager) Reclaimed() (int, int64) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	return tm.sweptFiles, tm.reclaimed
+}
+
+// HandleSignals clears running progress bars from the terminal and
+// removes registered temp files when the process receives SIGINT or
+// SIGTERM, then re-delivers the signal so commands with their own
+// shutdown handling still see it and others terminate as usual.
+func (tm *TempManager) HandleSignals() {
+	tm.handlerSetup.Do(func() {
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+		go func() {
+			sig := <-sigChan
+			restoreProgress()
+			tm.signalCleanup()
+			signal.Stop(sigChan)
+
+			process, err := os.FindProcess(os.Getpid())
+			if err != nil || process.Signal(sig) != nil {
+				os.Exit(1)
+			}
+		}()
+	})
+}
+
+// signalCleanup removes the registered temp files on a signal, unless a
+// command is shutting down on its own: its temp files may still be open,
+// like a --manifest being written, and are cleaned up once it returns
+func (tm *TempManager) signalCleanup() {
+	tm.mu.Lock()
+	handled := tm.shutdownHandlers > 0
+	tm.mu.Unlock()
+
+	if !handled {
+		tm.Cleanup()
+	}
+}
+
+// HandleShutdown records that the running command shuts down on SIGINT
+// and SIGTERM itself, until the returned function is called, so the
+// signal handler leaves its temp files to it
+func (tm *TempManager) HandleShutdown() func() {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	tm.shutdownHandlers++
+	return func() {
+		tm.mu.Lock()
+		defer tm.mu.Unlock()
+		tm.shutdownHandlers--
+	}
+}
+
+// resolveTempDir returns the temp directory for the given configuration
+func resolveTempDir(config *CommandConfig) string {
+	if config.TmpDir != "" {
+		return config.TmpDir
+	}
+	return config.resolvePath(filepath.Join(stateDirName, tempDirName))
+}
+
+// setupTempFiles points the process-wide temp manager at the configured
+// directory, sweeps orphans from earlier runs and installs signal cleanup
+func setupTempFiles(config *CommandConfig) {
+	tempFiles.SetDir(resolveTempDir(config))
+
+	removed, reclaimed, err := tempFiles.Sweep(orphanTempAge)
+	if err != nil {
+		if config.Verbose {
+			PrintWarning(fmt.Sprintf("Temp file sweep failed: %v", err))
+		}
+	} else if removed > 0 && config.Verbose {
+		PrintInfo(fmt.Sprintf("Removed %d orphaned temp file(s) (%s)", removed, formatBytes(reclaimed)))
+	}
+
+	tempFiles.HandleSignals()
+}
@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestTempManagerCreateAndCleanup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-temp")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tm := NewTempManager(filepath.Join(dir, stateDirName, tempDirName))
+
+	file, err := tm.CreateTemp("index-*.tmp")
+	if err != nil {
+		t.Fatalf("CreateTemp() returned error: %v", err)
+	}
+	file.Close()
+
+	if filepath.Dir(file.Name()) != tm.Dir() {
+		t.Errorf("Expected temp file in %s, got %s", tm.Dir(), file.Name())
+	}
+
+	released, err := tm.CreateTemp("index-*.tmp")
+	if err != nil {
+		t.Fatalf("CreateTemp() returned error: %v", err)
+	}
+	released.Close()
+	tm.Release(released.Name())
+
+	if err := tm.Cleanup(); err != nil {
+		t.Errorf("Cleanup() returned error: %v", err)
+	}
+
+	if _, err := os.Stat(file.Name()); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed by Cleanup()", file.Name())
+	}
+
+	if _, err := os.Stat(released.Name()); err != nil {
+		t.Errorf("Expected released file %s to survive Cleanup(): %v", released.Name(), err)
+	}
+}
+
+func TestTempManagerSignalCleanup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-temp")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tm := NewTempManager(dir)
+	file, err := tm.CreateTemp("signal-*.tmp")
+	if err != nil {
+		t.Fatalf("CreateTemp() returned error: %v", err)
+	}
+	file.Close()
+	open := file.Name()
+
+	// A command shutting down on its own keeps its temp files
+	done := tm.HandleShutdown()
+	tm.signalCleanup()
+	if _, err := os.Stat(open); err != nil {
+		t.Errorf("Expected %s to survive a signal during a graceful shutdown: %v", open, err)
+	}
+	done()
+
+	tm.signalCleanup()
+	if _, err := os.Stat(open); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed on a signal", open)
+	}
+}
+
+func TestStateFilesSaveBesideTarget(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-temp")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// With --tmp-dir elsewhere, the files must still be written in the
+	// state directory so the final rename does not cross filesystems
+	tmpDir := filepath.Join(dir, "tmp")
+	defer func(old *TempManager) { tempFiles = old }(tempFiles)
+	tempFiles = NewTempManager(tmpDir)
+
+	stateDir := filepath.Join(dir, stateDirName)
+	index, err := LoadIndexManifest(filepath.Join(stateDir, "manifest.json"))
+	if err != nil {
+		t.Fatalf("LoadIndexManifest() returned error: %v", err)
+	}
+
+	saves := map[string]func() error{
+		"manifest.json":   index.Save,
+		"checkpoint.json": NewCheckpoint(filepath.Join(stateDir, "checkpoint.json"), nil).Save,
+		"baseline.json": func() error {
+			return NewMonitorBaseline(filepath.Join(stateDir, "baseline.json"), nil).Save(snapshot{})
+		},
+	}
+	for name, save := range saves {
+		if err := save(); err != nil {
+			t.Errorf("Saving %s returned error: %v", name, err)
+		}
+	}
+
+	if _, err := os.Stat(tmpDir); !os.IsNotExist(err) {
+		t.Errorf("Expected no temp files in --tmp-dir %s", tmpDir)
+	}
+	entries, err := ioutil.ReadDir(stateDir)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", stateDir, err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+		if runtime.GOOS != "windows" && entry.Mode().Perm() != placedFileMode {
+			t.Errorf("%s mode = %v, expected %v", entry.Name(), entry.Mode().Perm(), os.FileMode(placedFileMode))
+		}
+	}
+	expected := []string{"baseline.json", "checkpoint.json", "manifest.json"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("state directory holds %v, expected %v", names, expected)
+	}
+}
+
+func TestTempManagerSweep(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-temp")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tm := NewTempManager(dir)
+
+	orphan := filepath.Join(dir, "orphan.tmp")
+	if err := ioutil.WriteFile(orphan, make([]byte, 2048), 0644); err != nil {
+		t.Fatalf("Failed to write orphan: %v", err)
+	}
+	old := time.Now().Add(-2 * orphanTempAge)
+	if err := os.Chtimes(orphan, old, old); err != nil {
+		t.Fatalf("Failed to age orphan: %v", err)
+	}
+
+	fresh := filepath.Join(dir, "fresh.tmp")
+	if err := ioutil.WriteFile(fresh, []byte("in use"), 0644); err != nil {
+		t.Fatalf("Failed to write fresh file: %v", err)
+	}
+
+	removed, reclaimed, err := tm.Sweep(orphanTempAge)
+	if err != nil {
+		t.Fatalf("Sweep() returned error: %v", err)
+	}
+
+	if removed != 1 {
+		t.Errorf("Expected 1 file removed, got %d", removed)
+	}
+
+	if reclaimed != 2048 {
+		t.Errorf("Expected 2048 bytes reclaimed, got %d", reclaimed)
+	}
+
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("Expected fresh file to survive Sweep(): %v", err)
+	}
+
+	files, bytes := tm.Reclaimed()
+	if files != 1 || bytes != 2048 {
+		t.Errorf("Reclaimed() = (%d, %d), expected (1, 2048)", files, bytes)
+	}
+}
+
+func TestTempManagerSweepMissingDir(t *testing.T) {
+	tm := NewTempManager(filepath.Join(os.TempDir(), "stroidex-missing", "tmp"))
+
+	removed, _, err := tm.Sweep(orphanTempAge)
+	if err != nil {
+		t.Errorf("Sweep() on missing dir returned error: %v", err)
+	}
+	if removed != 0 {
+		t.Errorf("Expected 0 files removed, got %d", removed)
+	}
+}
+
+func TestResolveTempDir(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *CommandConfig
+		expected string
+	}{
+		{"Default", &CommandConfig{}, filepath.Join(".stroidex", "tmp")},
+		{"Override", &CommandConfig{TmpDir: "/var/tmp/stroidex"}, "/var/tmp/stroidex"},
+		{"Workspace", &CommandConfig{Workspace: "project"}, filepath.Join("project", ".stroidex", "tmp")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveTempDir(tt.config); got != tt.expected {
+				t.Errorf("resolveTempDir() = %s, expected %s", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// Theme maps output roles to ANSI SGR color codes. An empty code leaves
+// that role uncolored.
+type Theme struct {
+	Name    string
+	Fill    string // filled portion of progress bars and spinner glyphs
+	Percent string // completion percentage
+	ETA     string // remaining and elapsed time
+	Error   string // failed progress bars and unhealthy statuses
+	Healthy string // healthy statuses and success messages
+	Warning string // degraded statuses and warnings
+	Info    string // informational messages
+	Header  string // table headers
+}
+
+// themes holds the themes selectable with --theme
+var themes = map[string]Theme{
+	"default": {Name: "default", Fill: "32", Percent: "1", ETA: "36", Error: "31", Healthy: "32", Warning: "33", Info: "36", Header: "1"},
+	"dark":    {Name: "dark", Fill: "92", Percent: "97", ETA: "96", Error: "91", Healthy: "92", Warning: "93", Info: "96", Header: "1;97"},
+	"light":   {Name: "light", Fill: "34", Percent: "30", ETA: "35", Error: "31", Healthy: "32", Warning: "33", Info: "34", Header: "1;30"},
+	"none":    {Name: "none"},
+}
+
+// activeTheme is the theme new progress bars are drawn with. It stays
+// uncolored until the persistent pre-run applies the configured theme.
+var activeTheme = themes["none"]
+
+// Paint wraps s in the escape sequence for code, returning s unchanged
+// when either is empty
+func (t Theme) Paint(code, s string) string {
+	if code == "" || s == "" {
+		return s
+	}
+	return "\033[" + code + "m" + s + "\033[0m"
+}
+
+// PaintStatus colors a health status: green when healthy, yellow when
+// degraded and red when unhealthy. Unknown statuses stay uncolored.
+func (t Theme) PaintStatus(status string) string {
+	switch status {
+	case "healthy", "ok", "pass":
+		return t.Paint(t.Healthy, status)
+	case "degraded", "warning", "warn":
+		return t.Paint(t.Warning, status)
+	case "unhealthy", "error", "fail":
+		return t.Paint(t.Error, status)
+	default:
+		return status
+	}
+}
+
+// Values of --color
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+// stdoutIsTerminal reports whether stdout is a terminal, which
+// --color=auto colors; a variable so tests can stand in for a terminal
+var stdoutIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// validateColor validates --color; empty means auto. --no-color is an
+// alias of --color=never, so it contradicts --color=always.
+func validateColor(config *CommandConfig) error {
+	switch config.Color {
+	case "", colorAuto, colorAlways, colorNever:
+	default:
+		return fmt.Errorf("invalid color mode: %s (valid: auto, always, never)", config.Color)
+	}
+
+	if config.NoColor && config.Color == colorAlways {
+		return fmt.Errorf("--no-color cannot be combined with --color=always")
+	}
+	return nil
+}
+
+// colorDisabled reports whether output is uncolored: always with
+// --color=never or --no-color, never with --color=always, which colors
+// piped output too, and otherwise when the NO_COLOR environment variable
+// is set or stdout is not a terminal
+func colorDisabled(config *CommandConfig) bool {
+	switch {
+	case config.NoColor || config.Color == colorNever:
+		return true
+	case config.Color == colorAlways:
+		return false
+	}
+	return os.Getenv("NO_COLOR") != "" || !stdoutIsTerminal()
+}
+
+// resolveTheme returns the theme selected by config, or the uncolored
+// theme when colors are disabled or the name is unknown
+func resolveTheme(config *CommandConfig) Theme {
+	if colorDisabled(config) {
+		return themes["none"]
+	}
+
+	theme, ok := themes[config.Theme]
+	if !ok {
+		return themes["none"]
+	}
+	return theme
+}
+
+// setupTheme applies the configured theme to subsequent output
+func setupTheme(config *CommandConfig) {
+	activeTheme = resolveTheme(config)
+}
+
+// visibleWidth returns the number of runes in s that occupy a terminal
+// column, skipping ANSI escape sequences
+func visibleWidth(s string) int {
+	width := 0
+	for len(s) > 0 {
+		if strings.HasPrefix(s, "\033[") {
+			end := strings.IndexFunc(s[2:], func(r rune) bool {
+				return r >= '@' && r <= '~'
+			})
+			if end < 0 {
+				break
+			}
+			s = s[end+3:]
+			continue
+		}
+
+		_, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+		width++
+	}
+	return width
+}
@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// pretendTerminal makes --color=auto see stdout as a terminal, or not,
+// until the test ends
+func pretendTerminal(t *testing.T, terminal bool) {
+	t.Helper()
+
+	isTerminal := stdoutIsTerminal
+	stdoutIsTerminal = func() bool { return terminal }
+	t.Cleanup(func() { stdoutIsTerminal = isTerminal })
+}
+
+func TestResolveTheme(t *testing.T) {
+	tests := []struct {
+		name     string
+		theme    string
+		noColor  bool
+		envColor string
+		expected string
+	}{
+		{"Default theme", "default", false, "", "default"},
+		{"Dark theme", "dark", false, "", "dark"},
+		{"None theme", "none", false, "", "none"},
+		{"No color flag", "dark", true, "", "none"},
+		{"NO_COLOR env", "light", false, "1", "none"},
+		{"Unknown theme", "neon", false, "", "none"},
+	}
+
+	pretendTerminal(t, true)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.envColor)
+
+			config := &CommandConfig{Theme: tt.theme, NoColor: tt.noColor}
+			if got := resolveTheme(config); got.Name != tt.expected {
+				t.Errorf("resolveTheme() = %s, expected %s", got.Name, tt.expected)
+			}
+		})
+	}
+}
+
+func TestColorModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *CommandConfig
+		terminal bool
+		envColor string
+		want     bool
+	}{
+		{"Auto on a terminal", &CommandConfig{Color: colorAuto}, true, "", true},
+		{"Auto when piped", &CommandConfig{Color: colorAuto}, false, "", false},
+		{"Auto with NO_COLOR", &CommandConfig{Color: colorAuto}, true, "1", false},
+		{"Empty means auto", &CommandConfig{}, true, "", true},
+		{"Always when piped", &CommandConfig{Color: colorAlways}, false, "", true},
+		{"Always overrides NO_COLOR", &CommandConfig{Color: colorAlways}, false, "1", true},
+		{"Never on a terminal", &CommandConfig{Color: colorNever}, true, "", false},
+		{"No color alias", &CommandConfig{Color: colorAuto, NoColor: true}, true, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preserveTheme(t)
+			pretendTerminal(t, tt.terminal)
+			t.Setenv("NO_COLOR", tt.envColor)
+
+			tt.config.Theme = "default"
+			setupTheme(tt.config)
+			setupOutput(tt.config)
+
+			// Print helpers and tables
+			stdout := captureStdout(t, func() {
+				PrintInfo("watching")
+				PrintTable([]string{"Metric", "Value"}, [][]string{{"Files", "2"}})
+			})
+			if got := strings.Contains(stdout, "\033["); got != tt.want {
+				t.Errorf("Print helper output colored = %v, expected %v: %q", got, tt.want, stdout)
+			}
+			if got := strings.Contains(stdout, "\033[1mMETRIC"); got != tt.want {
+				t.Errorf("table header colored = %v, expected %v: %q", got, tt.want, stdout)
+			}
+
+			// Progress bars
+			var buf bytes.Buffer
+			pb := NewProgressBar("Indexing", 10).WithWriter(&buf)
+			pb.style.Width = 10
+			pb.Start()
+			pb.Stop()
+			if got := strings.Contains(buf.String(), "\033["); got != tt.want {
+				t.Errorf("progress bar colored = %v, expected %v: %q", got, tt.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestValidateColor(t *testing.T) {
+	for _, color := range []string{"", colorAuto, colorAlways, colorNever} {
+		if err := validateColor(&CommandConfig{Color: color}); err != nil {
+			t.Errorf("validateColor(%q) error = %v", color, err)
+		}
+	}
+
+	if err := validateColor(&CommandConfig{Color: "sometimes"}); err == nil || !strings.Contains(err.Error(), "auto, always, never") {
+		t.Errorf("validateColor(sometimes) error = %v, expected the valid modes", err)
+	}
+	if err := validateColor(&CommandConfig{Color: colorAlways, NoColor: true}); err == nil {
+		t.Error("validateColor() accepted --no-color with --color=always")
+	}
+	if err := validateColor(&CommandConfig{Color: colorNever, NoColor: true}); err != nil {
+		t.Errorf("validateColor() rejected --no-color with --color=never: %v", err)
+	}
+}
+
+func TestThemePaint(t *testing.T) {
+	theme := themes["default"]
+
+	if got := theme.Paint("", "text"); got != "text" {
+		t.Errorf("Paint() with empty code = %q, expected plain text", got)
+	}
+	if got := theme.Paint("32", ""); got != "" {
+		t.Errorf("Paint() of empty string = %q, expected empty", got)
+	}
+	if got := theme.Paint("32", "ok"); got != "\033[32mok\033[0m" {
+		t.Errorf("Paint() = %q, expected green ok", got)
+	}
+}
+
+func TestColoredProgressBar(t *testing.T) {
+	tests := []struct {
+		theme     string
+		wantColor bool
+	}{
+		{"default", true},
+		{"dark", true},
+		{"light", true},
+		{"none", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.theme, func(t *testing.T) {
+			pb := NewProgressBar("Test", 10)
+			pb.theme = themes[tt.theme]
+			pb.style.Width = 20
+			pb.current = 5
+
+			frame := pb.RenderString()
+			if got := strings.Contains(frame, "\033["); got != tt.wantColor {
+				t.Errorf("Frame contains ANSI codes = %v, expected %v: %q", got, tt.wantColor, frame)
+			}
+
+			// Colors must not change the visible layout
+			pb.theme = themes["none"]
+			if plain := pb.RenderString(); visibleWidth(frame) != utf8.RuneCountInString(plain) {
+				t.Errorf("visibleWidth(%q) = %d, expected %d", frame, visibleWidth(frame), utf8.RuneCountInString(plain))
+			}
+		})
+	}
+}
+
+func TestPaintStatus(t *testing.T) {
+	theme := themes["default"]
+
+	tests := []struct {
+		status   string
+		expected string
+	}{
+		{"healthy", "\033[32mhealthy\033[0m"},
+		{"ok", "\033[32mok\033[0m"},
+		{"degraded", "\033[33mdegraded\033[0m"},
+		{"warning", "\033[33mwarning\033[0m"},
+		{"unhealthy", "\033[31munhealthy\033[0m"},
+		{"unknown", "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			if got := theme.PaintStatus(tt.status); got != tt.expected {
+				t.Errorf("PaintStatus(%s) = %q, expected %q", tt.status, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHealthStatusColors(t *testing.T) {
+	health := HealthStatus{
+		Status:     "degraded",
+		Components: map[string]string{"database": "healthy", "disk_space": "warning"},
+	}
+
+	tests := []struct {
+		theme     string
+		wantColor bool
+	}{
+		{"default", true},
+		{"dark", true},
+		{"none", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.theme, func(t *testing.T) {
+			previous := activeTheme
+			activeTheme = themes[tt.theme]
+			defer func() { activeTheme = previous }()
+
+			sc := &StatusCommand{config: &CommandConfig{OutputFormat: "table"}}
+			output := captureStdout(t, func() {
+				if err := sc.displayHealthStatus(health); err != nil {
+					t.Fatalf("displayHealthStatus() error = %v", err)
+				}
+				if err := sc.displayStatusTable(&StatusReport{Health: health}); err != nil {
+					t.Fatalf("displayStatusTable() error = %v", err)
+				}
+			})
+
+			if got := strings.Contains(output, "\033["); got != tt.wantColor {
+				t.Errorf("Output contains ANSI codes = %v, expected %v: %q", got, tt.wantColor, output)
+			}
+			if !strings.Contains(output, "degraded") || !strings.Contains(output, "warning") {
+				t.Errorf("Output is missing statuses: %q", output)
+			}
+		})
+	}
+}
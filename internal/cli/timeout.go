@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// startTimeout creates the context commands run under, which expires
+// after --timeout when one is set
+func (c *CommandConfig) startTimeout() {
+	c.stopTimeout()
+
+	if c.Timeout > 0 {
+		c.ctx, c.cancel = context.WithTimeout(context.Background(), c.Timeout)
+		return
+	}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+}
+
+// stopTimeout releases the resources of the --timeout context
+func (c *CommandConfig) stopTimeout() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+}
+
+// Context returns the context long-running commands derive theirs from.
+// It is done once --timeout has elapsed.
+func (c *CommandConfig) Context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// timeoutError replaces the error of a command that ran past --timeout,
+// which is often just a cancelled context or none at all, with one
+// naming the timeout
+func (c *CommandConfig) timeoutError(err error) error {
+	if c.Timeout <= 0 || !errors.Is(c.Context().Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return fmt.Errorf("timed out after %v (--timeout)", c.Timeout)
+}
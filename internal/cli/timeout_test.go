@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIndexTimeout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-timeout")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	const total = 100
+	for i := 0; i < total; i++ {
+		if err := ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("file%d.md", i)), []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	config := &CommandConfig{Timeout: 50 * time.Millisecond}
+	config.startTimeout()
+	defer config.stopTimeout()
+
+	var calls int32
+	ic := &IndexCommand{
+		config:     config,
+		recursive:  true,
+		maxDepth:   -1,
+		patterns:   []string{"*"},
+		maxWorkers: 1,
+		batchSize:  10,
+		indexType:  "full",
+		processFn: func(ctx context.Context, filePath string, stats *IndexStats) error {
+			atomic.AddInt32(&calls, 1)
+			select {
+			case <-time.After(20 * time.Millisecond):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+		indexManifestPath: filepath.Join(dir, "manifest.json"),
+	}
+
+	start := time.Now()
+	captureStdout(t, func() {
+		err = ic.runIndex(nil, []string{dir})
+	})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("runIndex() error = %v, expected the deadline to be exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("runIndex() took %v, expected it to stop shortly after the timeout", elapsed)
+	}
+	if n := atomic.LoadInt32(&calls); n == 0 || n >= total {
+		t.Errorf("processed %d of %d files, expected a partial run", n, total)
+	}
+
+	err = config.timeoutError(err)
+	if err == nil || !strings.Contains(err.Error(), "timed out after 50ms") {
+		t.Errorf("timeoutError() = %v, expected a timeout error", err)
+	}
+}
+
+func TestTimeoutError(t *testing.T) {
+	failure := errors.New("failed")
+
+	// Without --timeout, or before it expires, errors pass through
+	for _, config := range []*CommandConfig{{}, {Timeout: time.Hour}} {
+		config.startTimeout()
+		if err := config.timeoutError(failure); err != failure {
+			t.Errorf("timeoutError() with timeout %v = %v, expected the error unchanged", config.Timeout, err)
+		}
+		if err := config.timeoutError(nil); err != nil {
+			t.Errorf("timeoutError(nil) with timeout %v = %v, expected nil", config.Timeout, err)
+		}
+		config.stopTimeout()
+	}
+
+	// A command that stops cleanly on expiry still reports the timeout
+	config := &CommandConfig{Timeout: time.Millisecond}
+	config.startTimeout()
+	defer config.stopTimeout()
+	<-config.Context().Done()
+
+	if err := config.timeoutError(nil); err == nil || !strings.Contains(err.Error(), "--timeout") {
+		t.Errorf("timeoutError(nil) after expiry = %v, expected a timeout error", err)
+	}
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Build information, set at link time with
+//
+//	go build -ldflags "-X stroidex/internal/cli.Version=v1.2.0 \
+//	  -X stroidex/internal/cli.Commit=abc1234 \
+//	  -X stroidex/internal/cli.BuildDate=2024-01-01T00:00:00Z"
+//
+// Unset values fall back to the module build information.
+var (
+	Version   string
+	Commit    string
+	BuildDate string
+)
+
+// develVersion is reported for builds without version information
+const develVersion = "(devel)"
+
+// BuildInfo describes the running binary
+type BuildInfo struct {
+	Version   string `json:"version" yaml:"version"`
+	Commit    string `json:"commit,omitempty" yaml:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty" yaml:"build_date,omitempty"`
+	GoVersion string `json:"go_version" yaml:"go_version"`
+	Platform  string `json:"platform" yaml:"platform"`
+}
+
+// buildInfo returns the injected build information, filling in what was
+// not injected from the module build information
+func buildInfo() BuildInfo {
+	info := BuildInfo{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	if module, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && module.Main.Version != develVersion {
+			info.Version = module.Main.Version
+		}
+		revision, date := vcsInfo(module)
+		if info.Commit == "" {
+			info.Commit = revision
+		}
+		if info.BuildDate == "" {
+			info.BuildDate = date
+		}
+	}
+
+	if info.Version == "" {
+		info.Version = develVersion
+	}
+	return info
+}
+
+// vcsInfo returns the revision and commit time recorded by the go tool
+func vcsInfo(module *debug.BuildInfo) (revision, date string) {
+	for _, setting := range module.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.time":
+			date = setting.Value
+		}
+	}
+	return revision, date
+}
+
+// shortCommit abbreviates a commit hash for display
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+// versionString formats the version with the commit and build date when
+// known, e.g. "v1.2.0 (commit abc1234, built 2024-01-01T00:00:00Z)"
+func versionString() string {
+	info := buildInfo()
+
+	var details []string
+	if info.Commit != "" {
+		details = append(details, "commit "+shortCommit(info.Commit))
+	}
+	if info.BuildDate != "" {
+		details = append(details, "built "+info.BuildDate)
+	}
+
+	if len(details) == 0 {
+		return info.Version
+	}
+	return fmt.Sprintf("%s (%s)", info.Version, strings.Join(details, ", "))
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"runtime"
+	"testing"
+)
+
+// setBuildVars sets the link-time build variables for the duration of
+// the test
+func setBuildVars(t *testing.T, version, commit, date string) {
+	t.Helper()
+
+	previous := [3]string{Version, Commit, BuildDate}
+	t.Cleanup(func() {
+		Version, Commit, BuildDate = previous[0], previous[1], previous[2]
+	})
+	Version, Commit, BuildDate = version, commit, date
+}
+
+func TestVersionStringInjected(t *testing.T) {
+	setBuildVars(t, "v1.2.0", "0123456789abcdef", "2024-05-01T12:00:00Z")
+
+	expected := "v1.2.0 (commit 0123456789ab, built 2024-05-01T12:00:00Z)"
+	if got := versionString(); got != expected {
+		t.Errorf("versionString() = %q, expected %q", got, expected)
+	}
+
+	info := buildInfo()
+	if info.Version != "v1.2.0" || info.Commit != "0123456789abcdef" || info.BuildDate != "2024-05-01T12:00:00Z" {
+		t.Errorf("buildInfo() = %+v, expected the injected values", info)
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("GoVersion = %s, expected %s", info.GoVersion, runtime.Version())
+	}
+
+	cli := NewCLI()
+	if cli.RootCmd.Version != expected {
+		t.Errorf("root command version = %q, expected %q", cli.RootCmd.Version, expected)
+	}
+}
+
+func TestVersionStringFallback(t *testing.T) {
+	setBuildVars(t, "", "", "")
+
+	// Test binaries carry no module version or VCS information
+	if got := buildInfo().Version; got != develVersion {
+		t.Errorf("buildInfo().Version = %q, expected %q", got, develVersion)
+	}
+	if got := versionString(); got == "" {
+		t.Error("versionString() is empty")
+	}
+}
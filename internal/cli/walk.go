@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// symlinkWalker walks a tree like filepath.Walk but descends into
+// symlinked directories. A directory that resolves to one of its own
+// ancestors is reported to onLoop and not descended into, which breaks
+// symlink cycles.
+type symlinkWalker struct {
+	fn     filepath.WalkFunc
+	onLoop func(path string)
+
+	// ancestors holds the identities of the directories on the current
+	// path from the root
+	ancestors map[fileID]bool
+}
+
+// walkFollowingSymlinks walks root like filepath.Walk, following symlinks
+// to directories and files. Paths passed to fn are below root as written,
+// not resolved.
+func walkFollowingSymlinks(root string, fn filepath.WalkFunc, onLoop func(path string)) error {
+	w := &symlinkWalker{
+		fn:        fn,
+		onLoop:    onLoop,
+		ancestors: make(map[fileID]bool),
+	}
+
+	info, err := w.stat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = w.walk(root, info)
+	}
+	if err == filepath.SkipDir {
+		return nil
+	}
+	return err
+}
+
+// stat returns the info of path, resolving a symlink to its target
+func (w *symlinkWalker) stat(path string) (os.FileInfo, error) {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return info, err
+	}
+	return os.Stat(path)
+}
+
+// walk visits path and, for directories, everything below it
+func (w *symlinkWalker) walk(path string, info os.FileInfo) error {
+	if !info.IsDir() {
+		return w.fn(path, info, nil)
+	}
+
+	id, ok := identify(path, info)
+	if ok {
+		if w.ancestors[id] {
+			if w.onLoop != nil {
+				w.onLoop(path)
+			}
+			return nil
+		}
+		w.ancestors[id] = true
+		defer delete(w.ancestors, id)
+	}
+
+	if err := w.fn(path, info, nil); err != nil {
+		return err
+	}
+
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return w.fn(path, info, err)
+	}
+
+	for _, entry := range entries {
+		child := filepath.Join(path, entry.Name())
+
+		childInfo := entry
+		if entry.Mode()&os.ModeSymlink != 0 {
+			childInfo, err = os.Stat(child)
+			if err != nil {
+				if err := w.fn(child, entry, err); err != nil && err != filepath.SkipDir {
+					return err
+				}
+				continue
+			}
+		}
+
+		if err := w.walk(child, childInfo); err != nil {
+			if !childInfo.IsDir() || err != filepath.SkipDir {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// makeSymlinkTree creates root/a/file.md, root/a/loop -> root and
+// root/ext -> an outside directory holding ext.md
+func makeSymlinkTree(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "stroidex-symlink")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	root := filepath.Join(dir, "root")
+	outside := filepath.Join(dir, "outside")
+	for _, d := range []string{filepath.Join(root, "a"), outside} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", d, err)
+		}
+	}
+	for _, f := range []string{filepath.Join(root, "a", "file.md"), filepath.Join(outside, "ext.md")} {
+		if err := ioutil.WriteFile(f, []byte("content"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", f, err)
+		}
+	}
+
+	if err := os.Symlink(root, filepath.Join(root, "a", "loop")); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "ext")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	return root
+}
+
+func TestWalkFollowingSymlinksBreaksLoops(t *testing.T) {
+	root := makeSymlinkTree(t)
+
+	var (
+		files []string
+		loops []string
+	)
+	done := make(chan error, 1)
+	go func() {
+		done <- walkFollowingSymlinks(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				rel, _ := filepath.Rel(root, path)
+				files = append(files, filepath.ToSlash(rel))
+			}
+			return nil
+		}, func(path string) {
+			loops = append(loops, path)
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("walkFollowingSymlinks() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("walkFollowingSymlinks() did not terminate on a symlink cycle")
+	}
+
+	sort.Strings(files)
+	expected := []string{"a/file.md", "ext/ext.md"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("walked files = %v, expected %v", files, expected)
+	}
+
+	if len(loops) != 1 || loops[0] != filepath.Join(root, "a", "loop") {
+		t.Errorf("loops = %v, expected only %s", loops, filepath.Join(root, "a", "loop"))
+	}
+}
+
+func TestIndexCollectFilesFollowSymlinks(t *testing.T) {
+	root := makeSymlinkTree(t)
+
+	tests := []struct {
+		name        string
+		followLinks bool
+		expected    []string
+	}{
+		{"Default does not follow", false, []string{"ext", "file.md", "loop"}},
+		{"Follow symlinks", true, []string{"ext.md", "file.md"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ic := &IndexCommand{
+				config:      &CommandConfig{},
+				paths:       []string{root},
+				recursive:   true,
+				maxDepth:    -1,
+				followLinks: tt.followLinks,
+				patterns:    []string{"*"},
+				noIgnore:    true,
+			}
+
+			files, err := ic.collectFiles(context.Background(), &IndexStats{})
+			if err != nil {
+				t.Fatalf("collectFiles() error = %v", err)
+			}
+
+			var names []string
+			for _, file := range files {
+				names = append(names, filepath.Base(file))
+			}
+			sort.Strings(names)
+
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("collectFiles() = %v, expected %v", names, tt.expected)
+			}
+		})
+	}
+}
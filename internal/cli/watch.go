@@ -0,0 +1,237 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// eventBuffer is the number of file events queued before the watch loop
+// waits for the monitor to catch up
+const eventBuffer = 256
+
+// FileEvent is a change to a watched file. A rename detected as such
+// moved the file from OldPath to Path.
+type FileEvent struct {
+	Path    string
+	OldPath string
+	Op      string // create, write, remove or rename
+	Time    time.Time
+}
+
+// EventRecord is a file event as streamed with a structured output
+// format. Size is omitted for files that no longer exist.
+type EventRecord struct {
+	Path      string    `json:"path" yaml:"path"`
+	OldPath   string    `json:"old_path,omitempty" yaml:"old_path,omitempty"`
+	Event     string    `json:"event" yaml:"event"`
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`
+	Size      *int64    `json:"size,omitempty" yaml:"size,omitempty"`
+}
+
+// newEventRecord describes event, reading the current size of the file
+func newEventRecord(event FileEvent) EventRecord {
+	record := EventRecord{
+		Path:      event.Path,
+		OldPath:   event.OldPath,
+		Event:     event.Op,
+		Timestamp: event.Time,
+	}
+	if info, err := os.Stat(event.Path); err == nil && !info.IsDir() {
+		size := info.Size()
+		record.Size = &size
+	}
+	return record
+}
+
+// eventOp names the change in an fsnotify event, or returns "" for
+// changes the monitor does not report, such as chmod
+func eventOp(op fsnotify.Op) string {
+	switch {
+	case op&fsnotify.Create != 0:
+		return "create"
+	case op&fsnotify.Write != 0:
+		return "write"
+	case op&fsnotify.Remove != 0:
+		return "remove"
+	case op&fsnotify.Rename != 0:
+		return "rename"
+	}
+	return ""
+}
+
+// startWatching creates the file system watcher and adds the monitored
+// paths. In recursive mode every subdirectory found below them is
+// watched too.
+func (mc *MonitorCommand) startWatching() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	mc.watcher = watcher
+
+	for _, path := range mc.paths {
+		if err := mc.addWatch(path, path); err != nil {
+			watcher.Close()
+			mc.watcher = nil
+			return err
+		}
+	}
+	return nil
+}
+
+// addWatch watches path, a monitored root or a directory below root, and
+// in recursive mode the directories below it within --max-depth
+func (mc *MonitorCommand) addWatch(root, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to watch %s: %w", path, err)
+	}
+
+	if !info.IsDir() || !mc.recursive {
+		if err := mc.watcher.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		return nil
+	}
+
+	return filepath.Walk(path, func(walkPath string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return nil // Skip errors and files
+		}
+
+		rel := relativePath(root, walkPath)
+		if walkPath != root && (info.Name() == stateDirName || !withinMaxDepth(rel, mc.maxDepth)) {
+			return filepath.SkipDir
+		}
+
+		if err := mc.watcher.Add(walkPath); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", walkPath, err)
+		}
+		return nil
+	})
+}
+
+// watch translates watcher notifications into file events until ctx is
+// done or the watcher is closed. Directories created in recursive mode
+// are watched as they appear.
+func (mc *MonitorCommand) watch(ctx context.Context) <-chan FileEvent {
+	events := make(chan FileEvent, eventBuffer)
+
+	go func() {
+		defer close(events)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err, ok := <-mc.watcher.Errors:
+				if !ok {
+					return
+				}
+				PrintWarning(fmt.Sprintf("Watch error: %v", err))
+			case ev, ok := <-mc.watcher.Events:
+				if !ok {
+					return
+				}
+
+				event, ok := mc.fileEvent(ev)
+				if !ok {
+					continue
+				}
+
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events
+}
+
+// fileEvent converts a watcher notification, reporting false for
+// notifications that are filtered out
+func (mc *MonitorCommand) fileEvent(ev fsnotify.Event) (FileEvent, bool) {
+	op := eventOp(ev.Op)
+	if op == "" {
+		return FileEvent{}, false
+	}
+
+	root := mc.rootOf(ev.Name)
+	rel := relativePath(root, ev.Name)
+
+	// Stroidex's own state, e.g. the index manifest, is not a change
+	if isStatePath(rel) {
+		return FileEvent{}, false
+	}
+
+	if op == "create" && mc.recursive {
+		if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+			if withinMaxDepth(rel, mc.maxDepth) {
+				if err := mc.addWatch(root, ev.Name); err != nil && mc.config.Verbose {
+					PrintWarning(err.Error())
+				}
+			}
+			return FileEvent{}, false
+		}
+	}
+
+	if !mc.pathMatcher().Matches(rel) {
+		return FileEvent{}, false
+	}
+
+	return FileEvent{Path: ev.Name, Op: op, Time: time.Now()}, true
+}
+
+// rootOf returns the monitored path that filePath lies below
+func (mc *MonitorCommand) rootOf(filePath string) string {
+	best := ""
+	for _, root := range mc.paths {
+		rel, err := filepath.Rel(root, filePath)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(root) > len(best) {
+			best = root
+		}
+	}
+	if best == "" {
+		return filepath.Dir(filePath)
+	}
+	return best
+}
+
+// isStatePath reports whether relPath lies inside the state directory
+func isStatePath(relPath string) bool {
+	for _, segment := range strings.Split(filepath.ToSlash(relPath), "/") {
+		if segment == stateDirName {
+			return true
+		}
+	}
+	return false
+}
+
+// loadPatternFiles merges the patterns of --include-from and
+// --exclude-from into the pattern lists
+func (mc *MonitorCommand) loadPatternFiles() error {
+	patterns, exclude, err := mergePatternFiles(mc.config, mc.patterns, mc.exclude, mc.includeFrom, mc.excludeFrom)
+	if err != nil {
+		return err
+	}
+	mc.patterns, mc.exclude = patterns, exclude
+	return nil
+}
+
+// pathMatcher returns the matcher of --pattern and --exclude, with the
+// same semantics as the index command
+func (mc *MonitorCommand) pathMatcher() *PathMatcher {
+	return &PathMatcher{Include: mc.patterns, Exclude: mc.exclude}
+}
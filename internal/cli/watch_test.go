@@ -0,0 +1,352 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// waitForEvent returns the first event for path, failing after a timeout
+func waitForEvent(t *testing.T, events <-chan FileEvent, path string) FileEvent {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatalf("Event channel closed before an event for %s", path)
+			}
+			if event.Path == path {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("No event delivered for %s", path)
+		}
+	}
+}
+
+func TestMonitorWatchDeliversEvents(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-watch")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// A subdirectory found by the initial walk
+	sub := filepath.Join(dir, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatalf("Failed to create subdir: %v", err)
+	}
+
+	mc := &MonitorCommand{
+		config:    &CommandConfig{},
+		paths:     []string{dir},
+		recursive: true,
+		maxDepth:  -1,
+		patterns:  []string{"*.md"},
+	}
+	if err := mc.startWatching(); err != nil {
+		t.Fatalf("startWatching() error = %v", err)
+	}
+	defer mc.watcher.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := mc.watch(ctx)
+
+	// Filtered out by --pattern
+	if err := ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("skip"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	created := filepath.Join(dir, "a.md")
+	if err := ioutil.WriteFile(created, []byte("new"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if event := waitForEvent(t, events, created); event.Op != "create" {
+		t.Errorf("event for %s = %q, expected create", created, event.Op)
+	}
+
+	nested := filepath.Join(sub, "b.md")
+	if err := ioutil.WriteFile(nested, []byte("nested"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	waitForEvent(t, events, nested)
+
+	mc.watcher.Close()
+	for event := range events {
+		if filepath.Ext(event.Path) != ".md" {
+			t.Errorf("Unexpected event %+v for a path not matching --pattern", event)
+		}
+	}
+}
+
+func TestMonitorWatchesNewDirectories(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-watch-new")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	mc := &MonitorCommand{
+		config:    &CommandConfig{},
+		paths:     []string{dir},
+		recursive: true,
+		maxDepth:  -1,
+		patterns:  []string{"*"},
+	}
+	if err := mc.startWatching(); err != nil {
+		t.Fatalf("startWatching() error = %v", err)
+	}
+	defer mc.watcher.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := mc.watch(ctx)
+
+	sub := filepath.Join(dir, "later")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatalf("Failed to create subdir: %v", err)
+	}
+
+	// The directory is watched once its create event is handled; keep
+	// rewriting the file until a change inside it is seen
+	nested := filepath.Join(sub, "c.md")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if err := ioutil.WriteFile(nested, []byte(time.Now().String()), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+
+		select {
+		case event := <-events:
+			if event.Path == nested {
+				return
+			}
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("No event delivered for %s in a directory created after watching started", nested)
+		}
+	}
+}
+
+func TestEventOp(t *testing.T) {
+	tests := []struct {
+		op       fsnotify.Op
+		expected string
+	}{
+		{fsnotify.Create, "create"},
+		{fsnotify.Write, "write"},
+		{fsnotify.Remove, "remove"},
+		{fsnotify.Rename, "rename"},
+		{fsnotify.Chmod, ""},
+		{fsnotify.Create | fsnotify.Chmod, "create"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.op.String(), func(t *testing.T) {
+			if got := eventOp(tt.op); got != tt.expected {
+				t.Errorf("eventOp(%v) = %q, expected %q", tt.op, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMonitorIgnoresStateDirectory(t *testing.T) {
+	mc := &MonitorCommand{
+		config:   &CommandConfig{},
+		paths:    []string{"/watched"},
+		patterns: []string{"*"},
+	}
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"/watched/docs/a.md", true},
+		{"/watched/.stroidex/manifest.json", false},
+		{"/watched/.stroidex/tmp/index-manifest-1.json", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			_, ok := mc.fileEvent(fsnotify.Event{Name: tt.path, Op: fsnotify.Write})
+			if ok != tt.expected {
+				t.Errorf("fileEvent(%s) reported = %v, expected %v", tt.path, ok, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMonitorExcludeFiltersEvents(t *testing.T) {
+	mc := &MonitorCommand{
+		config:   &CommandConfig{},
+		paths:    []string{"/watched"},
+		patterns: []string{"*"},
+		exclude:  []string{".git/**", "*.o", "build/**"},
+	}
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"/watched/docs/a.md", true},
+		{"/watched/.git/index", false},
+		{"/watched/.git/refs/heads/main", false},
+		{"/watched/src/main.o", false},
+		{"/watched/build/app", false},
+		{"/watched/src/build/app", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			_, ok := mc.fileEvent(fsnotify.Event{Name: tt.path, Op: fsnotify.Write})
+			if ok != tt.expected {
+				t.Errorf("fileEvent(%s) reported = %v, expected %v", tt.path, ok, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMonitorWatchSkipsExcludedPaths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-watch-exclude")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	git := filepath.Join(dir, ".git")
+	if err := os.Mkdir(git, 0755); err != nil {
+		t.Fatalf("Failed to create subdir: %v", err)
+	}
+
+	mc := &MonitorCommand{
+		config:    &CommandConfig{},
+		paths:     []string{dir},
+		recursive: true,
+		maxDepth:  -1,
+		patterns:  []string{"*"},
+		exclude:   []string{".git/**", "*.tmp"},
+	}
+	if err := mc.startWatching(); err != nil {
+		t.Fatalf("startWatching() error = %v", err)
+	}
+	defer mc.watcher.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := mc.watch(ctx)
+
+	for _, path := range []string{filepath.Join(git, "index"), filepath.Join(dir, "swap.tmp")} {
+		if err := ioutil.WriteFile(path, []byte("excluded"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	// Written last, so every excluded write has been handled once its
+	// event arrives
+	kept := filepath.Join(dir, "a.md")
+	if err := ioutil.WriteFile(kept, []byte("kept"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.Path == kept {
+				return
+			}
+			t.Errorf("Unexpected event %+v for an excluded path", event)
+		case <-timeout:
+			t.Fatalf("No event delivered for %s", kept)
+		}
+	}
+}
+
+func TestMonitorStreamsEventsAsNDJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-stream")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	mc := &MonitorCommand{
+		config:   &CommandConfig{OutputFormat: "json"},
+		paths:    []string{dir},
+		patterns: []string{"*.md"},
+	}
+	if err := mc.startWatching(); err != nil {
+		t.Fatalf("startWatching() error = %v", err)
+	}
+	defer mc.watcher.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mc.events = mc.watch(ctx)
+
+	path := filepath.Join(dir, "a.md")
+	output := captureStdout(t, func() {
+		renderer := NewStreamRenderer(mc.config.OutputFormat, os.Stdout)
+		done := make(chan error)
+		go func() {
+			done <- mc.runStreamMode(ctx, make(chan os.Signal), renderer)
+		}()
+
+		if err := ioutil.WriteFile(path, []byte("hello"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		time.Sleep(300 * time.Millisecond)
+		cancel()
+
+		if err := <-done; err != nil {
+			t.Errorf("runStreamMode() returned error: %v", err)
+		}
+	})
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) == 0 || lines[0] == "" {
+		t.Fatalf("Expected at least one event line, got %q", output)
+	}
+	for _, line := range lines {
+		var record struct {
+			Type      string    `json:"type"`
+			Path      string    `json:"path"`
+			Event     string    `json:"event"`
+			Timestamp time.Time `json:"timestamp"`
+			Size      *int64    `json:"size"`
+		}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Line %q is not valid JSON: %v", line, err)
+		}
+		if record.Type != "event" || record.Path != path {
+			t.Errorf("record = %+v, expected an event for %s", record, path)
+		}
+		if record.Event != "create" && record.Event != "write" {
+			t.Errorf("record event = %q, expected create or write", record.Event)
+		}
+		if record.Timestamp.IsZero() {
+			t.Errorf("record for %s has no timestamp", record.Path)
+		}
+		if record.Size == nil {
+			t.Errorf("record for %s has no size", record.Path)
+		}
+	}
+}
+
+func TestNewEventRecordOmitsSizeForRemovedFiles(t *testing.T) {
+	record := newEventRecord(FileEvent{Path: filepath.Join(os.TempDir(), "stroidex-missing.md"), Op: "remove"})
+	if record.Size != nil {
+		t.Errorf("newEventRecord() size = %d, expected none for a missing file", *record.Size)
+	}
+}
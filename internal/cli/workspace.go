@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// resolvePath resolves a relative path against the workspace. Absolute
+// paths, and any path without a workspace, are returned unchanged.
+func (c *CommandConfig) resolvePath(path string) string {
+	if c.Workspace == "" || c.Workspace == "." || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.Workspace, path)
+}
+
+// resolvePaths resolves each path against the workspace
+func (c *CommandConfig) resolvePaths(paths []string) []string {
+	resolved := make([]string, len(paths))
+	for i, path := range paths {
+		resolved[i] = c.resolvePath(path)
+	}
+	return resolved
+}
+
+// validateWorkspace checks that the workspace is an existing directory
+func validateWorkspace(workspace string) error {
+	if workspace == "" {
+		return nil
+	}
+
+	info, err := os.Stat(workspace)
+	if err != nil {
+		return fmt.Errorf("invalid workspace: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("invalid workspace: %s is not a directory", workspace)
+	}
+	return nil
+}
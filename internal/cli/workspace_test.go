@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestResolvePath(t *testing.T) {
+	abs, _ := filepath.Abs("docs")
+
+	tests := []struct {
+		name      string
+		workspace string
+		path      string
+		expected  string
+	}{
+		{"No workspace", "", "docs", "docs"},
+		{"Current directory", ".", "docs", "docs"},
+		{"Relative path", "project", "docs", filepath.Join("project", "docs")},
+		{"Default path", "project", ".", "project"},
+		{"Absolute path", "project", abs, abs},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &CommandConfig{Workspace: tt.workspace}
+			if got := config.resolvePath(tt.path); got != tt.expected {
+				t.Errorf("resolvePath(%s) = %s, expected %s", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateWorkspace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stroidex-workspace")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "file.txt")
+	if err := ioutil.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if err := validateWorkspace(dir); err != nil {
+		t.Errorf("validateWorkspace(dir) error = %v", err)
+	}
+	if err := validateWorkspace(file); err == nil {
+		t.Error("validateWorkspace(file) should fail")
+	}
+	if err := validateWorkspace(filepath.Join(dir, "missing")); err == nil {
+		t.Error("validateWorkspace(missing) should fail")
+	}
+}
+
+func TestIndexWithWorkspace(t *testing.T) {
+	preserveTheme(t)
+
+	dir, err := ioutil.TempDir("", "stroidex-workspace")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	docs := filepath.Join(dir, "docs")
+	if err := os.Mkdir(docs, 0755); err != nil {
+		t.Fatalf("Failed to create docs dir: %v", err)
+	}
+	for _, name := range []string{"a.md", "b.md"} {
+		if err := ioutil.WriteFile(filepath.Join(docs, name), []byte("# "+name), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	cli := NewCLI()
+	cli.RootCmd.SetArgs([]string{"--workspace", dir, "--no-color", "index", "docs"})
+
+	output := captureStdout(t, func() {
+		if err := cli.RootCmd.Execute(); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	})
+
+	if !regexp.MustCompile(`Files processed\s*\|\s*2\s*\|`).MatchString(output) {
+		t.Errorf("Expected the 2 files of the workspace docs dir to be processed:\n%s", output)
+	}
+
+	// The state directory lives in the workspace, not the working directory
+	if _, err := os.Stat(filepath.Join(dir, stateDirName, indexManifestFile)); err != nil {
+		t.Errorf("Expected the index manifest in the workspace: %v", err)
+	}
+}
@@ -1,12 +1,18 @@
 package main
 
 import (
+	"errors"
+	"os"
+
 	"stroidex/internal/cli"
 )
 
 func main() {
 	stroidokCLI := cli.NewCLI()
 	if err := stroidokCLI.Execute(); err != nil {
-		cli.PrintError(err)
+		if !errors.Is(err, cli.ErrChangesFound) {
+			cli.PrintError(err)
+		}
+		os.Exit(cli.ExitCode(err))
 	}
-}
\ No newline at end of file
+}